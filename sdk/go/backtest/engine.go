@@ -0,0 +1,299 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/paper"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+// EventHandler receives a replay's market data and account events. It is
+// the plumbing strategy.Backtest uses to run a strategy.Strategy here, not
+// a strategy API of its own.
+type EventHandler interface {
+	OnStart(ctx context.Context, trader client.Trader) error
+	OnCandle(ctx context.Context, candle types.CandleData) error
+	OnBook(ctx context.Context, book types.L2BookData) error
+	OnTrades(ctx context.Context, trades []types.TradeData) error
+	OnFills(ctx context.Context, fills types.UserFillsData) error
+	OnOrderUpdates(ctx context.Context, updates []types.OrderUpdate) error
+	OnStop(ctx context.Context) error
+}
+
+// Config configures an Engine
+type Config struct {
+	User              string          // Account address reported in fills, default the zero address
+	Balance           decimal.Decimal // Starting USDC balance
+	Leverage          int             // Cross leverage used for margin, default 20
+	FeeTiers          []paper.FeeTier // Default paper.DefaultFeeTiers
+	MaintenanceMargin decimal.Decimal // Fraction of notional below which the account is liquidated, default 0.01
+	SampleInterval    time.Duration   // Spacing of equity curve points, default one hour
+}
+
+// Engine replays a feed through a strategy against a simulated exchange.
+// Books and trades drive the paper exchange's matching; for coins replayed
+// from candles alone, each candle is turned into trades at its low and
+// high, each carrying half its volume, and a book one tick wide at its
+// close. Funding events settle against open positions, and the account is
+// liquidated when its value falls below maintenance margin.
+type Engine struct {
+	config Config
+}
+
+// NewEngine creates a backtest engine
+func NewEngine(config Config) *Engine {
+	if config.User == "" {
+		config.User = "0x0000000000000000000000000000000000000000"
+	}
+	if config.MaintenanceMargin.IsZero() {
+		config.MaintenanceMargin = decimal.RequireFromString("0.01")
+	}
+	if config.SampleInterval <= 0 {
+		config.SampleInterval = time.Hour
+	}
+	return &Engine{config: config}
+}
+
+// run is the state of one Run
+type run struct {
+	config   Config
+	strategy EventHandler
+	exchange *paper.PaperExchange
+	clock    atomic.Int64
+	ledger   *ledger
+	books    map[string]bool
+
+	mu      sync.Mutex
+	fills   []types.UserFillsData
+	updates [][]types.OrderUpdate
+}
+
+// Run replays feed through strategy and reports the results. Account events
+// are delivered after the call that caused them returns, so the strategy is
+// never re-entered. A strategy error stops the run. Strategies are run here
+// with strategy.Backtest.
+func (e *Engine) Run(ctx context.Context, feed Feed, strategy EventHandler) (*Report, error) {
+	r := &run{
+		config:   e.config,
+		strategy: strategy,
+		exchange: paper.NewPaperExchange(nil, e.config.User, paper.Config{
+			Balance:  e.config.Balance,
+			Leverage: e.config.Leverage,
+			FeeTiers: e.config.FeeTiers,
+		}),
+		ledger: newLedger(e.config.Balance, e.config.SampleInterval),
+		books:  make(map[string]bool),
+	}
+	r.exchange.SetClock(func() time.Time { return time.UnixMilli(r.clock.Load()) })
+	r.exchange.OnFills(func(data types.UserFillsData) {
+		r.mu.Lock()
+		r.fills = append(r.fills, data)
+		r.mu.Unlock()
+	})
+	r.exchange.OnOrderUpdates(func(updates []types.OrderUpdate) {
+		r.mu.Lock()
+		r.updates = append(r.updates, updates)
+		r.mu.Unlock()
+	})
+
+	first, err := feed.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("feed is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	r.clock.Store(first.Time)
+
+	if err := strategy.OnStart(ctx, r.exchange); err != nil {
+		return nil, fmt.Errorf("strategy failed to start: %w", err)
+	}
+	if err := r.deliver(ctx); err != nil {
+		return nil, err
+	}
+
+	for event := first; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := r.step(ctx, event); err != nil {
+			return nil, err
+		}
+
+		event, err = feed.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read feed: %w", err)
+		}
+	}
+
+	if err := strategy.OnStop(ctx); err != nil {
+		return nil, fmt.Errorf("strategy failed to stop: %w", err)
+	}
+	if err := r.deliver(ctx); err != nil {
+		return nil, err
+	}
+
+	equity, err := r.equity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ledger.report(time.UnixMilli(r.clock.Load()), equity), nil
+}
+
+// step applies one event to the exchange, checks margin, then hands the
+// resulting account events and the event itself to the strategy
+func (r *run) step(ctx context.Context, event Event) error {
+	if event.Time > r.clock.Load() {
+		r.clock.Store(event.Time)
+	}
+
+	if coin := event.Coin(); coin != "" {
+		if err := r.exchange.Track(coin); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case event.Book != nil:
+		r.books[event.Book.Coin] = true
+		if err := r.exchange.HandleBook(*event.Book); err != nil {
+			return fmt.Errorf("failed to apply %s book: %w", event.Book.Coin, err)
+		}
+	case len(event.Trades) > 0:
+		r.exchange.HandleTrades(event.Trades)
+	case event.Candle != nil:
+		if err := r.applyCandle(*event.Candle); err != nil {
+			return err
+		}
+	case event.Funding != nil:
+		if funding, ok := r.exchange.ApplyFunding(event.Funding.Coin, event.Funding.FundingRate); ok {
+			r.ledger.funding(funding)
+		}
+	}
+
+	if err := r.checkMargin(ctx); err != nil {
+		return err
+	}
+	if err := r.deliver(ctx); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case event.Book != nil:
+		err = r.strategy.OnBook(ctx, *event.Book)
+	case len(event.Trades) > 0:
+		err = r.strategy.OnTrades(ctx, event.Trades)
+	case event.Candle != nil:
+		err = r.strategy.OnCandle(ctx, *event.Candle)
+	}
+	if err != nil {
+		return fmt.Errorf("strategy failed at %d: %w", event.Time, err)
+	}
+	if err := r.deliver(ctx); err != nil {
+		return err
+	}
+
+	equity, err := r.equity(ctx)
+	if err != nil {
+		return err
+	}
+	r.ledger.mark(time.UnixMilli(r.clock.Load()), equity)
+	return nil
+}
+
+// applyCandle simulates a candle's range for coins without book data
+func (r *run) applyCandle(candle types.CandleData) error {
+	if r.books[candle.Coin] {
+		return nil
+	}
+
+	// The volume is split between the sides rather than counted twice
+	half := candle.V.Div(decimal.NewFromInt(2))
+	r.exchange.HandleTrades([]types.TradeData{
+		{Coin: candle.Coin, Side: "A", Px: candle.L, Sz: half, Time: candle.T},
+		{Coin: candle.Coin, Side: "B", Px: candle.H, Sz: half, Time: candle.T},
+	})
+
+	bid := []interface{}{types.OrderBookLevel{Price: candle.C, Size: candle.V, NumOrders: 1}}
+	ask := []interface{}{types.OrderBookLevel{Price: candle.C.Add(tick(candle.C)), Size: candle.V, NumOrders: 1}}
+	book := types.L2BookData{Coin: candle.Coin, Time: candle.T, Levels: [][]interface{}{bid, ask}}
+	if err := r.exchange.HandleBook(book); err != nil {
+		return fmt.Errorf("failed to apply %s candle: %w", candle.Coin, err)
+	}
+	return nil
+}
+
+// tick returns the price increment at px under the exchange's five
+// significant figure rule
+func tick(px decimal.Decimal) decimal.Decimal {
+	intDigits := int32(px.NumDigits()) + px.Exponent()
+	places := int32(utils.MaxPriceSigFigs) - intDigits
+	if places < 0 {
+		places = 0
+	}
+	return decimal.New(1, -places)
+}
+
+// checkMargin liquidates the account once its value is below maintenance
+func (r *run) checkMargin(ctx context.Context) error {
+	state, err := r.exchange.GetUserState(ctx, r.config.User)
+	if err != nil {
+		return err
+	}
+
+	maintenance := state.MarginSummary.TotalNtlPos.Mul(r.config.MaintenanceMargin)
+	if maintenance.IsPositive() && state.MarginSummary.AccountValue.LessThan(maintenance) {
+		r.exchange.Liquidate()
+		r.ledger.liquidations++
+	}
+	return nil
+}
+
+// deliver hands queued account events to the strategy until none remain
+func (r *run) deliver(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		fills, updates := r.fills, r.updates
+		r.fills, r.updates = nil, nil
+		r.mu.Unlock()
+
+		if len(fills) == 0 && len(updates) == 0 {
+			return nil
+		}
+
+		for _, data := range fills {
+			for _, fill := range data.Fills {
+				r.ledger.fill(fill)
+			}
+			if err := r.strategy.OnFills(ctx, data); err != nil {
+				return fmt.Errorf("strategy failed on fills: %w", err)
+			}
+		}
+		for _, batch := range updates {
+			if err := r.strategy.OnOrderUpdates(ctx, batch); err != nil {
+				return fmt.Errorf("strategy failed on order updates: %w", err)
+			}
+		}
+	}
+}
+
+func (r *run) equity(ctx context.Context) (decimal.Decimal, error) {
+	state, err := r.exchange.GetUserState(ctx, r.config.User)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return state.MarginSummary.AccountValue, nil
+}
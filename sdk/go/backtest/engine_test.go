@@ -0,0 +1,246 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/recording"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func candles(closes ...string) []types.Candle {
+	var out []types.Candle
+	for i, c := range closes {
+		px := dec(c)
+		out = append(out, types.Candle{
+			T: int64(i) * time.Hour.Milliseconds(),
+			O: px, H: px.Add(decimal.NewFromInt(1)), L: px.Sub(decimal.NewFromInt(1)), C: px,
+			V: dec("10"),
+		})
+	}
+	return out
+}
+
+// scripted places one IOC order per candle index and records what it sees
+type scripted struct {
+	orders    map[int]types.OrderRequest
+	trader    client.Trader
+	seen      int
+	inCall    bool
+	fills     []types.UserFillData
+	updates   []types.OrderUpdate
+	stopped   bool
+	reentered bool
+}
+
+func (s *scripted) OnStart(ctx context.Context, trader client.Trader) error {
+	s.trader = trader
+	return nil
+}
+
+func (s *scripted) OnCandle(ctx context.Context, candle types.CandleData) error {
+	s.inCall = true
+	defer func() { s.inCall = false }()
+
+	order, ok := s.orders[s.seen]
+	s.seen++
+	if !ok {
+		return nil
+	}
+	resp, err := s.trader.PlaceOrder(ctx, order)
+	if err != nil {
+		return err
+	}
+	if status := resp.Response.Data.Statuses[0]; status.Error != nil {
+		return fmt.Errorf("order rejected: %s", *status.Error)
+	}
+	return nil
+}
+
+func (s *scripted) OnBook(ctx context.Context, book types.L2BookData) error      { return nil }
+func (s *scripted) OnTrades(ctx context.Context, trades []types.TradeData) error { return nil }
+
+func (s *scripted) OnFills(ctx context.Context, fills types.UserFillsData) error {
+	s.reentered = s.reentered || s.inCall
+	s.fills = append(s.fills, fills.Fills...)
+	return nil
+}
+
+func (s *scripted) OnOrderUpdates(ctx context.Context, updates []types.OrderUpdate) error {
+	s.updates = append(s.updates, updates...)
+	return nil
+}
+
+func (s *scripted) OnStop(ctx context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+func ioc(isBuy bool, px, sz string) types.OrderRequest {
+	return types.OrderRequest{
+		Asset:     "BTC",
+		IsBuy:     isBuy,
+		LimitPx:   dec(px),
+		Sz:        dec(sz),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: types.TifIoc}},
+	}
+}
+
+func TestCandleBacktest(t *testing.T) {
+	bars, err := FromCandles("BTC", "1h", candles("100", "105", "110", "108"))
+	if err != nil {
+		t.Fatalf("Failed to convert candles: %v", err)
+	}
+	funding := FromFundings([]types.FundingData{{Coin: "BTC", FundingRate: dec("0.0001"), Time: 90 * time.Minute.Milliseconds()}})
+
+	strategy := &scripted{orders: map[int]types.OrderRequest{
+		0: ioc(true, "101", "1"),
+		2: ioc(false, "100", "1"),
+	}}
+	engine := NewEngine(Config{Balance: dec("10000"), SampleInterval: time.Hour})
+	report, err := engine.Run(context.Background(), Replay(bars, funding), strategy)
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	// Buys lift the ask a tick above the close; sells hit the close
+	if len(strategy.fills) != 2 || !strategy.fills[0].Px.Equal(dec("100.01")) || !strategy.fills[1].Px.Equal(dec("110")) {
+		t.Fatalf("Expected fills at 100.01 and 110, got %+v", strategy.fills)
+	}
+	if strategy.reentered || !strategy.stopped || len(strategy.updates) != 2 {
+		t.Errorf("Expected non-reentrant delivery of 2 updates and a stop")
+	}
+
+	// 9.99 gained, less 0.0450045 + 0.0495 taker fees and 0.0100005 funding
+	// paid at the 100.005 mid
+	if len(report.Trades) != 1 || !report.Trades[0].Pnl.Equal(dec("9.885495")) || !report.Trades[0].Funding.Equal(dec("-0.0100005")) {
+		t.Fatalf("Unexpected trades: %+v", report.Trades)
+	}
+	if !report.FinalEquity.Equal(dec("10009.885495")) || !report.Fees.Equal(dec("0.0945045")) || !report.Volume.Equal(dec("210.01")) {
+		t.Errorf("Unexpected totals: equity %s fees %s volume %s", report.FinalEquity, report.Fees, report.Volume)
+	}
+	if math.Abs(report.Return-0.0009885495) > 1e-12 || len(report.Equity) != 4 {
+		t.Errorf("Expected return 0.0009885495 over 4 samples, got %f over %d", report.Return, len(report.Equity))
+	}
+	if report.MaxDrawdown <= 0 || report.Sharpe <= 0 {
+		t.Errorf("Expected a drawdown from the last bar and a positive Sharpe, got %f and %f", report.MaxDrawdown, report.Sharpe)
+	}
+}
+
+func TestCandleSplitsVolume(t *testing.T) {
+	bars, _ := FromCandles("BTC", "1h", candles("100", "100"))
+	resting := ioc(true, "99", "8")
+	resting.OrderType.Limit.Tif = types.TifGtc
+	strategy := &scripted{orders: map[int]types.OrderRequest{0: resting}}
+
+	if _, err := NewEngine(Config{Balance: dec("10000")}).Run(context.Background(), Replay(bars), strategy); err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	// The low prints half of the 10 traded
+	if len(strategy.fills) != 1 || !strategy.fills[0].Sz.Equal(dec("5")) {
+		t.Errorf("Expected 5 filled at the low, got %+v", strategy.fills)
+	}
+}
+
+func TestFromFrames(t *testing.T) {
+	frame := func(dir, data string) recording.Frame {
+		return recording.Frame{Dir: dir, Data: []byte(data)}
+	}
+	frames := []recording.Frame{
+		frame("out", `{"method":"subscribe","subscription":{"type":"trades","coin":"BTC"}}`),
+		frame("in", `{"channel":"subscriptionResponse","data":{}}`),
+		frame("in", `{"channel":"candle","data":{"coin":"BTC","interval":"1m","T":60000,"c":"100"}}`),
+		frame("in", `{"channel":"trades","data":[{"coin":"BTC","px":"100","sz":"1","time":1000,"tid":1},{"coin":"BTC","px":"101","sz":"1","time":1000,"tid":2}]}`),
+		frame("in", `{"channel":"candle","data":{"coin":"BTC","interval":"1m","T":60000,"c":"101"}}`),
+		frame("in", `{"channel":"l2Book","data":{"coin":"BTC","time":2000,"levels":[[],[]]}}`),
+		frame("in", `{"channel":"candle","data":{"coin":"BTC","interval":"1m","T":120000,"c":"102"}}`),
+	}
+
+	events, err := FromFrames(frames)
+	if err != nil {
+		t.Fatalf("Failed to convert frames: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected trades, book and one closed candle, got %+v", events)
+	}
+	if len(events[0].Trades) != 2 || events[0].Time != 1000 {
+		t.Errorf("Expected two trades at 1000, got %+v", events[0])
+	}
+	if events[1].Book == nil || events[1].Time != 2000 {
+		t.Errorf("Expected a book at 2000, got %+v", events[1])
+	}
+	if c := events[2].Candle; c == nil || events[2].Time != 60000 || !c.C.Equal(dec("101")) {
+		t.Errorf("Expected the last update of the first candle at 60000, got %+v", events[2])
+	}
+
+	if _, err := FromFrames([]recording.Frame{frame("in", `{"channel":"trades","data":{}}`)}); err == nil {
+		t.Error("Expected malformed trades to fail")
+	}
+}
+
+func TestTick(t *testing.T) {
+	for px, want := range map[string]string{"50000": "1", "123456": "1", "3000": "0.1", "1.5": "0.0001", "0.0123": "0.000001"} {
+		if got := tick(dec(px)); !got.Equal(dec(want)) {
+			t.Errorf("Expected tick %s at %s, got %s", want, px, got)
+		}
+	}
+}
+
+func TestLiquidation(t *testing.T) {
+	bars, _ := FromCandles("BTC", "1h", candles("100", "90", "120"))
+	strategy := &scripted{orders: map[int]types.OrderRequest{0: ioc(true, "101", "10")}}
+
+	engine := NewEngine(Config{Balance: dec("100"), Leverage: 20})
+	report, err := engine.Run(context.Background(), Replay(bars), strategy)
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if report.Liquidations != 1 || len(report.Trades) != 1 {
+		t.Fatalf("Expected one liquidated trade, got %d liquidations and %+v", report.Liquidations, report.Trades)
+	}
+	if trade := report.Trades[0]; !trade.Long || !trade.MaxSize.Equal(dec("10")) || !trade.Pnl.IsNegative() {
+		t.Errorf("Expected a losing 10 long, got %+v", trade)
+	}
+	// The rally after liquidation does not help
+	if report.FinalEquity.IsPositive() {
+		t.Errorf("Expected the account wiped out by liquidation, got %s", report.FinalEquity)
+	}
+}
+
+func TestTradeStats(t *testing.T) {
+	l := newLedger(dec("100"), time.Minute)
+	start := time.UnixMilli(0)
+	for i, equity := range []string{"100", "120", "90", "130"} {
+		l.mark(start.Add(time.Duration(i)*time.Minute), dec(equity))
+	}
+	if math.Abs(l.maxDrawdown-0.25) > 1e-12 {
+		t.Errorf("Expected drawdown 0.25, got %f", l.maxDrawdown)
+	}
+
+	fill := func(ms int64, side, start, sz, closedPnl string) types.UserFillData {
+		return types.UserFillData{Coin: "ETH", Side: side, Px: dec("10"), Sz: dec(sz), StartPosition: dec(start), ClosedPnl: dec(closedPnl), Time: ms}
+	}
+	// Long 2, flip to short 1, close: two trades
+	l.fill(fill(0, "B", "0", "2", "0"))
+	l.fill(fill(1000, "A", "2", "3", "6"))
+	l.fill(fill(3000, "B", "-1", "1", "-2"))
+
+	s := stats(l.closed)
+	if s.Count != 2 || s.Wins != 1 || s.WinRate != 0.5 || s.ProfitFactor != 3 || s.AvgHolding != 1500*time.Millisecond {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+	if !l.closed[0].Long || !l.closed[0].MaxSize.Equal(dec("2")) || l.closed[1].Long || !l.closed[1].MaxSize.Equal(dec("1")) {
+		t.Errorf("Unexpected trades: %+v", l.closed)
+	}
+}
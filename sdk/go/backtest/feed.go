@@ -0,0 +1,194 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/recording"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// Event is one replayed market data update. Exactly one of the payload
+// fields is set.
+type Event struct {
+	Time    int64 // Milliseconds; events replay in this order
+	Candle  *types.CandleData
+	Trades  []types.TradeData
+	Book    *types.L2BookData
+	Funding *types.FundingData // Coin, FundingRate and Time of a funding settlement
+}
+
+// Coin returns the coin the event belongs to
+func (e Event) Coin() string {
+	switch {
+	case e.Candle != nil:
+		return e.Candle.Coin
+	case e.Book != nil:
+		return e.Book.Coin
+	case e.Funding != nil:
+		return e.Funding.Coin
+	case len(e.Trades) > 0:
+		return e.Trades[0].Coin
+	}
+	return ""
+}
+
+// Feed yields events in time order, returning io.EOF when exhausted
+type Feed interface {
+	Next() (Event, error)
+}
+
+// sliceFeed replays events held in memory
+type sliceFeed struct {
+	events []Event
+	next   int
+}
+
+func (f *sliceFeed) Next() (Event, error) {
+	if f.next >= len(f.events) {
+		return Event{}, io.EOF
+	}
+	event := f.events[f.next]
+	f.next++
+	return event, nil
+}
+
+// Replay merges event sets into a feed ordered by time. Events with equal
+// times keep the order they were given in.
+func Replay(sets ...[]Event) Feed {
+	var events []Event
+	for _, set := range sets {
+		events = append(events, set...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+	return &sliceFeed{events: events}
+}
+
+// candleIntervals maps candle interval names to their length
+var candleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// FromCandles converts candles from GetCandles into events timed at each
+// candle's close, so a strategy never sees a candle before it completes
+func FromCandles(coin, interval string, candles []types.Candle) ([]Event, error) {
+	length, ok := candleIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("unknown candle interval: %s", interval)
+	}
+
+	events := make([]Event, 0, len(candles))
+	for _, c := range candles {
+		closeTime := c.T + length.Milliseconds()
+		events = append(events, Event{Time: closeTime, Candle: &types.CandleData{
+			Coin:     coin,
+			Interval: interval,
+			T:        closeTime,
+			O:        c.O,
+			H:        c.H,
+			L:        c.L,
+			C:        c.C,
+			V:        c.V,
+			N:        c.N,
+		}})
+	}
+	return events, nil
+}
+
+// FromTrades converts trades into events, grouping consecutive trades of a
+// coin with the same timestamp the way the trades stream delivers them
+func FromTrades(trades []types.TradeData) []Event {
+	var events []Event
+	for _, trade := range trades {
+		if n := len(events); n > 0 && events[n-1].Time == trade.Time && events[n-1].Trades[0].Coin == trade.Coin {
+			events[n-1].Trades = append(events[n-1].Trades, trade)
+			continue
+		}
+		events = append(events, Event{Time: trade.Time, Trades: []types.TradeData{trade}})
+	}
+	return events
+}
+
+// FromBooks converts l2Book snapshots into events
+func FromBooks(books []types.L2BookData) []Event {
+	events := make([]Event, 0, len(books))
+	for i := range books {
+		book := books[i]
+		events = append(events, Event{Time: book.Time, Book: &book})
+	}
+	return events
+}
+
+// FromFundings converts funding settlements into events. Only Coin,
+// FundingRate and Time are used.
+func FromFundings(fundings []types.FundingData) []Event {
+	events := make([]Event, 0, len(fundings))
+	for i := range fundings {
+		funding := fundings[i]
+		events = append(events, Event{Time: funding.Time, Funding: &funding})
+	}
+	return events
+}
+
+// FromFrames converts the l2Book, trades and candle messages of a recording
+// into events. A streamed candle is only complete once the next one starts,
+// so each becomes an event timed at its close when that happens and the
+// candle still open at the end of the recording is dropped. Other channels
+// are ignored.
+func FromFrames(frames []recording.Frame) ([]Event, error) {
+	var events []Event
+	open := make(map[string]types.CandleData) // Latest update of each coin and interval's open candle
+	for i, frame := range frames {
+		if !frame.Inbound() || frame.Data == nil {
+			continue
+		}
+
+		var msg types.WSMessage
+		if err := json.Unmarshal(frame.Data, &msg); err != nil {
+			return nil, fmt.Errorf("failed to decode frame %d: %w", i, err)
+		}
+
+		switch msg.Channel {
+		case "l2Book":
+			var book types.L2BookData
+			if err := json.Unmarshal(msg.Data, &book); err != nil {
+				return nil, fmt.Errorf("failed to decode l2Book in frame %d: %w", i, err)
+			}
+			events = append(events, Event{Time: book.Time, Book: &book})
+
+		case "trades":
+			var trades []types.TradeData
+			if err := json.Unmarshal(msg.Data, &trades); err != nil {
+				return nil, fmt.Errorf("failed to decode trades in frame %d: %w", i, err)
+			}
+			events = append(events, FromTrades(trades)...)
+
+		case "candle":
+			var candle types.CandleData
+			if err := json.Unmarshal(msg.Data, &candle); err != nil {
+				return nil, fmt.Errorf("failed to decode candle in frame %d: %w", i, err)
+			}
+			key := candle.Coin + "/" + candle.Interval
+			if last, ok := open[key]; ok && last.T != candle.T {
+				events = append(events, Event{Time: last.T, Candle: &last})
+			}
+			open[key] = candle
+		}
+	}
+	return events, nil
+}
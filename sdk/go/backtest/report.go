@@ -0,0 +1,259 @@
+package backtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// EquityPoint is one sample of the equity curve
+type EquityPoint struct {
+	Time   time.Time
+	Equity decimal.Decimal
+}
+
+// Trade is one round trip in a coin, from flat back to flat. A position
+// flipping through zero closes one trade and opens the next.
+type Trade struct {
+	Coin    string
+	Long    bool
+	Open    time.Time
+	Close   time.Time
+	MaxSize decimal.Decimal // Largest absolute position held
+	Fees    decimal.Decimal
+	Funding decimal.Decimal // Funding received; negative when paid
+	Pnl     decimal.Decimal // Closed PnL less fees, plus funding
+}
+
+// TradeStats summarizes closed trades
+type TradeStats struct {
+	Count        int
+	Wins         int
+	WinRate      float64
+	AvgWin       decimal.Decimal
+	AvgLoss      decimal.Decimal // Negative
+	Best         decimal.Decimal
+	Worst        decimal.Decimal
+	ProfitFactor float64 // Gross profit over gross loss; +Inf with only winners
+	AvgHolding   time.Duration
+}
+
+// Report is the outcome of a backtest
+type Report struct {
+	Start         time.Time
+	End           time.Time
+	InitialEquity decimal.Decimal
+	FinalEquity   decimal.Decimal
+	Return        float64       // Final over initial equity, less one
+	Equity        []EquityPoint // Sampled at the configured interval
+	MaxDrawdown   float64       // Largest peak to trough fall as a fraction of the peak
+	Sharpe        float64       // Annualized from per-sample returns, zero risk-free rate
+	Volume        decimal.Decimal
+	Turnover      float64 // Volume over average equity
+	Fees          decimal.Decimal
+	Funding       decimal.Decimal
+	Liquidations  int
+	Trades        []Trade // Closed trades; positions still open at the end are excluded
+	Stats         TradeStats
+}
+
+// ledger accumulates results as a run progresses
+type ledger struct {
+	initial      decimal.Decimal
+	interval     time.Duration
+	start        time.Time
+	curve        []EquityPoint
+	peak         decimal.Decimal
+	maxDrawdown  float64
+	volume       decimal.Decimal
+	fees         decimal.Decimal
+	fundings     decimal.Decimal
+	liquidations int
+	open         map[string]*Trade
+	closed       []Trade
+}
+
+func newLedger(initial decimal.Decimal, interval time.Duration) *ledger {
+	return &ledger{
+		initial:  initial,
+		interval: interval,
+		peak:     initial,
+		open:     make(map[string]*Trade),
+	}
+}
+
+// fill records a fill against the coin's open trade
+func (l *ledger) fill(f types.UserFillData) {
+	at := time.UnixMilli(f.Time)
+	l.volume = l.volume.Add(f.Px.Mul(f.Sz))
+	l.fees = l.fees.Add(f.Fee)
+
+	signed := f.Sz
+	if f.Side == "A" {
+		signed = signed.Neg()
+	}
+	end := f.StartPosition.Add(signed)
+
+	trade, ok := l.open[f.Coin]
+	if !ok {
+		trade = &Trade{Coin: f.Coin, Long: signed.IsPositive(), Open: at}
+		l.open[f.Coin] = trade
+	}
+	trade.Fees = trade.Fees.Add(f.Fee)
+	trade.Pnl = trade.Pnl.Add(f.ClosedPnl).Sub(f.Fee)
+
+	flipped := !end.IsZero() && !f.StartPosition.IsZero() && end.IsPositive() != f.StartPosition.IsPositive()
+	if end.IsZero() || flipped {
+		trade.MaxSize = decimal.Max(trade.MaxSize, f.StartPosition.Abs())
+		trade.Close = at
+		l.closed = append(l.closed, *trade)
+		delete(l.open, f.Coin)
+	}
+	if flipped {
+		l.open[f.Coin] = &Trade{Coin: f.Coin, Long: end.IsPositive(), Open: at, MaxSize: end.Abs()}
+		return
+	}
+	if trade, ok := l.open[f.Coin]; ok {
+		trade.MaxSize = decimal.Max(trade.MaxSize, end.Abs())
+	}
+}
+
+// funding records a funding settlement against the coin's open trade
+func (l *ledger) funding(f types.FundingData) {
+	l.fundings = l.fundings.Add(f.Usdc)
+	if trade, ok := l.open[f.Coin]; ok {
+		trade.Funding = trade.Funding.Add(f.Usdc)
+		trade.Pnl = trade.Pnl.Add(f.Usdc)
+	}
+}
+
+// mark records equity at t, sampling the curve and tracking drawdown
+func (l *ledger) mark(t time.Time, equity decimal.Decimal) {
+	if l.start.IsZero() {
+		l.start = t
+	}
+	if n := len(l.curve); n == 0 || t.Sub(l.curve[n-1].Time) >= l.interval {
+		l.curve = append(l.curve, EquityPoint{Time: t, Equity: equity})
+	}
+
+	if equity.GreaterThan(l.peak) {
+		l.peak = equity
+	}
+	if l.peak.IsPositive() {
+		drawdown, _ := l.peak.Sub(equity).Div(l.peak).Float64()
+		l.maxDrawdown = math.Max(l.maxDrawdown, drawdown)
+	}
+}
+
+func (l *ledger) report(end time.Time, equity decimal.Decimal) *Report {
+	curve := l.curve
+	if n := len(curve); n == 0 || curve[n-1].Time.Before(end) {
+		curve = append(curve, EquityPoint{Time: end, Equity: equity})
+	}
+
+	report := &Report{
+		Start:         l.start,
+		End:           end,
+		InitialEquity: l.initial,
+		FinalEquity:   equity,
+		Equity:        curve,
+		MaxDrawdown:   l.maxDrawdown,
+		Sharpe:        sharpe(curve, l.interval),
+		Volume:        l.volume,
+		Fees:          l.fees,
+		Funding:       l.fundings,
+		Liquidations:  l.liquidations,
+		Trades:        l.closed,
+		Stats:         stats(l.closed),
+	}
+
+	if l.initial.IsPositive() {
+		report.Return, _ = equity.Div(l.initial).Sub(decimal.NewFromInt(1)).Float64()
+	}
+
+	average := decimal.Zero
+	for _, point := range curve {
+		average = average.Add(point.Equity)
+	}
+	average = average.Div(decimal.NewFromInt(int64(len(curve))))
+	if average.IsPositive() {
+		report.Turnover, _ = l.volume.Div(average).Float64()
+	}
+
+	return report
+}
+
+// sharpe annualizes the mean over the standard deviation of curve returns
+func sharpe(curve []EquityPoint, interval time.Duration) float64 {
+	var returns []float64
+	for i := 1; i < len(curve); i++ {
+		prev, _ := curve[i-1].Equity.Float64()
+		cur, _ := curve[i].Equity.Float64()
+		if prev > 0 {
+			returns = append(returns, cur/prev-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+
+	periods := float64(365*24*time.Hour) / float64(interval)
+	return mean / std * math.Sqrt(periods)
+}
+
+func stats(trades []Trade) TradeStats {
+	s := TradeStats{Count: len(trades)}
+	if len(trades) == 0 {
+		return s
+	}
+
+	grossWin, grossLoss := decimal.Zero, decimal.Zero
+	var holding time.Duration
+	s.Best, s.Worst = trades[0].Pnl, trades[0].Pnl
+	for _, t := range trades {
+		if t.Pnl.IsPositive() {
+			s.Wins++
+			grossWin = grossWin.Add(t.Pnl)
+		} else {
+			grossLoss = grossLoss.Add(t.Pnl)
+		}
+		s.Best = decimal.Max(s.Best, t.Pnl)
+		s.Worst = decimal.Min(s.Worst, t.Pnl)
+		holding += t.Close.Sub(t.Open)
+	}
+
+	losses := s.Count - s.Wins
+	s.WinRate = float64(s.Wins) / float64(s.Count)
+	s.AvgHolding = holding / time.Duration(s.Count)
+	if s.Wins > 0 {
+		s.AvgWin = grossWin.Div(decimal.NewFromInt(int64(s.Wins)))
+	}
+	if losses > 0 {
+		s.AvgLoss = grossLoss.Div(decimal.NewFromInt(int64(losses)))
+	}
+
+	switch {
+	case !grossLoss.IsZero():
+		s.ProfitFactor, _ = grossWin.Div(grossLoss.Neg()).Float64()
+	case grossWin.IsPositive():
+		s.ProfitFactor = math.Inf(1)
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+// OrderBuilder assembles an OrderRequest step by step. Errors are collected
+// and reported by Build, so calls can be chained freely:
+//
+//	order, err := client.Buy("ETH").Size(sz).Limit(px).PostOnly().WithCloid().Resolve(ctx, c.Info())
+type OrderBuilder struct {
+	coin       string
+	isBuy      bool
+	size       decimal.Decimal
+	limitPx    *decimal.Decimal
+	tif        string
+	trigger    *types.TriggerOrderType
+	reduceOnly bool
+	cloid      *string
+	err        error
+}
+
+// Buy starts a buy order for coin
+func Buy(coin string) *OrderBuilder {
+	return &OrderBuilder{coin: coin, isBuy: true}
+}
+
+// Sell starts a sell order for coin
+func Sell(coin string) *OrderBuilder {
+	return &OrderBuilder{coin: coin}
+}
+
+// Size sets the order size
+func (b *OrderBuilder) Size(size decimal.Decimal) *OrderBuilder {
+	b.size = size
+	return b
+}
+
+// Limit sets the limit price. Orders are good till cancelled unless another
+// time in force is chosen.
+func (b *OrderBuilder) Limit(px decimal.Decimal) *OrderBuilder {
+	b.limitPx = &px
+	return b
+}
+
+// Gtc makes the order good till cancelled
+func (b *OrderBuilder) Gtc() *OrderBuilder {
+	return b.setTif(types.TifGtc)
+}
+
+// Ioc makes the order immediate or cancel
+func (b *OrderBuilder) Ioc() *OrderBuilder {
+	return b.setTif(types.TifIoc)
+}
+
+// PostOnly makes the order add liquidity only (Alo)
+func (b *OrderBuilder) PostOnly() *OrderBuilder {
+	return b.setTif(types.TifAlo)
+}
+
+// ReduceOnly restricts the order to reducing a position
+func (b *OrderBuilder) ReduceOnly() *OrderBuilder {
+	b.reduceOnly = true
+	return b
+}
+
+// TakeProfit turns the order into a market take-profit trigger at triggerPx
+func (b *OrderBuilder) TakeProfit(triggerPx decimal.Decimal) *OrderBuilder {
+	return b.setTrigger(types.TpSlTakeProfit, triggerPx)
+}
+
+// StopLoss turns the order into a market stop-loss trigger at triggerPx
+func (b *OrderBuilder) StopLoss(triggerPx decimal.Decimal) *OrderBuilder {
+	return b.setTrigger(types.TpSlStopLoss, triggerPx)
+}
+
+// TriggerLimit makes a trigger order rest at the Limit price once triggered
+// instead of executing at market
+func (b *OrderBuilder) TriggerLimit() *OrderBuilder {
+	if b.trigger == nil {
+		b.fail(fmt.Errorf("TriggerLimit requires TakeProfit or StopLoss"))
+		return b
+	}
+	b.trigger.IsMarket = false
+	return b
+}
+
+// WithCloid attaches a freshly generated client order ID
+func (b *OrderBuilder) WithCloid() *OrderBuilder {
+	cloid, err := utils.NewCloid()
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	b.cloid = &cloid
+	return b
+}
+
+// Cloid attaches the given client order ID
+func (b *OrderBuilder) Cloid(cloid string) *OrderBuilder {
+	if err := utils.ValidateCloid(cloid); err != nil {
+		b.fail(err)
+		return b
+	}
+	b.cloid = &cloid
+	return b
+}
+
+// Resolve looks up the coin's metadata and builds the order
+func (b *OrderBuilder) Resolve(ctx context.Context, info *InfoClient) (types.OrderRequest, error) {
+	if b.err != nil {
+		return types.OrderRequest{}, b.err
+	}
+
+	asset, err := info.GetAssetInfo(ctx, b.coin)
+	if err != nil {
+		return types.OrderRequest{}, err
+	}
+	return b.Build(asset)
+}
+
+// Build validates the order and rounds its price and size against asset
+func (b *OrderBuilder) Build(asset *types.AssetInfo) (types.OrderRequest, error) {
+	if b.err != nil {
+		return types.OrderRequest{}, b.err
+	}
+	if asset.Coin != b.coin {
+		return types.OrderRequest{}, fmt.Errorf("asset %s does not match order coin %s", asset.Coin, b.coin)
+	}
+
+	size := utils.RoundSize(b.size, asset.SzDecimals)
+	if !size.IsPositive() {
+		return types.OrderRequest{}, fmt.Errorf("order size must be positive at %d decimals", asset.SzDecimals)
+	}
+
+	order := types.OrderRequest{
+		Asset:      b.coin,
+		IsBuy:      b.isBuy,
+		Sz:         size,
+		ReduceOnly: b.reduceOnly,
+		Cloid:      b.cloid,
+	}
+
+	if b.trigger == nil {
+		if b.limitPx == nil {
+			return types.OrderRequest{}, fmt.Errorf("limit price required")
+		}
+		tif := b.tif
+		if tif == "" {
+			tif = types.TifGtc
+		}
+		order.LimitPx = utils.RoundPrice(*b.limitPx, asset.SzDecimals, asset.IsSpot)
+		order.OrderType.Limit = &types.LimitOrderType{Tif: tif}
+	} else {
+		if b.tif != "" {
+			return types.OrderRequest{}, fmt.Errorf("time in force %s cannot be combined with a trigger", b.tif)
+		}
+
+		trigger := *b.trigger
+		trigger.TriggerPx = utils.RoundPrice(trigger.TriggerPx, asset.SzDecimals, asset.IsSpot)
+		if err := trigger.Validate(); err != nil {
+			return types.OrderRequest{}, err
+		}
+
+		switch {
+		case b.limitPx != nil:
+			order.LimitPx = utils.RoundPrice(*b.limitPx, asset.SzDecimals, asset.IsSpot)
+		case trigger.IsMarket:
+			order.LimitPx = slippageLimit(asset, b.isBuy, trigger.TriggerPx, DefaultSlippage)
+		default:
+			return types.OrderRequest{}, fmt.Errorf("limit trigger requires a limit price")
+		}
+		order.OrderType.Trigger = &trigger
+	}
+
+	if !order.LimitPx.IsPositive() {
+		return types.OrderRequest{}, fmt.Errorf("limit price must be positive")
+	}
+
+	return order, nil
+}
+
+func (b *OrderBuilder) setTif(tif string) *OrderBuilder {
+	if b.tif != "" && b.tif != tif {
+		b.fail(fmt.Errorf("time in force already set to %s", b.tif))
+		return b
+	}
+	b.tif = tif
+	return b
+}
+
+func (b *OrderBuilder) setTrigger(kind string, triggerPx decimal.Decimal) *OrderBuilder {
+	if b.trigger != nil {
+		b.fail(fmt.Errorf("trigger already set to %s", b.trigger.TpSl))
+		return b
+	}
+	b.trigger = &types.TriggerOrderType{TriggerPx: triggerPx, IsMarket: true, TpSl: kind}
+	return b
+}
+
+// fail records the first error
+func (b *OrderBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
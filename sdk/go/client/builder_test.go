@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	eth := &types.AssetInfo{ID: 1, Coin: "ETH", SzDecimals: 4, MaxLeverage: 25}
+	d := decimal.RequireFromString
+
+	order, err := Buy("ETH").Size(d("0.123456")).Limit(d("2000.123")).PostOnly().ReduceOnly().WithCloid().Build(eth)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !order.IsBuy || !order.ReduceOnly || order.OrderType.Limit == nil || order.OrderType.Limit.Tif != types.TifAlo {
+		t.Errorf("Unexpected order: %+v", order)
+	}
+	if !order.Sz.Equal(d("0.1235")) || !order.LimitPx.Equal(d("2000.1")) {
+		t.Errorf("Expected rounded size and price, got %s @ %s", order.Sz, order.LimitPx)
+	}
+	if order.Cloid == nil || utils.ValidateCloid(*order.Cloid) != nil {
+		t.Errorf("Expected generated cloid, got %v", order.Cloid)
+	}
+
+	stop, err := Sell("ETH").Size(d("1")).StopLoss(d("1800")).ReduceOnly().Build(eth)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if stop.OrderType.Trigger == nil || !stop.OrderType.Trigger.IsMarket || !stop.LimitPx.Equal(d("1710")) {
+		t.Errorf("Unexpected stop order: %+v", stop)
+	}
+
+	invalid := []struct {
+		name    string
+		builder *OrderBuilder
+	}{
+		{"post only trigger", Buy("ETH").Size(d("1")).Limit(d("2000")).PostOnly().TakeProfit(d("2100"))},
+		{"missing price", Buy("ETH").Size(d("1"))},
+		{"size rounds to zero", Buy("ETH").Size(d("0.00001")).Limit(d("2000"))},
+		{"conflicting tif", Buy("ETH").Size(d("1")).Limit(d("2000")).Ioc().PostOnly()},
+		{"limit trigger without price", Sell("ETH").Size(d("1")).StopLoss(d("1800")).TriggerLimit()},
+		{"bad cloid", Buy("ETH").Size(d("1")).Limit(d("2000")).Cloid("abc")},
+		{"wrong asset", Buy("BTC").Size(d("1")).Limit(d("2000"))},
+	}
+
+	for _, tt := range invalid {
+		if _, err := tt.builder.Build(eth); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestOrderBuilderResolve(t *testing.T) {
+	c := newTestClient(t, func(string, map[string]interface{}) interface{} { return nil })
+
+	order, err := Sell("BTC").Size(decimal.RequireFromString("0.0123456")).Limit(decimal.RequireFromString("65432.1")).Resolve(context.Background(), c.Info())
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if !order.Sz.Equal(decimal.RequireFromString("0.01235")) || !order.LimitPx.Equal(decimal.RequireFromString("65432")) {
+		t.Errorf("Unexpected resolved order: %s @ %s", order.Sz, order.LimitPx)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
 	"golang.org/x/time/rate"
 )
 
@@ -30,6 +33,16 @@ type Client struct {
 	rateLimiter *rate.Limiter
 	privateKey  string
 	address     string
+	mainnet     bool
+
+	assetsMu sync.RWMutex
+	assets   map[string]types.AssetInfo
+
+	builderMu   sync.RWMutex
+	builder     *types.BuilderInfo
+	builderFees map[string]int // Approved max fee per builder, tenths of a basis point
+
+	lastNonce atomic.Int64
 }
 
 // NewClient creates a new Hyperliquid client
@@ -38,6 +51,7 @@ func NewClient(baseURL, wsURL, privateKey string) *Client {
 		baseURL:    baseURL,
 		wsURL:      wsURL,
 		privateKey: privateKey,
+		mainnet:    baseURL == MainnetAPI,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -66,6 +80,64 @@ func (c *Client) GetAddress() string {
 	return c.address
 }
 
+// SetBuilder sets the builder code attached to every order placed through
+// PlaceOrder and PlaceOrders. Pass nil to stop attaching one.
+func (c *Client) SetBuilder(builder *types.BuilderInfo) {
+	c.builderMu.Lock()
+	defer c.builderMu.Unlock()
+	c.builder = builder
+}
+
+// GetBuilder returns the default builder code, if any
+func (c *Client) GetBuilder() *types.BuilderInfo {
+	c.builderMu.RLock()
+	defer c.builderMu.RUnlock()
+	return c.builder
+}
+
+// SetMainnet sets the chain user-signed actions are signed for. It
+// defaults to mainnet only when the base URL is MainnetAPI, so set it when
+// reaching mainnet through a proxy or another URL.
+func (c *Client) SetMainnet(mainnet bool) {
+	c.mainnet = mainnet
+}
+
+// IsMainnet reports whether the client signs for mainnet
+func (c *Client) IsMainnet() bool {
+	return c.mainnet
+}
+
+// nextNonce returns the current time in milliseconds, bumped past the last
+// nonce issued so that concurrent actions never reuse one
+func (c *Client) nextNonce() int64 {
+	for {
+		last := c.lastNonce.Load()
+		nonce := time.Now().UnixMilli()
+		if nonce <= last {
+			nonce = last + 1
+		}
+		if c.lastNonce.CompareAndSwap(last, nonce) {
+			return nonce
+		}
+	}
+}
+
+// SendError is a request failure after the request may have reached the
+// server, e.g. a timeout or a lost response, so the action it carried may
+// or may not have taken effect. Errors returned before sending, such as
+// signing or validation failures, are never SendErrors.
+type SendError struct {
+	Err error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
 // request performs an HTTP request with rate limiting
 func (c *Client) request(ctx context.Context, endpoint string, payload interface{}) ([]byte, error) {
 	// Apply rate limiting
@@ -90,17 +162,21 @@ func (c *Client) request(ctx context.Context, endpoint string, payload interface
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, &SendError{Err: fmt.Errorf("request failed: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, &SendError{Err: fmt.Errorf("failed to read response: %w", err)}
 	}
 
-	// Check status code
+	// Check status code. A server error may come after the action was
+	// processed, e.g. from a proxy.
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &SendError{Err: fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))}
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
 	}
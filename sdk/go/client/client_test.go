@@ -2,8 +2,17 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
 )
 
 func TestNewClient(t *testing.T) {
@@ -89,4 +98,738 @@ func TestSetAddress(t *testing.T) {
 	if client.GetAddress() != testAddress {
 		t.Errorf("Expected address %s, got %s", testAddress, client.GetAddress())
 	}
-}
\ No newline at end of file
+}
+
+func TestSetMainnet(t *testing.T) {
+	if !NewMainnetClient("test_key").IsMainnet() || NewTestnetClient("test_key").IsMainnet() {
+		t.Error("Expected the chain to default from the API URL")
+	}
+
+	proxied := NewClient("https://proxy.example.com", MainnetWS, "test_key")
+	if proxied.IsMainnet() {
+		t.Error("Expected an unknown URL to default to testnet")
+	}
+	proxied.SetMainnet(true)
+	if !proxied.IsMainnet() {
+		t.Error("Expected SetMainnet to override the URL default")
+	}
+}
+
+// Test private key (DO NOT USE IN PRODUCTION)
+const testPrivateKey = "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+var testMeta = map[string]interface{}{
+	"universe": []map[string]interface{}{
+		{"name": "BTC", "szDecimals": 5, "maxLeverage": 50},
+		{"name": "ETH", "szDecimals": 4, "maxLeverage": 25},
+	},
+}
+
+var testSpotMeta = map[string]interface{}{
+	"universe": []map[string]interface{}{
+		{"name": "PURR/USDC", "tokens": []int{1, 0}, "index": 0},
+	},
+	"tokens": []map[string]interface{}{
+		{"name": "USDC", "szDecimals": 8, "index": 0},
+		{"name": "PURR", "szDecimals": 0, "index": 1},
+	},
+}
+
+// newTestClient starts a server that answers metadata requests and passes
+// every other /info and /exchange body to handler
+func newTestClient(t *testing.T, handler func(endpoint string, body map[string]interface{}) interface{}) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		var resp interface{}
+		switch body["type"] {
+		case "meta":
+			resp = testMeta
+		case "spotMeta":
+			resp = testSpotMeta
+		default:
+			resp = handler(r.URL.Path, body)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewClient(server.URL, "", testPrivateKey)
+	c.SetAddress("0x1234567890123456789012345678901234567890")
+	return c
+}
+
+func TestGetAssetInfo(t *testing.T) {
+	c := newTestClient(t, func(string, map[string]interface{}) interface{} { return nil })
+	ctx := context.Background()
+
+	eth, err := c.Info().GetAssetInfo(ctx, "ETH")
+	if err != nil {
+		t.Fatalf("Failed to resolve ETH: %v", err)
+	}
+	if eth.ID != 1 || eth.SzDecimals != 4 || eth.IsSpot {
+		t.Errorf("Unexpected ETH info: %+v", eth)
+	}
+
+	purr, err := c.Info().GetAssetInfo(ctx, "@0")
+	if err != nil {
+		t.Fatalf("Failed to resolve @0: %v", err)
+	}
+	if purr.ID != 10000 || !purr.IsSpot || purr.SzDecimals != 0 {
+		t.Errorf("Unexpected PURR info: %+v", purr)
+	}
+
+	if _, err := c.Info().GetAssetInfo(ctx, "NOPE"); err == nil {
+		t.Error("Expected error for unknown asset")
+	}
+}
+
+func TestPlaceTwap(t *testing.T) {
+	var action map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		action = body["action"].(map[string]interface{})
+		return map[string]interface{}{
+			"status": "ok",
+			"response": map[string]interface{}{
+				"type": "twapOrder",
+				"data": map[string]interface{}{"status": map[string]interface{}{"running": map[string]interface{}{"twapId": 77}}},
+			},
+		}
+	})
+
+	resp, err := c.Exchange().PlaceTwap(context.Background(), "ETH", true, decimal.RequireFromString("1.5"), 30, true, false)
+	if err != nil {
+		t.Fatalf("PlaceTwap failed: %v", err)
+	}
+	if resp.Response.Data.Status.Running == nil || resp.Response.Data.Status.Running.TwapId != 77 {
+		t.Errorf("Unexpected TWAP response: %+v", resp)
+	}
+
+	twap := action["twap"].(map[string]interface{})
+	if action["type"] != "twapOrder" || twap["a"] != float64(1) || twap["s"] != "1.5" || twap["m"] != float64(30) || twap["t"] != true {
+		t.Errorf("Unexpected TWAP action: %+v", action)
+	}
+
+	if _, err := c.Exchange().PlaceTwap(context.Background(), "ETH", true, decimal.NewFromInt(1), 2, false, false); err == nil {
+		t.Error("Expected error for TWAP shorter than 5 minutes")
+	}
+}
+
+func TestCancelTwapRejected(t *testing.T) {
+	c := newTestClient(t, func(string, map[string]interface{}) interface{} {
+		return map[string]interface{}{
+			"status": "ok",
+			"response": map[string]interface{}{
+				"type": "twapCancel",
+				"data": map[string]interface{}{"status": map[string]interface{}{"error": "TWAP was never placed"}},
+			},
+		}
+	})
+
+	resp, err := c.Exchange().CancelTwap(context.Background(), "BTC", 5)
+	if err == nil {
+		t.Fatal("Expected error for rejected cancel")
+	}
+	if resp == nil || resp.Response.Data.Status.Error == nil {
+		t.Errorf("Expected typed error status, got %+v", resp)
+	}
+}
+
+func TestDeadMansSwitch(t *testing.T) {
+	var mu sync.Mutex
+	var actions []map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		mu.Lock()
+		actions = append(actions, body["action"].(map[string]interface{}))
+		mu.Unlock()
+		return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "default"}}
+	})
+
+	if _, err := NewDeadMansSwitch(c.Exchange(), time.Second, 500*time.Millisecond); err == nil {
+		t.Error("Expected error for window shorter than 5s")
+	}
+
+	dms, err := NewDeadMansSwitch(c.Exchange(), 10*time.Second, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create switch: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := dms.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if until := time.Until(dms.Deadline()); until < 9*time.Second {
+		t.Errorf("Expected deadline about 10s ahead, got %s", until)
+	}
+
+	time.Sleep(70 * time.Millisecond)
+	if err := dms.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if !dms.Deadline().IsZero() {
+		t.Error("Expected deadline to be cleared after Stop")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(actions) < 3 {
+		t.Fatalf("Expected initial heartbeat, refreshes and a clear, got %d actions", len(actions))
+	}
+	for _, action := range actions[:len(actions)-1] {
+		if action["type"] != "scheduleCancel" || action["time"] == nil {
+			t.Errorf("Unexpected heartbeat action: %+v", action)
+		}
+	}
+	if last := actions[len(actions)-1]; last["type"] != "scheduleCancel" || last["time"] != nil {
+		t.Errorf("Expected final action to clear the schedule, got %+v", last)
+	}
+}
+
+func TestBatchModify(t *testing.T) {
+	var action map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		action = body["action"].(map[string]interface{})
+		return map[string]interface{}{
+			"status": "ok",
+			"response": map[string]interface{}{
+				"type": "order",
+				"data": map[string]interface{}{"statuses": []interface{}{
+					map[string]interface{}{"resting": map[string]interface{}{"oid": 11}},
+					map[string]interface{}{"error": "Cannot modify canceled or filled order"},
+				}},
+			},
+		}
+	})
+
+	oid := int64(10)
+	cloid := "0x00000000000000000000000000000001"
+	order := types.OrderRequest{
+		Asset:      "ETH",
+		IsBuy:      true,
+		LimitPx:    decimal.RequireFromString("2000"),
+		Sz:         decimal.RequireFromString("0.5"),
+		ReduceOnly: true,
+		OrderType:  types.OrderType{Limit: &types.LimitOrderType{Tif: "Alo"}},
+		Cloid:      &cloid,
+	}
+
+	resp, err := c.Exchange().BatchModify(context.Background(), []types.ModifyRequest{
+		{Oid: &oid, Order: order},
+		{Cloid: &cloid, Order: order},
+	})
+	if err != nil {
+		t.Fatalf("BatchModify failed: %v", err)
+	}
+
+	statuses := resp.Response.Data.Statuses
+	if len(statuses) != 2 || statuses[0].Resting == nil || statuses[0].Resting.Oid != 11 || statuses[1].Error == nil {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+
+	modifies := action["modifies"].([]interface{})
+	if action["type"] != "batchModify" || len(modifies) != 2 {
+		t.Fatalf("Unexpected modify action: %+v", action)
+	}
+	first := modifies[0].(map[string]interface{})
+	second := modifies[1].(map[string]interface{})
+	if first["oid"] != float64(10) || second["oid"] != cloid {
+		t.Errorf("Expected oid and cloid keys, got %v and %v", first["oid"], second["oid"])
+	}
+	sent := first["order"].(map[string]interface{})
+	if sent["reduce_only"] != true || sent["cloid"] != cloid || sent["order_type"] == nil {
+		t.Errorf("Expected full replacement order, got %+v", sent)
+	}
+
+	if _, err := c.Exchange().ModifyOrder(context.Background(), types.ModifyRequest{Order: order}); err == nil {
+		t.Error("Expected error for modify without oid or cloid")
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	now := time.Now().UnixMilli()
+	cloid := "0xaa000000000000000000000000000001"
+	orders := []map[string]interface{}{
+		{"coin": "BTC", "side": "B", "oid": 1, "limitPx": "50000", "sz": "0.1", "timestamp": now},
+		{"coin": "BTC", "side": "A", "oid": 2, "limitPx": "51000", "sz": "0.1", "timestamp": now - 60000, "cloid": cloid},
+		{"coin": "ETH", "side": "A", "oid": 3, "limitPx": "3000", "sz": "1", "timestamp": now - 60000},
+	}
+	for oid := 4; oid < 4+MaxCancelsPerAction; oid++ {
+		orders = append(orders, map[string]interface{}{"coin": "ETH", "side": "B", "oid": oid, "limitPx": "2000", "sz": "1", "timestamp": now})
+	}
+
+	var mu sync.Mutex
+	var actions []map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		if body["type"] == "openOrders" {
+			return orders
+		}
+
+		action := body["action"].(map[string]interface{})
+		mu.Lock()
+		actions = append(actions, action)
+		mu.Unlock()
+
+		if action["type"] == "scheduleCancel" {
+			return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "default"}}
+		}
+		statuses := make([]interface{}, 0)
+		for _, raw := range action["cancels"].([]interface{}) {
+			cancel := raw.(map[string]interface{})
+			if cancel["o"] == float64(3) {
+				statuses = append(statuses, map[string]interface{}{"error": "Order was never placed, already canceled, or filled."})
+				continue
+			}
+			statuses = append(statuses, "success")
+		}
+		return map[string]interface{}{
+			"status":   "ok",
+			"response": map[string]interface{}{"type": "cancel", "data": map[string]interface{}{"statuses": statuses}},
+		}
+	})
+	ctx := context.Background()
+
+	asks := false
+	results, err := c.Exchange().CancelAll(ctx, types.CancelFilter{IsBuy: &asks, MinAge: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("CancelAll failed: %v", err)
+	}
+	if len(results) != 2 || !results[0].Status.Success || results[1].Status.Error == nil {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if len(actions) != 2 || actions[0]["type"] != "cancel" || actions[1]["type"] != "cancelByCloid" {
+		t.Fatalf("Expected one cancel by oid and one by cloid, got %+v", actions)
+	}
+	byCloid := actions[1]["cancels"].([]interface{})[0].(map[string]interface{})
+	if byCloid["asset"] != float64(0) || byCloid["cloid"] != cloid {
+		t.Errorf("Unexpected cancelByCloid entry: %+v", byCloid)
+	}
+
+	results, err = c.Exchange().CancelAll(ctx, types.CancelFilter{Coins: []string{"BTC"}, CloidPrefix: "0xaa"})
+	if err != nil || len(results) != 1 || results[0].Order.Oid != 2 {
+		t.Errorf("Expected only the prefixed BTC order, got %+v (%v)", results, err)
+	}
+
+	actions = nil
+	results, err = c.Exchange().PanicCancel(ctx)
+	if err != nil {
+		t.Fatalf("PanicCancel failed: %v", err)
+	}
+	if len(results) != len(orders) {
+		t.Errorf("Expected %d results, got %d", len(orders), len(results))
+	}
+
+	counts := make(map[string]int)
+	for _, action := range actions {
+		counts[action["type"].(string)]++
+		if cancels, ok := action["cancels"].([]interface{}); ok && len(cancels) > MaxCancelsPerAction {
+			t.Errorf("Batch of %d exceeds limit", len(cancels))
+		}
+	}
+	if counts["scheduleCancel"] != 1 || counts["cancel"] != 2 || counts["cancelByCloid"] != 1 {
+		t.Errorf("Unexpected panic actions: %v", counts)
+	}
+}
+
+func TestMarketOpenAndClose(t *testing.T) {
+	var order map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		switch body["type"] {
+		case "allMids":
+			return map[string]string{"BTC": "50000.5", "ETH": "2000"}
+		case "clearinghouseState":
+			return map[string]interface{}{
+				"assetPositions": []interface{}{
+					map[string]interface{}{"type": "oneWay", "position": map[string]interface{}{"coin": "BTC", "szi": "-0.5", "entryPx": "51000"}},
+				},
+			}
+		}
+
+		action := body["action"].(map[string]interface{})
+		order = action["orders"].([]interface{})[0].(map[string]interface{})
+		return map[string]interface{}{
+			"status": "ok",
+			"response": map[string]interface{}{
+				"type": "order",
+				"data": map[string]interface{}{"statuses": []interface{}{
+					map[string]interface{}{"filled": map[string]interface{}{"totalSz": order["sz"], "avgPx": "2001", "oid": 9}},
+				}},
+			},
+		}
+	})
+	ctx := context.Background()
+
+	filled, err := c.Exchange().MarketOpen(ctx, "ETH", true, decimal.RequireFromString("0.12345"), 0.05)
+	if err != nil {
+		t.Fatalf("MarketOpen failed: %v", err)
+	}
+	if filled.Oid != 9 || !filled.TotalSz.Equal(decimal.RequireFromString("0.1235")) {
+		t.Errorf("Unexpected fill summary: %+v", filled)
+	}
+	tif := order["order_type"].(map[string]interface{})["limit"].(map[string]interface{})["tif"]
+	if order["limit_px"] != "2100" || order["sz"] != "0.1235" || tif != "Ioc" || order["reduce_only"] != false {
+		t.Errorf("Unexpected market order: %+v", order)
+	}
+
+	if _, err := c.Exchange().MarketClose(ctx, "BTC"); err != nil {
+		t.Fatalf("MarketClose failed: %v", err)
+	}
+	if order["coin"] != "BTC" || order["is_buy"] != true || order["reduce_only"] != true || order["sz"] != "0.5" || order["limit_px"] != "52501" {
+		t.Errorf("Unexpected close order: %+v", order)
+	}
+
+	if _, err := c.Exchange().MarketClose(ctx, "ETH"); err == nil {
+		t.Error("Expected error closing a coin without a position")
+	}
+}
+
+func TestPlaceBracketAndPositionTpsl(t *testing.T) {
+	var action map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		if body["type"] == "clearinghouseState" {
+			return map[string]interface{}{
+				"assetPositions": []interface{}{
+					map[string]interface{}{"type": "oneWay", "position": map[string]interface{}{"coin": "BTC", "szi": "-0.5", "entryPx": "51000"}},
+				},
+			}
+		}
+		action = body["action"].(map[string]interface{})
+		return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "order", "data": map[string]interface{}{"statuses": []interface{}{}}}}
+	})
+	ctx := context.Background()
+
+	entry := types.OrderRequest{
+		Asset:     "ETH",
+		IsBuy:     true,
+		LimitPx:   decimal.RequireFromString("2000"),
+		Sz:        decimal.RequireFromString("1"),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: "Gtc"}},
+	}
+	tp := decimal.RequireFromString("2200")
+	sl := decimal.RequireFromString("1900")
+
+	if _, err := c.Exchange().PlaceBracket(ctx, entry, &tp, &sl); err != nil {
+		t.Fatalf("PlaceBracket failed: %v", err)
+	}
+	orders := action["orders"].([]interface{})
+	if action["grouping"] != "normalTpsl" || len(orders) != 3 {
+		t.Fatalf("Unexpected bracket action: %+v", action)
+	}
+	tpOrder := orders[1].(map[string]interface{})
+	trigger := tpOrder["order_type"].(map[string]interface{})["trigger"].(map[string]interface{})
+	if tpOrder["is_buy"] != false || tpOrder["reduce_only"] != true || trigger["tp_sl"] != "tp" || trigger["trigger_px"] != "2200" || tpOrder["limit_px"] != "2090" {
+		t.Errorf("Unexpected take profit leg: %+v", tpOrder)
+	}
+
+	if _, err := c.Exchange().PlaceBracket(ctx, entry, &sl, nil); err == nil {
+		t.Error("Expected error for take profit below a long entry")
+	}
+
+	shortTp := decimal.RequireFromString("48000")
+	shortSl := decimal.RequireFromString("53000")
+	if _, err := c.Exchange().SetPositionTpsl(ctx, "BTC", &shortTp, &shortSl); err != nil {
+		t.Fatalf("SetPositionTpsl failed: %v", err)
+	}
+	orders = action["orders"].([]interface{})
+	slOrder := orders[1].(map[string]interface{})
+	if action["grouping"] != "positionTpsl" || len(orders) != 2 || slOrder["is_buy"] != true || slOrder["sz"] != "0.5" {
+		t.Errorf("Unexpected position TP/SL action: %+v", action)
+	}
+
+	if _, err := c.Exchange().SetPositionTpsl(ctx, "BTC", &shortSl, nil); err == nil {
+		t.Error("Expected error for take profit above a short entry")
+	}
+}
+
+func TestBuilderFee(t *testing.T) {
+	builder := "0xABCDEFabcdef0123456789012345678901234567"
+	var action map[string]interface{}
+	var nonce interface{}
+	maxFeeQueries := 0
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		if body["type"] == "maxBuilderFee" {
+			maxFeeQueries++
+			return 20
+		}
+		action = body["action"].(map[string]interface{})
+		nonce = body["nonce"]
+		if action["type"] == "approveBuilderFee" {
+			return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "default"}}
+		}
+		return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "order", "data": map[string]interface{}{"statuses": []interface{}{}}}}
+	})
+	ctx := context.Background()
+
+	order := types.OrderRequest{
+		Asset:     "ETH",
+		IsBuy:     true,
+		LimitPx:   decimal.RequireFromString("2000"),
+		Sz:        decimal.RequireFromString("1"),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: types.TifGtc}},
+	}
+
+	c.SetBuilder(&types.BuilderInfo{Builder: builder, Fee: 10})
+	if _, err := c.Exchange().PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	attached := action["builder"].(map[string]interface{})
+	if attached["b"] != strings.ToLower(builder) || attached["f"] != float64(10) {
+		t.Errorf("Unexpected builder: %+v", attached)
+	}
+
+	over := &types.BuilderInfo{Builder: builder, Fee: 30}
+	if _, err := c.Exchange().PlaceOrdersWithBuilder(ctx, []types.OrderRequest{order}, types.GroupingNA, over); err == nil {
+		t.Error("Expected error for fee above approved maximum")
+	}
+
+	if _, err := c.Exchange().ApproveBuilderFee(ctx, builder, 50); err != nil {
+		t.Fatalf("ApproveBuilderFee failed: %v", err)
+	}
+	if action["maxFeeRate"] != "0.05%" || action["builder"] != strings.ToLower(builder) || action["nonce"] != nonce || action["hyperliquidChain"] != "Testnet" {
+		t.Errorf("Unexpected approval action: %+v (nonce %v)", action, nonce)
+	}
+
+	if _, err := c.Exchange().PlaceOrdersWithBuilder(ctx, []types.OrderRequest{order}, types.GroupingNA, over); err != nil {
+		t.Errorf("Expected fee within new approval to pass: %v", err)
+	}
+	if maxFeeQueries != 1 {
+		t.Errorf("Expected approved fee to be cached, got %d queries", maxFeeQueries)
+	}
+
+	perpLimit := &types.BuilderInfo{Builder: builder, Fee: types.MaxPerpBuilderFee + 1}
+	if _, err := c.Exchange().PlaceOrdersWithBuilder(ctx, []types.OrderRequest{order}, types.GroupingNA, perpLimit); err == nil {
+		t.Error("Expected error for fee above the perp limit")
+	}
+
+	c.SetBuilder(nil)
+	if _, err := c.Exchange().PlaceOrder(ctx, order); err != nil || action["builder"] != nil {
+		t.Errorf("Expected no builder after clearing, got %+v (%v)", action["builder"], err)
+	}
+}
+
+func TestStaking(t *testing.T) {
+	validator := "0x5ac99df645f3414876c816caa18b2d234024b487"
+	var action map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		switch body["type"] {
+		case "delegations":
+			return []interface{}{map[string]interface{}{"validator": validator, "amount": "12060.16529862", "lockedUntilTimestamp": 1735466781353}}
+		case "delegatorHistory":
+			return []interface{}{
+				map[string]interface{}{"time": 1, "hash": "0x1", "delta": map[string]interface{}{"delegate": map[string]interface{}{"validator": validator, "amount": "10000.0", "isUndelegate": false}}},
+				map[string]interface{}{"time": 2, "hash": "0x2", "delta": map[string]interface{}{"withdrawal": map[string]interface{}{"amount": "5.0", "phase": "initiated"}}},
+			}
+		}
+		action = body["action"].(map[string]interface{})
+		return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "default"}}
+	})
+	ctx := context.Background()
+
+	if _, err := c.Exchange().TokenDelegate(ctx, validator, decimal.RequireFromString("1.5"), true); err != nil {
+		t.Fatalf("TokenDelegate failed: %v", err)
+	}
+	if action["type"] != "tokenDelegate" || action["wei"] != float64(150000000) || action["isUndelegate"] != true || action["validator"] != validator {
+		t.Errorf("Unexpected delegate action: %+v", action)
+	}
+
+	if _, err := c.Exchange().CWithdraw(ctx, decimal.RequireFromString("2")); err != nil {
+		t.Fatalf("CWithdraw failed: %v", err)
+	}
+	if action["type"] != "cWithdraw" || action["wei"] != float64(200000000) || action["nonce"] == nil {
+		t.Errorf("Unexpected withdraw action: %+v", action)
+	}
+
+	if _, err := c.Exchange().CDeposit(ctx, decimal.RequireFromString("0.000000001")); err == nil {
+		t.Error("Expected error for amount below wei precision")
+	}
+
+	delegations, err := c.Info().GetDelegations(ctx, c.GetAddress())
+	if err != nil || len(delegations) != 1 || !delegations[0].Amount.Equal(decimal.RequireFromString("12060.16529862")) {
+		t.Errorf("Unexpected delegations: %+v (%v)", delegations, err)
+	}
+
+	history, err := c.Info().GetDelegatorHistory(ctx, c.GetAddress())
+	if err != nil || len(history) != 2 || history[0].Delta.Delegate == nil || history[1].Delta.Withdrawal == nil {
+		t.Errorf("Unexpected history: %+v (%v)", history, err)
+	}
+}
+
+func TestLeverageAndIsolatedMargin(t *testing.T) {
+	var action map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		if body["type"] == "clearinghouseState" {
+			return map[string]interface{}{
+				"assetPositions": []interface{}{
+					map[string]interface{}{"type": "oneWay", "position": map[string]interface{}{
+						"coin": "ETH", "szi": "-2", "positionValue": "4000", "marginUsed": "300",
+						"leverage": map[string]interface{}{"type": "isolated", "value": "10"},
+					}},
+					map[string]interface{}{"type": "oneWay", "position": map[string]interface{}{
+						"coin": "BTC", "szi": "0.1", "positionValue": "5000", "marginUsed": "500",
+						"leverage": map[string]interface{}{"type": "cross", "value": "10"},
+					}},
+				},
+			}
+		}
+		action = body["action"].(map[string]interface{})
+		return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "default"}}
+	})
+	ctx := context.Background()
+
+	if _, err := c.Exchange().UpdateLeverage(ctx, "ETH", types.MarginIsolated, 10); err != nil {
+		t.Fatalf("UpdateLeverage failed: %v", err)
+	}
+	if action["asset"] != float64(1) || action["isCross"] != false || action["leverage"] != float64(10) {
+		t.Errorf("Unexpected leverage action: %+v", action)
+	}
+	if _, err := c.Exchange().UpdateLeverage(ctx, "ETH", types.MarginCross, 30); err == nil {
+		t.Error("Expected error for leverage above the asset maximum")
+	}
+	if _, err := c.Exchange().UpdateLeverage(ctx, "ETH", "cros", 5); err == nil {
+		t.Error("Expected error for unknown margin mode")
+	}
+
+	if _, err := c.Exchange().UpdateIsolatedMargin(ctx, "ETH", decimal.RequireFromString("-12.5")); err != nil {
+		t.Fatalf("UpdateIsolatedMargin failed: %v", err)
+	}
+	if action["ntli"] != float64(-12500000) || action["isBuy"] != false {
+		t.Errorf("Unexpected margin action: %+v", action)
+	}
+	if _, err := c.Exchange().UpdateIsolatedMargin(ctx, "BTC", decimal.NewFromInt(10)); err == nil {
+		t.Error("Expected error for cross position")
+	}
+	if _, err := c.Exchange().UpdateIsolatedMargin(ctx, "ETH", decimal.RequireFromString("0.0000001")); err == nil {
+		t.Error("Expected error for sub-micro amount")
+	}
+
+	if _, err := c.Exchange().TopUpIsolatedMargin(ctx, "ETH", decimal.NewFromInt(10)); err != nil {
+		t.Fatalf("TopUpIsolatedMargin failed: %v", err)
+	}
+	if action["ntli"] != float64(100000000) {
+		t.Errorf("Expected 100 USDC top up, got %+v", action)
+	}
+
+	resp, err := c.Exchange().TopUpIsolatedMargin(ctx, "ETH", decimal.NewFromInt(20))
+	if err != nil || resp != nil {
+		t.Errorf("Expected no top up when margin suffices, got %+v (%v)", resp, err)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"status":"err","response":"Insufficient margin to place order."}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "", testPrivateKey)
+	order := types.OrderRequest{
+		Asset:     "BTC",
+		IsBuy:     true,
+		LimitPx:   decimal.NewFromInt(50000),
+		Sz:        decimal.NewFromInt(1),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: types.TifGtc}},
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		want   bool
+	}{
+		{"rejected by the exchange", http.StatusOK, false},
+		{"malformed request", http.StatusUnprocessableEntity, false},
+		{"server error after sending", http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = tt.status
+			_, err := c.Exchange().PlaceOrders(context.Background(), []types.OrderRequest{order}, types.GroupingNA)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if got := errors.As(err, new(*SendError)); got != tt.want {
+				t.Errorf("Expected SendError %v, got %v (%v)", tt.want, got, err)
+			}
+		})
+	}
+
+	server.Close()
+	if _, err := c.Exchange().PlaceOrders(context.Background(), []types.OrderRequest{order}, types.GroupingNA); !errors.As(err, new(*SendError)) {
+		t.Errorf("Expected a transport failure to be a SendError, got %v", err)
+	}
+}
+
+func TestNextNonce(t *testing.T) {
+	c := NewClient("", "", testPrivateKey)
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				nonce := c.nextNonce()
+				mu.Lock()
+				if seen[nonce] {
+					t.Errorf("Expected unique nonces, got %d twice", nonce)
+				}
+				seen[nonce] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	last := c.nextNonce()
+	if next := c.nextNonce(); next <= last {
+		t.Errorf("Expected nonces to increase, got %d after %d", next, last)
+	}
+}
+
+func TestCancelOrdersWireFormat(t *testing.T) {
+	var actions []map[string]interface{}
+	c := newTestClient(t, func(endpoint string, body map[string]interface{}) interface{} {
+		actions = append(actions, body["action"].(map[string]interface{}))
+		return map[string]interface{}{
+			"status":   "ok",
+			"response": map[string]interface{}{"type": "cancel", "data": map[string]interface{}{"statuses": []interface{}{"success"}}},
+		}
+	})
+	ctx := context.Background()
+
+	oid := int64(7)
+	if _, err := c.Exchange().CancelOrder(ctx, types.CancelRequest{Asset: "ETH", Oid: &oid}); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	cloid := "0xaa000000000000000000000000000001"
+	if _, err := c.Exchange().CancelOrders(ctx, []types.CancelRequest{{Asset: "BTC", Cloid: &cloid}}); err != nil {
+		t.Fatalf("CancelOrders failed: %v", err)
+	}
+
+	if len(actions) != 2 || actions[0]["type"] != "cancel" || actions[1]["type"] != "cancelByCloid" {
+		t.Fatalf("Expected one cancel by oid and one by cloid, got %+v", actions)
+	}
+	byOid := actions[0]["cancels"].([]interface{})[0].(map[string]interface{})
+	if byOid["a"] != float64(1) || byOid["o"] != float64(7) || len(byOid) != 2 {
+		t.Errorf("Expected the {a, o} wire form, got %+v", byOid)
+	}
+	byCloid := actions[1]["cancels"].([]interface{})[0].(map[string]interface{})
+	if byCloid["asset"] != float64(0) || byCloid["cloid"] != cloid {
+		t.Errorf("Unexpected cancelByCloid entry: %+v", byCloid)
+	}
+
+	mixed := []types.CancelRequest{{Asset: "ETH", Oid: &oid}, {Asset: "BTC", Cloid: &cloid}}
+	if _, err := c.Exchange().CancelOrders(ctx, mixed); err == nil {
+		t.Error("Expected a batch mixing oids and cloids to be refused")
+	}
+	if len(actions) != 2 {
+		t.Errorf("Expected nothing sent for a refused batch, got %d actions", len(actions))
+	}
+}
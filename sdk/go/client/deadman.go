@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DeadMansSwitch keeps a scheduled cancel a fixed window ahead of now. If
+// the process stops sending heartbeats, the exchange cancels every resting
+// order once the window elapses.
+type DeadMansSwitch struct {
+	exchange *ExchangeClient
+	window   time.Duration
+	interval time.Duration
+
+	mu          sync.Mutex
+	healthCheck func() error
+	onError     func(error)
+	deadline    time.Time
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewDeadMansSwitch creates a switch that schedules the cancel window ahead
+// of now and refreshes it every interval. The window must be at least 5s
+// and longer than the interval.
+func NewDeadMansSwitch(exchange *ExchangeClient, window, interval time.Duration) (*DeadMansSwitch, error) {
+	if window < 5*time.Second {
+		return nil, fmt.Errorf("window must be at least 5s, got %s", window)
+	}
+	if interval <= 0 || interval >= window {
+		return nil, fmt.Errorf("interval must be positive and shorter than the window, got %s", interval)
+	}
+
+	return &DeadMansSwitch{
+		exchange: exchange,
+		window:   window,
+		interval: interval,
+	}, nil
+}
+
+// SetHealthCheck sets a check run before each heartbeat. While it returns an
+// error heartbeats are skipped, so the scheduled cancel fires.
+func (d *DeadMansSwitch) SetHealthCheck(check func() error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.healthCheck = check
+}
+
+// OnError sets a callback for failed heartbeats
+func (d *DeadMansSwitch) OnError(handler func(error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onError = handler
+}
+
+// Start arms the switch and keeps it armed until Stop or ctx is done
+func (d *DeadMansSwitch) Start(ctx context.Context) error {
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.mu.Unlock()
+		return fmt.Errorf("dead man's switch already started")
+	}
+	d.mu.Unlock()
+
+	if err := d.Heartbeat(ctx); err != nil {
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	d.mu.Lock()
+	d.cancel = cancel
+	d.done = done
+	d.mu.Unlock()
+
+	go d.loop(loopCtx, done)
+	return nil
+}
+
+// Stop stops the heartbeat and clears the scheduled cancel so resting orders
+// survive a graceful shutdown
+func (d *DeadMansSwitch) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	if _, err := d.exchange.ScheduleCancel(ctx, nil); err != nil {
+		return fmt.Errorf("failed to clear scheduled cancel: %w", err)
+	}
+
+	d.mu.Lock()
+	d.deadline = time.Time{}
+	d.mu.Unlock()
+
+	return nil
+}
+
+// Heartbeat pushes the scheduled cancel one window ahead of now
+func (d *DeadMansSwitch) Heartbeat(ctx context.Context) error {
+	d.mu.Lock()
+	check := d.healthCheck
+	d.mu.Unlock()
+
+	if check != nil {
+		if err := check(); err != nil {
+			return fmt.Errorf("health check failed: %w", err)
+		}
+	}
+
+	deadline := time.Now().Add(d.window)
+	if _, err := d.exchange.ScheduleCancel(ctx, &deadline); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.deadline = deadline
+	d.mu.Unlock()
+
+	return nil
+}
+
+// Deadline returns when resting orders will be cancelled if no further
+// heartbeat succeeds, or the zero time when the switch is disarmed
+func (d *DeadMansSwitch) Deadline() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.deadline
+}
+
+func (d *DeadMansSwitch) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Heartbeat(ctx); err != nil && ctx.Err() == nil {
+				d.mu.Lock()
+				handler := d.onError
+				d.mu.Unlock()
+				if handler != nil {
+					handler(err)
+				}
+			}
+		}
+	}
+}
@@ -3,45 +3,54 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
 	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/shopspring/decimal"
 )
 
 // ExchangeClient methods for trading operations that require authentication
 
-// PlaceOrder places a new order
-func (e *ExchangeClient) PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error) {
-	action := map[string]interface{}{
-		"type":       "order",
-		"orders":     []types.OrderRequest{order},
-		"grouping":   "na",
-	}
+const (
+	// MaxCancelsPerAction bounds the cancels sent in one signed action
+	MaxCancelsPerAction = 100
 
-	payload, err := e.createSignedRequest(action)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
-	}
+	// DefaultSlippage is the slippage used by MarketClose and TP/SL triggers
+	DefaultSlippage = 0.05
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to place order: %w", err)
-	}
+	// panicCancelDelay leaves headroom over the 5s minimum for scheduleCancel
+	panicCancelDelay = 10 * time.Second
+)
 
-	var orderResp types.OrderResponse
-	if err := json.Unmarshal(resp, &orderResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order response: %w", err)
-	}
+// PlaceOrder places a new order
+func (e *ExchangeClient) PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error) {
+	return e.PlaceOrders(ctx, []types.OrderRequest{order}, types.GroupingNA)
+}
 
-	return &orderResp, nil
+// PlaceOrders places multiple orders atomically, attaching the client's
+// default builder code if one is set
+func (e *ExchangeClient) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error) {
+	return e.PlaceOrdersWithBuilder(ctx, orders, grouping, e.client.GetBuilder())
 }
 
-// PlaceOrders places multiple orders atomically
-func (e *ExchangeClient) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping string) (*types.OrderResponse, error) {
+// PlaceOrdersWithBuilder places orders with an explicit builder code, or
+// none if builder is nil. The fee must not exceed what the user approved.
+func (e *ExchangeClient) PlaceOrdersWithBuilder(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping, builder *types.BuilderInfo) (*types.OrderResponse, error) {
 	if grouping == "" {
-		grouping = "na"
+		grouping = types.GroupingNA
+	}
+
+	for i, order := range orders {
+		if order.OrderType.Trigger != nil {
+			if err := order.OrderType.Trigger.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid trigger on order %d: %w", i, err)
+			}
+		}
 	}
 
 	action := map[string]interface{}{
@@ -50,12 +59,20 @@ func (e *ExchangeClient) PlaceOrders(ctx context.Context, orders []types.OrderRe
 		"grouping": grouping,
 	}
 
+	if builder != nil {
+		normalized, err := e.checkBuilder(ctx, orders, *builder)
+		if err != nil {
+			return nil, err
+		}
+		action["builder"] = normalized
+	}
+
 	payload, err := e.createSignedRequest(action)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create signed request: %w", err)
 	}
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
+	resp, err := e.submit(ctx, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to place orders: %w", err)
 	}
@@ -70,19 +87,49 @@ func (e *ExchangeClient) PlaceOrders(ctx context.Context, orders []types.OrderRe
 
 // CancelOrder cancels an order by ID or client order ID
 func (e *ExchangeClient) CancelOrder(ctx context.Context, cancel types.CancelRequest) (*types.APIResponse, error) {
-	action := map[string]interface{}{
-		"type":    "cancel",
-		"cancels": []types.CancelRequest{cancel},
+	return e.CancelOrders(ctx, []types.CancelRequest{cancel})
+}
+
+// CancelOrders cancels multiple orders in one action. Each request names its
+// order by exactly one of Oid or Cloid, and a batch must use the same one
+// throughout since the exchange cancels by oid and by cloid in separate
+// actions. CancelByCloid and CancelAll return typed per-order statuses.
+func (e *ExchangeClient) CancelOrders(ctx context.Context, cancels []types.CancelRequest) (*types.APIResponse, error) {
+	if len(cancels) == 0 {
+		return nil, fmt.Errorf("no cancels given")
 	}
 
-	payload, err := e.createSignedRequest(action)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
+	byCloid := cancels[0].Cloid != nil
+	wire := make([]map[string]interface{}, 0, len(cancels))
+	for i, cancel := range cancels {
+		if (cancel.Oid == nil) == (cancel.Cloid == nil) {
+			return nil, fmt.Errorf("cancel %d must set exactly one of oid or cloid", i)
+		}
+		if (cancel.Cloid != nil) != byCloid {
+			return nil, fmt.Errorf("cancel %d: a batch cannot mix oids and cloids", i)
+		}
+
+		asset, err := e.client.Info().GetAssetInfo(ctx, cancel.Asset)
+		if err != nil {
+			return nil, err
+		}
+		if byCloid {
+			wire = append(wire, map[string]interface{}{"asset": asset.ID, "cloid": *cancel.Cloid})
+		} else {
+			wire = append(wire, map[string]interface{}{"a": asset.ID, "o": *cancel.Oid})
+		}
 	}
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
+	actionType := "cancel"
+	if byCloid {
+		actionType = "cancelByCloid"
+	}
+	resp, err := e.postAction(ctx, map[string]interface{}{
+		"type":    actionType,
+		"cancels": wire,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to cancel order: %w", err)
+		return nil, fmt.Errorf("failed to cancel orders: %w", err)
 	}
 
 	var apiResp types.APIResponse
@@ -93,101 +140,227 @@ func (e *ExchangeClient) CancelOrder(ctx context.Context, cancel types.CancelReq
 	return &apiResp, nil
 }
 
-// CancelOrders cancels multiple orders
-func (e *ExchangeClient) CancelOrders(ctx context.Context, cancels []types.CancelRequest) (*types.APIResponse, error) {
-	action := map[string]interface{}{
-		"type":    "cancel",
-		"cancels": cancels,
+// CancelByCloid cancels orders on coin by client order ID and returns one
+// status per cloid
+func (e *ExchangeClient) CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error) {
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
 	}
 
-	payload, err := e.createSignedRequest(action)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
+	cancels := make([]map[string]interface{}, 0, len(cloids))
+	for _, cloid := range cloids {
+		cancels = append(cancels, map[string]interface{}{"asset": asset.ID, "cloid": cloid})
 	}
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
+	return e.sendCancelBatch(ctx, "cancelByCloid", cancels)
+}
+
+// CancelAll cancels every open order matching filter and returns one result
+// per matched order. Orders carrying a cloid are cancelled by cloid, which
+// stays valid if the order is modified concurrently; the rest by oid.
+func (e *ExchangeClient) CancelAll(ctx context.Context, filter types.CancelFilter) ([]types.CancelResult, error) {
+	orders, err := e.openOrders(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to cancel orders: %w", err)
+		return nil, err
 	}
 
-	var apiResp types.APIResponse
-	if err := json.Unmarshal(resp, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cancel response: %w", err)
+	now := time.Now()
+	matched := make([]types.OpenOrder, 0, len(orders))
+	for _, order := range orders {
+		if filter.Matches(order, now) {
+			matched = append(matched, order)
+		}
 	}
 
-	return &apiResp, nil
+	return e.cancelOpenOrders(ctx, matched, false), nil
 }
 
-// CancelAllOrders cancels all orders for an asset
-func (e *ExchangeClient) CancelAllOrders(ctx context.Context, asset string) (*types.APIResponse, error) {
-	action := map[string]interface{}{
-		"type": "cancelByCloid",
-		"cancels": []map[string]interface{}{
-			{
-				"asset": asset,
-			},
-		},
-	}
+// PanicCancel schedules a cancel of everything a few seconds out, as a
+// backstop for orders placed in the meantime, then cancels every open order
+// with all batches sent concurrently. The scheduled cancel is left in place.
+func (e *ExchangeClient) PanicCancel(ctx context.Context) ([]types.CancelResult, error) {
+	at := time.Now().Add(panicCancelDelay)
+	_, scheduleErr := e.ScheduleCancel(ctx, &at)
 
-	payload, err := e.createSignedRequest(action)
+	orders, err := e.openOrders(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
+		return nil, errors.Join(scheduleErr, err)
 	}
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to cancel all orders: %w", err)
+	return e.cancelOpenOrders(ctx, orders, true), scheduleErr
+}
+
+func (e *ExchangeClient) openOrders(ctx context.Context) ([]types.OpenOrder, error) {
+	if e.client.address == "" {
+		return nil, fmt.Errorf("address not set")
 	}
+	return e.client.Info().GetOpenOrders(ctx, e.client.address)
+}
 
-	var apiResp types.APIResponse
-	if err := json.Unmarshal(resp, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cancel response: %w", err)
+// cancelOpenOrders cancels orders in batches of at most MaxCancelsPerAction,
+// recording a failed batch against each of its orders
+func (e *ExchangeClient) cancelOpenOrders(ctx context.Context, orders []types.OpenOrder, concurrent bool) []types.CancelResult {
+	results := make([]types.CancelResult, len(orders))
+
+	type batch struct {
+		action  string
+		indexes []int
+		cancels []map[string]interface{}
+	}
+	var byOid, byCloid []*batch
+	appendTo := func(batches []*batch, action string, index int, cancel map[string]interface{}) []*batch {
+		if len(batches) == 0 || len(batches[len(batches)-1].indexes) >= MaxCancelsPerAction {
+			batches = append(batches, &batch{action: action})
+		}
+		b := batches[len(batches)-1]
+		b.indexes = append(b.indexes, index)
+		b.cancels = append(b.cancels, cancel)
+		return batches
 	}
 
-	return &apiResp, nil
+	for i, order := range orders {
+		results[i].Order = order
+
+		asset, err := e.client.Info().GetAssetInfo(ctx, order.Coin)
+		if err != nil {
+			message := err.Error()
+			results[i].Status.Error = &message
+			continue
+		}
+
+		if order.Cloid != nil {
+			byCloid = appendTo(byCloid, "cancelByCloid", i, map[string]interface{}{"asset": asset.ID, "cloid": *order.Cloid})
+		} else {
+			byOid = appendTo(byOid, "cancel", i, map[string]interface{}{"a": asset.ID, "o": order.Oid})
+		}
+	}
+
+	send := func(b *batch) {
+		statuses, err := e.sendCancelBatch(ctx, b.action, b.cancels)
+		for j, index := range b.indexes {
+			switch {
+			case err != nil:
+				message := err.Error()
+				results[index].Status.Error = &message
+			case j < len(statuses):
+				results[index].Status = statuses[j]
+			default:
+				message := "missing cancel status"
+				results[index].Status.Error = &message
+			}
+		}
+	}
+
+	batches := append(byOid, byCloid...)
+	if !concurrent {
+		for _, b := range batches {
+			send(b)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for _, b := range batches {
+		wg.Add(1)
+		go func(b *batch) {
+			defer wg.Done()
+			send(b)
+		}(b)
+	}
+	wg.Wait()
+
+	return results
 }
 
-// ModifyOrder modifies an existing order
-func (e *ExchangeClient) ModifyOrder(ctx context.Context, modify types.ModifyRequest) (*types.APIResponse, error) {
+func (e *ExchangeClient) sendCancelBatch(ctx context.Context, actionType string, cancels []map[string]interface{}) ([]types.ActionStatus, error) {
 	action := map[string]interface{}{
-		"type":   "modify",
-		"oid":    modify.Oid,
-		"order":  modify,
+		"type":    actionType,
+		"cancels": cancels,
 	}
 
-	payload, err := e.createSignedRequest(action)
+	resp, err := e.postAction(ctx, action)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
+		return nil, fmt.Errorf("failed to cancel orders: %w", err)
 	}
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
+	var cancelResp types.CancelResponse
+	if err := json.Unmarshal(resp, &cancelResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cancel response: %w", err)
+	}
+
+	return cancelResp.Response.Data.Statuses, nil
+}
+
+// ModifyOrder replaces a resting order with a new one
+func (e *ExchangeClient) ModifyOrder(ctx context.Context, modify types.ModifyRequest) (*types.OrderResponse, error) {
+	return e.BatchModify(ctx, []types.ModifyRequest{modify})
+}
+
+// BatchModify replaces several resting orders in one signed action. The
+// response carries one status per modify, in request order.
+func (e *ExchangeClient) BatchModify(ctx context.Context, modifies []types.ModifyRequest) (*types.OrderResponse, error) {
+	if len(modifies) == 0 {
+		return nil, fmt.Errorf("no modifies given")
+	}
+	for i, modify := range modifies {
+		if (modify.Oid == nil) == (modify.Cloid == nil) {
+			return nil, fmt.Errorf("modify %d must set exactly one of oid or cloid", i)
+		}
+	}
+
+	action := map[string]interface{}{
+		"type":     "batchModify",
+		"modifies": modifies,
+	}
+
+	resp, err := e.postAction(ctx, action)
 	if err != nil {
-		return nil, fmt.Errorf("failed to modify order: %w", err)
+		return nil, fmt.Errorf("failed to modify orders: %w", err)
 	}
 
-	var apiResp types.APIResponse
-	if err := json.Unmarshal(resp, &apiResp); err != nil {
+	var orderResp types.OrderResponse
+	if err := json.Unmarshal(resp, &orderResp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal modify response: %w", err)
 	}
 
-	return &apiResp, nil
+	return &orderResp, nil
 }
 
-// UpdateLeverage updates leverage for an asset
-func (e *ExchangeClient) UpdateLeverage(ctx context.Context, asset string, leverageMode string, leverage int) (*types.APIResponse, error) {
+// UpdateLeverage sets the margin mode and leverage for a perp. Leverage is
+// checked against the asset's maximum, and cross margin is refused for
+// isolated-only assets.
+func (e *ExchangeClient) UpdateLeverage(ctx context.Context, coin string, mode types.MarginMode, leverage int) (*types.APIResponse, error) {
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	if asset.IsSpot {
+		return nil, fmt.Errorf("leverage does not apply to spot asset %s", coin)
+	}
+
+	switch mode {
+	case types.MarginCross:
+		if asset.OnlyIsolated {
+			return nil, fmt.Errorf("%s only supports isolated margin", coin)
+		}
+	case types.MarginIsolated:
+	default:
+		return nil, fmt.Errorf("unknown margin mode: %q", mode)
+	}
+
+	if leverage < 1 || leverage > asset.MaxLeverage {
+		return nil, fmt.Errorf("leverage for %s must be between 1 and %d, got %d", coin, asset.MaxLeverage, leverage)
+	}
+
 	action := map[string]interface{}{
 		"type":     "updateLeverage",
-		"asset":    asset,
-		"isCross":  leverageMode == "cross",
+		"asset":    asset.ID,
+		"isCross":  mode == types.MarginCross,
 		"leverage": leverage,
 	}
 
-	payload, err := e.createSignedRequest(action)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
-	}
-
-	resp, err := e.client.request(ctx, "/exchange", payload)
+	resp, err := e.postAction(ctx, action)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update leverage: %w", err)
 	}
@@ -200,21 +373,35 @@ func (e *ExchangeClient) UpdateLeverage(ctx context.Context, asset string, lever
 	return &apiResp, nil
 }
 
-// UpdateIsolatedMargin adds or removes isolated margin
-func (e *ExchangeClient) UpdateIsolatedMargin(ctx context.Context, asset string, amount float64) (*types.APIResponse, error) {
-	action := map[string]interface{}{
-		"type":     "updateIsolatedMargin",
-		"asset":    asset,
-		"isBuy":    amount > 0,
-		"ntli":     amount,
+// UpdateIsolatedMargin adds amount USDC to the isolated position in coin, or
+// removes it when amount is negative. Amounts carry at most 6 decimals.
+func (e *ExchangeClient) UpdateIsolatedMargin(ctx context.Context, coin string, amount decimal.Decimal) (*types.APIResponse, error) {
+	ntli := amount.Shift(types.UsdcDecimals)
+	if ntli.IsZero() || !ntli.IsInteger() {
+		return nil, fmt.Errorf("margin amount must be non-zero with at most %d decimals, got %s", types.UsdcDecimals, amount)
 	}
 
-	payload, err := e.createSignedRequest(action)
+	position, err := e.position(ctx, coin)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create signed request: %w", err)
+		return nil, err
+	}
+	if position.Leverage.Type != string(types.MarginIsolated) {
+		return nil, fmt.Errorf("position in %s is not isolated", coin)
 	}
 
-	resp, err := e.client.request(ctx, "/exchange", payload)
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	action := map[string]interface{}{
+		"type":  "updateIsolatedMargin",
+		"asset": asset.ID,
+		"isBuy": position.Szi.IsPositive(),
+		"ntli":  ntli.IntPart(),
+	}
+
+	resp, err := e.postAction(ctx, action)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update isolated margin: %w", err)
 	}
@@ -227,6 +414,32 @@ func (e *ExchangeClient) UpdateIsolatedMargin(ctx context.Context, asset string,
 	return &apiResp, nil
 }
 
+// TopUpIsolatedMargin adds enough margin to the isolated position in coin to
+// bring its effective leverage (position value / margin) down to
+// targetLeverage. It returns a nil response when no margin is needed.
+func (e *ExchangeClient) TopUpIsolatedMargin(ctx context.Context, coin string, targetLeverage decimal.Decimal) (*types.APIResponse, error) {
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	if targetLeverage.LessThan(decimal.NewFromInt(1)) || targetLeverage.GreaterThan(decimal.NewFromInt(int64(asset.MaxLeverage))) {
+		return nil, fmt.Errorf("target leverage for %s must be between 1 and %d, got %s", coin, asset.MaxLeverage, targetLeverage)
+	}
+
+	position, err := e.position(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	required := position.PositionValue.Div(targetLeverage)
+	delta := required.Sub(position.MarginUsed).RoundCeil(types.UsdcDecimals)
+	if !delta.IsPositive() {
+		return nil, nil
+	}
+
+	return e.UpdateIsolatedMargin(ctx, coin, delta)
+}
+
 // Transfer performs a USDC transfer
 func (e *ExchangeClient) Transfer(ctx context.Context, transfer types.TransferRequest) (*types.APIResponse, error) {
 	action := map[string]interface{}{
@@ -310,13 +523,589 @@ func (e *ExchangeClient) SetReferrer(ctx context.Context, code string) (*types.A
 	return &apiResp, nil
 }
 
+// MarketOpen buys or sells size at market by sending an IOC limit order
+// priced slippage (e.g. 0.05 for 5%) through the current mid, and returns
+// the fill summary
+func (e *ExchangeClient) MarketOpen(ctx context.Context, coin string, isBuy bool, size decimal.Decimal, slippage float64) (*types.FilledOrder, error) {
+	return e.marketOrder(ctx, coin, isBuy, size, slippage, false)
+}
+
+// MarketClose closes the full perp position in coin with a reduce-only IOC
+// order at DefaultSlippage and returns the fill summary
+func (e *ExchangeClient) MarketClose(ctx context.Context, coin string) (*types.FilledOrder, error) {
+	position, err := e.position(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	isBuy := position.Szi.IsNegative()
+	return e.marketOrder(ctx, coin, isBuy, position.Szi.Abs(), DefaultSlippage, true)
+}
+
+// position returns the open perp position in coin
+func (e *ExchangeClient) position(ctx context.Context, coin string) (*types.Position, error) {
+	if e.client.address == "" {
+		return nil, fmt.Errorf("address not set")
+	}
+
+	state, err := e.client.Info().GetUserState(ctx, e.client.address)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ap := range state.AssetPositions {
+		if ap.Position.Coin == coin && !ap.Position.Szi.IsZero() {
+			position := ap.Position
+			return &position, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no open position in %s", coin)
+}
+
+func (e *ExchangeClient) marketOrder(ctx context.Context, coin string, isBuy bool, size decimal.Decimal, slippage float64, reduceOnly bool) (*types.FilledOrder, error) {
+	if slippage < 0 || slippage >= 1 {
+		return nil, fmt.Errorf("slippage must be in [0, 1), got %g", slippage)
+	}
+
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	size = utils.RoundSize(size, asset.SzDecimals)
+	if !size.IsPositive() {
+		return nil, fmt.Errorf("order size rounds to zero at %d decimals", asset.SzDecimals)
+	}
+
+	px, err := e.slippagePrice(ctx, asset, isBuy, slippage)
+	if err != nil {
+		return nil, err
+	}
+
+	order := types.OrderRequest{
+		Asset:      coin,
+		IsBuy:      isBuy,
+		LimitPx:    px,
+		Sz:         size,
+		ReduceOnly: reduceOnly,
+		OrderType: types.OrderType{
+			Limit: &types.LimitOrderType{Tif: types.TifIoc},
+		},
+	}
+
+	resp, err := e.PlaceOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := resp.Response.Data.Statuses
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("no order status returned")
+	}
+	if statuses[0].Error != nil {
+		return nil, fmt.Errorf("market order rejected: %s", *statuses[0].Error)
+	}
+	if statuses[0].Filled == nil {
+		return nil, fmt.Errorf("market order was not filled")
+	}
+
+	return statuses[0].Filled, nil
+}
+
+// slippagePrice returns the mid moved slippage against the taker, rounded to
+// the asset's tick rules. The mid comes from allMids, falling back to the book.
+func (e *ExchangeClient) slippagePrice(ctx context.Context, asset *types.AssetInfo, isBuy bool, slippage float64) (decimal.Decimal, error) {
+	mid, err := e.midPrice(ctx, asset.Coin)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return slippageLimit(asset, isBuy, mid, slippage), nil
+}
+
+// slippageLimit moves px slippage against the taker and rounds it
+func slippageLimit(asset *types.AssetInfo, isBuy bool, px decimal.Decimal, slippage float64) decimal.Decimal {
+	factor := decimal.NewFromFloat(1 - slippage)
+	if isBuy {
+		factor = decimal.NewFromFloat(1 + slippage)
+	}
+	return utils.RoundPrice(px.Mul(factor), asset.SzDecimals, asset.IsSpot)
+}
+
+func (e *ExchangeClient) midPrice(ctx context.Context, coin string) (decimal.Decimal, error) {
+	mids, err := e.client.Info().GetAllMids(ctx)
+	if err == nil {
+		if raw, ok := mids[coin]; ok {
+			if mid, err := decimal.NewFromString(raw); err == nil {
+				return mid, nil
+			}
+		}
+	}
+
+	book, err := e.client.Info().GetL2Book(ctx, coin)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get mid for %s: %w", coin, err)
+	}
+	ob, err := book.OrderBook()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return decimal.Zero, fmt.Errorf("no mid price for %s", coin)
+	}
+
+	return ob.Bids[0].Price.Add(ob.Asks[0].Price).Div(decimal.NewFromInt(2)), nil
+}
+
+// PlaceBracket places an entry order together with reduce-only take-profit
+// and stop-loss triggers in one normalTpsl group. Either trigger price may be
+// nil. Triggers execute as market orders bounded by DefaultSlippage.
+func (e *ExchangeClient) PlaceBracket(ctx context.Context, entry types.OrderRequest, takeProfit, stopLoss *decimal.Decimal) (*types.OrderResponse, error) {
+	if takeProfit == nil && stopLoss == nil {
+		return nil, fmt.Errorf("bracket needs a take profit or a stop loss")
+	}
+	if err := validateTpsl(entry.IsBuy, entry.LimitPx, takeProfit, stopLoss); err != nil {
+		return nil, err
+	}
+
+	asset, err := e.client.Info().GetAssetInfo(ctx, entry.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []types.OrderRequest{entry}
+	orders = append(orders, tpslOrders(asset, !entry.IsBuy, entry.Sz, takeProfit, stopLoss)...)
+
+	return e.PlaceOrders(ctx, orders, types.GroupingNormalTpsl)
+}
+
+// SetPositionTpsl attaches take-profit and stop-loss triggers to the open
+// position in coin using positionTpsl grouping. Either price may be nil.
+func (e *ExchangeClient) SetPositionTpsl(ctx context.Context, coin string, takeProfit, stopLoss *decimal.Decimal) (*types.OrderResponse, error) {
+	if takeProfit == nil && stopLoss == nil {
+		return nil, fmt.Errorf("need a take profit or a stop loss")
+	}
+	position, err := e.position(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	isLong := position.Szi.IsPositive()
+	if err := validateTpsl(isLong, position.EntryPx, takeProfit, stopLoss); err != nil {
+		return nil, err
+	}
+
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := tpslOrders(asset, !isLong, position.Szi.Abs(), takeProfit, stopLoss)
+	return e.PlaceOrders(ctx, orders, types.GroupingPositionTpsl)
+}
+
+// validateTpsl checks that the take profit is beyond the entry in the
+// direction of the trade and the stop loss is behind it
+func validateTpsl(isBuy bool, entryPx decimal.Decimal, takeProfit, stopLoss *decimal.Decimal) error {
+	if !entryPx.IsPositive() {
+		return fmt.Errorf("entry price must be positive")
+	}
+
+	if takeProfit != nil {
+		if isBuy && !takeProfit.GreaterThan(entryPx) {
+			return fmt.Errorf("take profit %s must be above entry %s for a long", takeProfit, entryPx)
+		}
+		if !isBuy && !takeProfit.LessThan(entryPx) {
+			return fmt.Errorf("take profit %s must be below entry %s for a short", takeProfit, entryPx)
+		}
+	}
+
+	if stopLoss != nil {
+		if isBuy && !stopLoss.LessThan(entryPx) {
+			return fmt.Errorf("stop loss %s must be below entry %s for a long", stopLoss, entryPx)
+		}
+		if !isBuy && !stopLoss.GreaterThan(entryPx) {
+			return fmt.Errorf("stop loss %s must be above entry %s for a short", stopLoss, entryPx)
+		}
+	}
+
+	return nil
+}
+
+// tpslOrders builds reduce-only market triggers closing size on side isBuy
+func tpslOrders(asset *types.AssetInfo, isBuy bool, size decimal.Decimal, takeProfit, stopLoss *decimal.Decimal) []types.OrderRequest {
+	var orders []types.OrderRequest
+	legs := []struct {
+		px   *decimal.Decimal
+		kind string
+	}{
+		{takeProfit, types.TpSlTakeProfit},
+		{stopLoss, types.TpSlStopLoss},
+	}
+
+	for _, leg := range legs {
+		if leg.px == nil {
+			continue
+		}
+
+		triggerPx := utils.RoundPrice(*leg.px, asset.SzDecimals, asset.IsSpot)
+		orders = append(orders, types.OrderRequest{
+			Asset:      asset.Coin,
+			IsBuy:      isBuy,
+			LimitPx:    slippageLimit(asset, isBuy, triggerPx, DefaultSlippage),
+			Sz:         size,
+			ReduceOnly: true,
+			OrderType: types.OrderType{
+				Trigger: &types.TriggerOrderType{
+					TriggerPx: triggerPx,
+					IsMarket:  true,
+					TpSl:      leg.kind,
+				},
+			},
+		})
+	}
+
+	return orders
+}
+
+// ApproveBuilderFee approves builder to charge up to maxFee, in tenths of a
+// basis point, on orders placed for this account
+func (e *ExchangeClient) ApproveBuilderFee(ctx context.Context, builder string, maxFee int) (*types.APIResponse, error) {
+	if !utils.ValidateAddress(builder) {
+		return nil, fmt.Errorf("invalid builder address: %s", builder)
+	}
+	if maxFee < 0 || maxFee > types.MaxSpotBuilderFee {
+		return nil, fmt.Errorf("builder fee must be between 0 and %d, got %d", types.MaxSpotBuilderFee, maxFee)
+	}
+
+	builder = strings.ToLower(builder)
+	action := map[string]interface{}{
+		"type":       "approveBuilderFee",
+		"maxFeeRate": decimal.New(int64(maxFee), -3).String() + "%",
+		"builder":    builder,
+	}
+
+	resp, err := e.postUserAction(ctx, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve builder fee: %w", err)
+	}
+
+	var apiResp types.APIResponse
+	if err := json.Unmarshal(resp, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal approve builder fee response: %w", err)
+	}
+
+	e.client.builderMu.Lock()
+	if e.client.builderFees == nil {
+		e.client.builderFees = make(map[string]int)
+	}
+	e.client.builderFees[builder] = maxFee
+	e.client.builderMu.Unlock()
+
+	return &apiResp, nil
+}
+
+// checkBuilder validates a builder code against the orders it is attached to
+// and the fee the user approved, returning it with a lowercase address
+func (e *ExchangeClient) checkBuilder(ctx context.Context, orders []types.OrderRequest, builder types.BuilderInfo) (types.BuilderInfo, error) {
+	if !utils.ValidateAddress(builder.Builder) {
+		return builder, fmt.Errorf("invalid builder address: %s", builder.Builder)
+	}
+	if builder.Fee < 0 {
+		return builder, fmt.Errorf("builder fee must not be negative")
+	}
+	builder.Builder = strings.ToLower(builder.Builder)
+
+	for _, order := range orders {
+		asset, err := e.client.Info().GetAssetInfo(ctx, order.Asset)
+		if err != nil {
+			return builder, err
+		}
+		limit := types.MaxPerpBuilderFee
+		if asset.IsSpot {
+			limit = types.MaxSpotBuilderFee
+		}
+		if builder.Fee > limit {
+			return builder, fmt.Errorf("builder fee %d exceeds the %d limit for %s", builder.Fee, limit, order.Asset)
+		}
+	}
+
+	approved, err := e.approvedBuilderFee(ctx, builder.Builder)
+	if err != nil {
+		return builder, err
+	}
+	if builder.Fee > approved {
+		return builder, fmt.Errorf("builder fee %d exceeds approved maximum %d for %s", builder.Fee, approved, builder.Builder)
+	}
+
+	return builder, nil
+}
+
+// approvedBuilderFee returns the user's approved max fee for builder, cached
+// after the first lookup
+func (e *ExchangeClient) approvedBuilderFee(ctx context.Context, builder string) (int, error) {
+	e.client.builderMu.RLock()
+	approved, ok := e.client.builderFees[builder]
+	e.client.builderMu.RUnlock()
+	if ok {
+		return approved, nil
+	}
+
+	if e.client.address == "" {
+		return 0, fmt.Errorf("address not set")
+	}
+
+	approved, err := e.client.Info().GetMaxBuilderFee(ctx, e.client.address, builder)
+	if err != nil {
+		return 0, err
+	}
+
+	e.client.builderMu.Lock()
+	if e.client.builderFees == nil {
+		e.client.builderFees = make(map[string]int)
+	}
+	e.client.builderFees[builder] = approved
+	e.client.builderMu.Unlock()
+
+	return approved, nil
+}
+
+// TokenDelegate delegates amount HYPE from the staking balance to validator,
+// or undelegates it when isUndelegate is set
+func (e *ExchangeClient) TokenDelegate(ctx context.Context, validator string, amount decimal.Decimal, isUndelegate bool) (*types.APIResponse, error) {
+	if !utils.ValidateAddress(validator) {
+		return nil, fmt.Errorf("invalid validator address: %s", validator)
+	}
+
+	wei, err := utils.ToWei(amount, types.HypeWeiDecimals)
+	if err != nil {
+		return nil, err
+	}
+
+	action := map[string]interface{}{
+		"type":         "tokenDelegate",
+		"validator":    strings.ToLower(validator),
+		"wei":          wei,
+		"isUndelegate": isUndelegate,
+	}
+
+	return e.stakingAction(ctx, action, "delegate")
+}
+
+// CDeposit moves amount HYPE from the spot balance into the staking balance
+func (e *ExchangeClient) CDeposit(ctx context.Context, amount decimal.Decimal) (*types.APIResponse, error) {
+	wei, err := utils.ToWei(amount, types.HypeWeiDecimals)
+	if err != nil {
+		return nil, err
+	}
+
+	action := map[string]interface{}{
+		"type": "cDeposit",
+		"wei":  wei,
+	}
+
+	return e.stakingAction(ctx, action, "deposit to staking")
+}
+
+// CWithdraw moves amount HYPE from the staking balance back to spot, subject
+// to the unstaking queue
+func (e *ExchangeClient) CWithdraw(ctx context.Context, amount decimal.Decimal) (*types.APIResponse, error) {
+	wei, err := utils.ToWei(amount, types.HypeWeiDecimals)
+	if err != nil {
+		return nil, err
+	}
+
+	action := map[string]interface{}{
+		"type": "cWithdraw",
+		"wei":  wei,
+	}
+
+	return e.stakingAction(ctx, action, "withdraw from staking")
+}
+
+func (e *ExchangeClient) stakingAction(ctx context.Context, action map[string]interface{}, description string) (*types.APIResponse, error) {
+	resp, err := e.postUserAction(ctx, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", description, err)
+	}
+
+	var apiResp types.APIResponse
+	if err := json.Unmarshal(resp, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s response: %w", description, err)
+	}
+
+	return &apiResp, nil
+}
+
+// PlaceTwap places a TWAP order that works size over the given number of
+// minutes (5 to 1440), optionally randomizing slice timing
+func (e *ExchangeClient) PlaceTwap(ctx context.Context, coin string, isBuy bool, size decimal.Decimal, minutes int, randomize, reduceOnly bool) (*types.TwapResponse, error) {
+	if !size.IsPositive() {
+		return nil, fmt.Errorf("TWAP size must be positive")
+	}
+	if minutes < 5 || minutes > 1440 {
+		return nil, fmt.Errorf("TWAP duration must be between 5 and 1440 minutes, got %d", minutes)
+	}
+
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	action := map[string]interface{}{
+		"type": "twapOrder",
+		"twap": map[string]interface{}{
+			"a": asset.ID,
+			"b": isBuy,
+			"s": size.String(),
+			"r": reduceOnly,
+			"m": minutes,
+			"t": randomize,
+		},
+	}
+
+	resp, err := e.postAction(ctx, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place TWAP: %w", err)
+	}
+
+	var twapResp types.TwapResponse
+	if err := json.Unmarshal(resp, &twapResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TWAP response: %w", err)
+	}
+
+	if status := twapResp.Response.Data.Status; status.Error != nil {
+		return &twapResp, fmt.Errorf("TWAP rejected: %s", *status.Error)
+	}
+
+	return &twapResp, nil
+}
+
+// CancelTwap cancels a running TWAP order
+func (e *ExchangeClient) CancelTwap(ctx context.Context, coin string, twapID int64) (*types.TwapCancelResponse, error) {
+	asset, err := e.client.Info().GetAssetInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	action := map[string]interface{}{
+		"type": "twapCancel",
+		"a":    asset.ID,
+		"t":    twapID,
+	}
+
+	resp, err := e.postAction(ctx, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel TWAP: %w", err)
+	}
+
+	var cancelResp types.TwapCancelResponse
+	if err := json.Unmarshal(resp, &cancelResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TWAP cancel response: %w", err)
+	}
+
+	if status := cancelResp.Response.Data.Status; status.Error != nil {
+		return &cancelResp, fmt.Errorf("TWAP cancel rejected: %s", *status.Error)
+	}
+
+	return &cancelResp, nil
+}
+
+// ScheduleCancel schedules a cancel of all open orders at the given time,
+// which must be at least 5 seconds in the future. A nil time clears the
+// scheduled cancel.
+func (e *ExchangeClient) ScheduleCancel(ctx context.Context, at *time.Time) (*types.APIResponse, error) {
+	action := map[string]interface{}{
+		"type": "scheduleCancel",
+	}
+
+	if at != nil {
+		if time.Until(*at) < 5*time.Second {
+			return nil, fmt.Errorf("scheduled cancel time must be at least 5s in the future")
+		}
+		action["time"] = at.UnixMilli()
+	}
+
+	resp, err := e.postAction(ctx, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule cancel: %w", err)
+	}
+
+	var apiResp types.APIResponse
+	if err := json.Unmarshal(resp, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schedule cancel response: %w", err)
+	}
+
+	return &apiResp, nil
+}
+
+// postAction signs and submits an action, returning the raw response.
+// Requests rejected as a whole ({"status":"err"}) are returned as errors.
+func (e *ExchangeClient) postAction(ctx context.Context, action interface{}) ([]byte, error) {
+	payload, err := e.createSignedRequest(action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signed request: %w", err)
+	}
+
+	return e.submit(ctx, payload)
+}
+
+// submit posts a signed payload, turning an error envelope into an error
+func (e *ExchangeClient) submit(ctx context.Context, payload map[string]interface{}) ([]byte, error) {
+	resp, err := e.client.request(ctx, "/exchange", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope struct {
+		Status   string          `json:"status"`
+		Response json.RawMessage `json:"response"`
+	}
+	if err := json.Unmarshal(resp, &envelope); err == nil && envelope.Status == "err" {
+		var message string
+		if err := json.Unmarshal(envelope.Response, &message); err != nil {
+			message = string(envelope.Response)
+		}
+		return nil, fmt.Errorf("exchange error: %s", message)
+	}
+
+	return resp, nil
+}
+
+// postUserAction fills in the chain and nonce fields of a user-signed
+// action, which must match the request nonce, then signs and submits it
+func (e *ExchangeClient) postUserAction(ctx context.Context, action map[string]interface{}) ([]byte, error) {
+	// The signature chain is the wallet's: Arbitrum One on mainnet, Arbitrum
+	// Sepolia on testnet
+	chain, signatureChainId := "Testnet", "0x66eee"
+	if e.client.IsMainnet() {
+		chain, signatureChainId = "Mainnet", "0xa4b1"
+	}
+
+	nonce := e.client.nextNonce()
+	action["hyperliquidChain"] = chain
+	action["signatureChainId"] = signatureChainId
+	action["nonce"] = nonce
+
+	payload, err := e.createSignedRequestWithNonce(action, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signed request: %w", err)
+	}
+
+	return e.submit(ctx, payload)
+}
+
 // createSignedRequest creates a signed request payload
 func (e *ExchangeClient) createSignedRequest(action interface{}) (map[string]interface{}, error) {
+	return e.createSignedRequestWithNonce(action, e.client.nextNonce())
+}
+
+func (e *ExchangeClient) createSignedRequestWithNonce(action interface{}, nonce int64) (map[string]interface{}, error) {
 	if e.client.privateKey == "" {
 		return nil, fmt.Errorf("private key not set")
 	}
 
-	nonce := time.Now().UnixMilli()
 	
 	// Create the signature
 	signature, err := utils.SignAction(action, e.client.privateKey, nonce)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
 	"github.com/shopspring/decimal"
 )
 
@@ -72,11 +74,28 @@ func (i *InfoClient) GetAllMids(ctx context.Context) (map[string]string, error)
 
 // GetL2Book retrieves the level 2 order book for an asset
 func (i *InfoClient) GetL2Book(ctx context.Context, coin string) (*types.L2Book, error) {
+	return i.GetL2BookAggregated(ctx, coin, nil, nil)
+}
+
+// GetL2BookAggregated retrieves the level 2 order book with price levels
+// aggregated to nSigFigs significant figures and an optional mantissa
+func (i *InfoClient) GetL2BookAggregated(ctx context.Context, coin string, nSigFigs, mantissa *int) (*types.L2Book, error) {
+	if err := utils.ValidateL2Aggregation(nSigFigs, mantissa); err != nil {
+		return nil, fmt.Errorf("invalid L2 aggregation: %w", err)
+	}
+
 	payload := map[string]interface{}{
 		"type": "l2Book",
 		"coin": coin,
 	}
 
+	if nSigFigs != nil {
+		payload["nSigFigs"] = *nSigFigs
+	}
+	if mantissa != nil {
+		payload["mantissa"] = *mantissa
+	}
+
 	resp, err := i.client.request(ctx, "/info", payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get L2 book: %w", err)
@@ -115,7 +134,8 @@ func (i *InfoClient) GetCandles(ctx context.Context, coin, interval string, star
 	return candles, nil
 }
 
-// GetUserFills retrieves user's trade fills
+// GetUserFills retrieves user's trade fills. Without a start time the most
+// recent fills are returned; with one, fills are queried by time range.
 func (i *InfoClient) GetUserFills(ctx context.Context, user string, startTime, endTime *int64) ([]types.Fill, error) {
 	payload := map[string]interface{}{
 		"type": "userFills",
@@ -123,6 +143,7 @@ func (i *InfoClient) GetUserFills(ctx context.Context, user string, startTime, e
 	}
 
 	if startTime != nil {
+		payload["type"] = "userFillsByTime"
 		payload["startTime"] = *startTime
 	}
 	if endTime != nil {
@@ -335,4 +356,221 @@ func (i *InfoClient) GetTradeVolume(ctx context.Context, window string) (map[str
 	}
 
 	return volume, nil
-}
\ No newline at end of file
+}
+
+// RefreshAssets reloads perp and spot metadata used to resolve coin names
+// to asset IDs and size precision
+func (i *InfoClient) RefreshAssets(ctx context.Context) error {
+	meta, err := i.GetMeta(ctx)
+	if err != nil {
+		return err
+	}
+
+	spotMeta, err := i.GetSpotMeta(ctx)
+	if err != nil {
+		return err
+	}
+
+	assets := make(map[string]types.AssetInfo, len(meta.Universe)+2*len(spotMeta.Universe))
+	for idx, asset := range meta.Universe {
+		assets[asset.Name] = types.AssetInfo{
+			ID:           idx,
+			Coin:         asset.Name,
+			SzDecimals:   asset.SzDecimals,
+			MaxLeverage:  asset.MaxLeverage,
+			OnlyIsolated: asset.OnlyIsolated,
+		}
+	}
+
+	for _, pair := range spotMeta.Universe {
+		info := types.AssetInfo{
+			ID:         10000 + pair.Index,
+			Coin:       pair.Name,
+			SzDecimals: pair.SzDecimals,
+			IsSpot:     true,
+		}
+		// Size precision comes from the base token
+		if len(pair.Tokens) > 0 && pair.Tokens[0] < len(spotMeta.Tokens) {
+			info.SzDecimals = spotMeta.Tokens[pair.Tokens[0]].SzDecimals
+		}
+
+		// Spot pairs are addressed by name or by "@index"
+		assets[fmt.Sprintf("@%d", pair.Index)] = info
+		if _, exists := assets[pair.Name]; !exists {
+			assets[pair.Name] = info
+		}
+	}
+
+	i.client.assetsMu.Lock()
+	i.client.assets = assets
+	i.client.assetsMu.Unlock()
+
+	return nil
+}
+
+// GetAssetInfo resolves a coin name to its asset ID and trading metadata,
+// loading metadata on first use
+func (i *InfoClient) GetAssetInfo(ctx context.Context, coin string) (*types.AssetInfo, error) {
+	i.client.assetsMu.RLock()
+	loaded := i.client.assets != nil
+	info, ok := i.client.assets[coin]
+	i.client.assetsMu.RUnlock()
+
+	if !loaded {
+		if err := i.RefreshAssets(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load asset metadata: %w", err)
+		}
+
+		i.client.assetsMu.RLock()
+		info, ok = i.client.assets[coin]
+		i.client.assetsMu.RUnlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown asset: %s", coin)
+	}
+
+	return &info, nil
+}
+
+// GetUserTwapSliceFills retrieves fills produced by the user's TWAP orders
+func (i *InfoClient) GetUserTwapSliceFills(ctx context.Context, user string) ([]types.TwapSliceFill, error) {
+	payload := map[string]interface{}{
+		"type": "userTwapSliceFills",
+		"user": user,
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TWAP slice fills: %w", err)
+	}
+
+	var fills []types.TwapSliceFill
+	if err := json.Unmarshal(resp, &fills); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TWAP slice fills: %w", err)
+	}
+
+	return fills, nil
+}
+
+// GetTwapHistory retrieves the status history of the user's TWAP orders
+func (i *InfoClient) GetTwapHistory(ctx context.Context, user string) ([]types.TwapHistoryEntry, error) {
+	payload := map[string]interface{}{
+		"type": "twapHistory",
+		"user": user,
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TWAP history: %w", err)
+	}
+
+	var history []types.TwapHistoryEntry
+	if err := json.Unmarshal(resp, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TWAP history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetMaxBuilderFee retrieves the maximum fee, in tenths of a basis point,
+// that user has approved for builder
+func (i *InfoClient) GetMaxBuilderFee(ctx context.Context, user, builder string) (int, error) {
+	payload := map[string]interface{}{
+		"type":    "maxBuilderFee",
+		"user":    user,
+		"builder": strings.ToLower(builder),
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get max builder fee: %w", err)
+	}
+
+	var fee int
+	if err := json.Unmarshal(resp, &fee); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal max builder fee: %w", err)
+	}
+
+	return fee, nil
+}
+
+// GetDelegatorSummary retrieves a user's staking totals
+func (i *InfoClient) GetDelegatorSummary(ctx context.Context, user string) (*types.DelegatorSummary, error) {
+	payload := map[string]interface{}{
+		"type": "delegatorSummary",
+		"user": user,
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegator summary: %w", err)
+	}
+
+	var summary types.DelegatorSummary
+	if err := json.Unmarshal(resp, &summary); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delegator summary: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// GetDelegations retrieves a user's delegations per validator
+func (i *InfoClient) GetDelegations(ctx context.Context, user string) ([]types.Delegation, error) {
+	payload := map[string]interface{}{
+		"type": "delegations",
+		"user": user,
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegations: %w", err)
+	}
+
+	var delegations []types.Delegation
+	if err := json.Unmarshal(resp, &delegations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delegations: %w", err)
+	}
+
+	return delegations, nil
+}
+
+// GetDelegatorRewards retrieves a user's staking rewards
+func (i *InfoClient) GetDelegatorRewards(ctx context.Context, user string) ([]types.DelegatorReward, error) {
+	payload := map[string]interface{}{
+		"type": "delegatorRewards",
+		"user": user,
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegator rewards: %w", err)
+	}
+
+	var rewards []types.DelegatorReward
+	if err := json.Unmarshal(resp, &rewards); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delegator rewards: %w", err)
+	}
+
+	return rewards, nil
+}
+
+// GetDelegatorHistory retrieves a user's staking events
+func (i *InfoClient) GetDelegatorHistory(ctx context.Context, user string) ([]types.DelegatorHistoryEntry, error) {
+	payload := map[string]interface{}{
+		"type": "delegatorHistory",
+		"user": user,
+	}
+
+	resp, err := i.client.request(ctx, "/info", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegator history: %w", err)
+	}
+
+	var history []types.DelegatorHistoryEntry
+	if err := json.Unmarshal(resp, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delegator history: %w", err)
+	}
+
+	return history, nil
+}
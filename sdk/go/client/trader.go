@@ -0,0 +1,20 @@
+package client
+
+import (
+	"context"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// Trader is the order entry surface shared by ExchangeClient and anything
+// standing in for it, such as a risk wrapper or a simulated exchange.
+// Strategies written against Trader run unchanged on any of them.
+type Trader interface {
+	PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error)
+	PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error)
+	BatchModify(ctx context.Context, modifies []types.ModifyRequest) (*types.OrderResponse, error)
+	CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error)
+	CancelAll(ctx context.Context, filter types.CancelFilter) ([]types.CancelResult, error)
+}
+
+var _ Trader = (*ExchangeClient)(nil)
@@ -0,0 +1,459 @@
+// Package grid is a grid trading strategy for the strategy runtime.
+package grid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/strategy"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// Grid spacings
+const (
+	Arithmetic = "arithmetic" // Equal price steps
+	Geometric  = "geometric"  // Equal percentage steps
+)
+
+// retryTimer is the runtime timer that retries unplaced counters
+const retryTimer = "grid.retry"
+
+// Config configures a Grid
+type Config struct {
+	Asset         types.AssetInfo      // Perp or spot market; resolve with InfoClient.GetAssetInfo
+	Lower         decimal.Decimal      // Lowest grid price
+	Upper         decimal.Decimal      // Highest grid price
+	Levels        int                  // Number of grid prices, at least 2
+	Spacing       string               // Arithmetic or Geometric (default Arithmetic)
+	Size          decimal.Decimal      // Base size of each order
+	MinNotional   decimal.Decimal      // Smallest counter placed; smaller fills accumulate until they reach it (default 10)
+	RetryInterval time.Duration        // How often counters that failed to place are retried (default 10s)
+	StatePath     string               // File the state is saved to after every change and resumed from; none when empty
+	Fills         websocket.FillSource // Optional REST source for fills missed while a resumed grid was down
+}
+
+// Grid places a buy at every grid price below the market and a sell at
+// every one above, leaving the nearest empty. Each fill, partial or not, is
+// answered with an opposing order of the same size one level away, which
+// closes a round trip at a profit of one grid step when it fills. Orders
+// go out in PlaceOrders batches and are triggered by the userFills stream.
+//
+// Selling on a spot pair needs the base asset in the account; on a perp
+// the initial sells open a short. With a StatePath the grid resumes where
+// it left off: OnStop pulls the orders and keeps their size to place again
+// on the next start, and after a crash the saved orders are assumed to
+// still rest, with fills missed meanwhile recovered from Fills.
+//
+// It is a strategy.Strategy: run it with strategy.NewRuntime for live or
+// paper trading. Callbacks must not be called concurrently, which the
+// runtime guarantees; read State from a callback or once the runtime stops.
+type Grid struct {
+	strategy.Base
+	config Config
+	prices []decimal.Decimal
+	rt     *strategy.Runtime
+	state  State
+}
+
+var _ strategy.Strategy = (*Grid)(nil)
+
+// NewGrid creates a grid for config.Asset
+func NewGrid(config Config) (*Grid, error) {
+	if config.Spacing == "" {
+		config.Spacing = Arithmetic
+	}
+	if !config.MinNotional.IsPositive() {
+		config.MinNotional = decimal.NewFromInt(10)
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 10 * time.Second
+	}
+
+	prices, err := Prices(config)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Size.Truncate(int32(config.Asset.SzDecimals)).IsPositive() {
+		return nil, fmt.Errorf("grid size must be positive at %d decimals", config.Asset.SzDecimals)
+	}
+
+	return &Grid{
+		config: config,
+		prices: prices,
+		state:  State{Prices: prices},
+	}, nil
+}
+
+// Prices returns the grid prices from Lower to Upper, rounded to the
+// asset's tick size
+func Prices(config Config) ([]decimal.Decimal, error) {
+	if config.Levels < 2 {
+		return nil, fmt.Errorf("grid needs at least 2 levels, got %d", config.Levels)
+	}
+	if !config.Lower.IsPositive() || !config.Upper.GreaterThan(config.Lower) {
+		return nil, fmt.Errorf("grid bounds must satisfy 0 < lower < upper, got %s and %s", config.Lower, config.Upper)
+	}
+
+	steps := int64(config.Levels - 1)
+	prices := make([]decimal.Decimal, config.Levels)
+	for i := range prices {
+		switch config.Spacing {
+		case Arithmetic, "":
+			step := config.Upper.Sub(config.Lower).Div(decimal.NewFromInt(steps))
+			prices[i] = config.Lower.Add(step.Mul(decimal.NewFromInt(int64(i))))
+		case Geometric:
+			ratio, _ := config.Upper.Div(config.Lower).Float64()
+			prices[i] = config.Lower.Mul(decimal.NewFromFloat(math.Pow(ratio, float64(i)/float64(steps))))
+		default:
+			return nil, fmt.Errorf("unknown grid spacing %q", config.Spacing)
+		}
+	}
+	prices[len(prices)-1] = config.Upper
+
+	for i := range prices {
+		prices[i] = utils.RoundPrice(prices[i], config.Asset.SzDecimals, config.Asset.IsSpot)
+		if i > 0 && !prices[i].GreaterThan(prices[i-1]) {
+			return nil, fmt.Errorf("grid prices %s and %s collapse at the tick size; use fewer levels or wider bounds", prices[i-1], prices[i])
+		}
+	}
+	return prices, nil
+}
+
+// Prices returns the grid prices, lowest first
+func (g *Grid) Prices() []decimal.Decimal {
+	return append([]decimal.Decimal(nil), g.prices...)
+}
+
+// State returns a copy of the grid's orders, pending counters and profit
+func (g *Grid) State() State {
+	state := g.state
+	state.Prices = append([]decimal.Decimal(nil), state.Prices...)
+	state.Orders = append([]Order(nil), state.Orders...)
+	state.Pending = append([]Counter(nil), state.Pending...)
+	return state
+}
+
+// OnStart resumes a saved grid, catching up on fills missed while it was
+// down. A new grid is placed on the first book.
+func (g *Grid) OnStart(ctx context.Context, rt *strategy.Runtime) error {
+	g.rt = rt
+	rt.SetTimer(retryTimer, g.config.RetryInterval)
+	if g.config.StatePath == "" {
+		return nil
+	}
+
+	saved, err := LoadState(g.config.StatePath)
+	if err != nil || saved == nil {
+		return err
+	}
+	if !samePrices(saved.Prices, g.prices) {
+		return fmt.Errorf("grid state in %s was saved with different prices; remove it to start over", g.config.StatePath)
+	}
+	g.state = *saved
+
+	if g.config.Fills != nil && len(g.state.Orders) > 0 {
+		if err := g.catchUp(ctx); err != nil {
+			return err
+		}
+	}
+	return g.flush(ctx)
+}
+
+// samePrices reports whether a and b hold the same prices
+func samePrices(a, b []decimal.Decimal) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// catchUp applies fills of saved orders that happened while the grid was down
+func (g *Grid) catchUp(ctx context.Context) error {
+	start := g.state.Orders[0].Created
+	for _, o := range g.state.Orders {
+		if o.Created < start {
+			start = o.Created
+		}
+	}
+
+	fills, err := g.config.Fills.GetUserFills(ctx, g.rt.User(), &start, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch missed fills: %w", err)
+	}
+	sort.Slice(fills, func(i, j int) bool {
+		if fills[i].Time != fills[j].Time {
+			return fills[i].Time < fills[j].Time
+		}
+		return fills[i].Tid < fills[j].Tid
+	})
+	for _, f := range fills {
+		if f.Coin == g.config.Asset.Coin {
+			g.apply(types.UserFillData{
+				Coin: f.Coin, Px: f.Px, Sz: f.Sz, Side: f.Side, Time: f.Time,
+				Oid: f.Oid, Cloid: f.Cloid, Fee: f.Fee, Tid: f.Tid,
+			})
+		}
+	}
+	return nil
+}
+
+// OnBook places the initial grid around the first mid
+func (g *Grid) OnBook(ctx context.Context, book types.L2BookData) error {
+	if book.Coin != g.config.Asset.Coin || g.state.Anchor.IsPositive() {
+		return nil
+	}
+	bids, asks, err := types.ParseL2Levels(book.Levels)
+	if err != nil {
+		return fmt.Errorf("failed to parse book: %w", err)
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return nil
+	}
+	mid := bids[0].Price.Add(asks[0].Price).Div(decimal.NewFromInt(2))
+
+	// The level nearest the market stays empty, so every fill has an empty
+	// level next to it for its counter
+	nearest := 0
+	for i, px := range g.prices {
+		if px.Sub(mid).Abs().LessThan(g.prices[nearest].Sub(mid).Abs()) {
+			nearest = i
+		}
+	}
+	size := g.config.Size.Truncate(int32(g.config.Asset.SzDecimals))
+	for i := range g.prices {
+		if i != nearest {
+			g.addPending(Counter{Level: i, IsBuy: i < nearest, Sz: size})
+		}
+	}
+	g.state.Anchor = mid
+	return g.flush(ctx)
+}
+
+// OnFill answers a grid order's fill with an opposing order one level away
+func (g *Grid) OnFill(ctx context.Context, fill types.UserFillData) error {
+	if fill.Coin != g.config.Asset.Coin || !g.apply(fill) {
+		return nil
+	}
+	return g.flush(ctx)
+}
+
+// apply books a fill against its grid order and queues the counter. It
+// reports false for fills of other orders and fills already applied.
+func (g *Grid) apply(fill types.UserFillData) bool {
+	i := g.find(fill.Oid, fill.Cloid)
+	if i < 0 {
+		return false
+	}
+	o := &g.state.Orders[i]
+	for _, tid := range o.Tids {
+		if tid == fill.Tid {
+			return false
+		}
+	}
+	o.Tids = append(o.Tids, fill.Tid)
+	o.Filled = o.Filled.Add(fill.Sz)
+
+	g.state.Fees = g.state.Fees.Add(fill.Fee)
+	if !o.Entry.IsZero() {
+		pnl := fill.Px.Sub(o.Entry).Mul(fill.Sz)
+		if o.IsBuy {
+			pnl = pnl.Neg()
+		}
+		g.state.Profit = g.state.Profit.Add(pnl)
+	}
+
+	// Fills at the edges have no level beyond them to close at
+	counter := o.Level - 1
+	if o.IsBuy {
+		counter = o.Level + 1
+	}
+	if counter >= 0 && counter < len(g.prices) {
+		g.addPending(Counter{Level: counter, IsBuy: !o.IsBuy, Sz: fill.Sz, Entry: fill.Px})
+	}
+
+	if !o.Remaining().IsPositive() {
+		if !o.Entry.IsZero() {
+			g.state.RoundTrips++
+		}
+		g.state.Orders = append(g.state.Orders[:i], g.state.Orders[i+1:]...)
+	}
+	return true
+}
+
+// find returns the index of the grid order with oid or cloid, or -1
+func (g *Grid) find(oid int64, cloid *string) int {
+	for i, o := range g.state.Orders {
+		if cloid != nil && o.Cloid == *cloid || oid != 0 && o.Oid == oid {
+			return i
+		}
+	}
+	return -1
+}
+
+// addPending queues size at a level, merging it with size already waiting
+// there that closes the same kind of position
+func (g *Grid) addPending(c Counter) {
+	for i := range g.state.Pending {
+		p := &g.state.Pending[i]
+		if p.Level != c.Level || p.IsBuy != c.IsBuy || p.Entry.IsZero() != c.Entry.IsZero() {
+			continue
+		}
+		sz := p.Sz.Add(c.Sz)
+		if !c.Entry.IsZero() {
+			p.Entry = p.Entry.Mul(p.Sz).Add(c.Entry.Mul(c.Sz)).Div(sz)
+		}
+		p.Sz = sz
+		return
+	}
+	g.state.Pending = append(g.state.Pending, c)
+}
+
+// OnOrderUpdate forgets grid orders canceled or rejected by the exchange
+func (g *Grid) OnOrderUpdate(ctx context.Context, event orders.Event) error {
+	if !event.Order.State.Terminal() || event.Order.State == orders.StateFilled {
+		return nil
+	}
+	cloid := event.Order.Cloid
+	i := g.find(event.Order.Oid, &cloid)
+	if i < 0 {
+		return nil
+	}
+	g.state.Orders = append(g.state.Orders[:i], g.state.Orders[i+1:]...)
+	return g.save()
+}
+
+// OnTimer retries counters that failed to place
+func (g *Grid) OnTimer(ctx context.Context, name string, now time.Time) error {
+	if name != retryTimer || len(g.state.Pending) == 0 {
+		return nil
+	}
+	return g.flush(ctx)
+}
+
+// OnStop pulls the grid orders and keeps their unfilled size pending, so
+// a restart places them again
+func (g *Grid) OnStop(ctx context.Context) error {
+	if len(g.state.Orders) == 0 {
+		return g.save()
+	}
+
+	cloids := make([]string, 0, len(g.state.Orders))
+	for _, o := range g.state.Orders {
+		cloids = append(cloids, o.Cloid)
+		if remaining := o.Remaining(); remaining.IsPositive() {
+			g.addPending(Counter{Level: o.Level, IsBuy: o.IsBuy, Sz: remaining, Entry: o.Entry})
+		}
+	}
+	g.state.Orders = nil
+
+	var errs []error
+	if _, err := g.rt.Trader().CancelByCloid(ctx, g.config.Asset.Coin, cloids...); err != nil {
+		errs = append(errs, fmt.Errorf("failed to cancel grid orders: %w", err))
+	}
+	if err := g.save(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// flush places every pending counter worth at least MinNotional in one
+// batch and saves the state. Counters the exchange rejects stay pending.
+func (g *Grid) flush(ctx context.Context) error {
+	var (
+		requests []types.OrderRequest
+		placed   []Counter
+		kept     []Counter
+	)
+	for _, c := range g.state.Pending {
+		if c.Sz.Mul(g.prices[c.Level]).LessThan(g.config.MinNotional) {
+			kept = append(kept, c)
+			continue
+		}
+		order, err := g.order(c)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, order)
+		placed = append(placed, c)
+	}
+	if len(requests) == 0 {
+		return g.save()
+	}
+	g.state.Pending = kept
+
+	resp, err := g.rt.Trader().PlaceOrders(ctx, requests, types.GroupingNA)
+	// Only a failure after sending, such as a timeout, leaves the orders
+	// possibly resting; anything earlier, like a risk violation, placed none
+	unsent := err != nil && !errors.As(err, new(*client.SendError))
+	var statuses []types.OrderStatus
+	if resp != nil {
+		statuses = resp.Response.Data.Statuses
+	}
+	created := g.rt.Now().UnixMilli()
+	for i, c := range placed {
+		o := Order{
+			Cloid:   *requests[i].Cloid,
+			Level:   c.Level,
+			IsBuy:   c.IsBuy,
+			Px:      requests[i].LimitPx,
+			Sz:      requests[i].Sz,
+			Entry:   c.Entry,
+			Created: created,
+		}
+		switch {
+		case unsent:
+			g.addPending(c)
+			continue
+		case err != nil || i >= len(statuses):
+			// The order may have reached the exchange, so track it
+		case statuses[i].Resting != nil:
+			o.Oid = statuses[i].Resting.Oid
+		case statuses[i].Filled != nil:
+			o.Oid = statuses[i].Filled.Oid
+		default:
+			g.addPending(c)
+			continue
+		}
+		g.state.Orders = append(g.state.Orders, o)
+	}
+
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to place grid orders: %w", err), g.save())
+	}
+	return g.save()
+}
+
+// order builds a limit order for c with a fresh cloid
+func (g *Grid) order(c Counter) (types.OrderRequest, error) {
+	b := client.Sell(g.config.Asset.Coin)
+	if c.IsBuy {
+		b = client.Buy(g.config.Asset.Coin)
+	}
+	order, err := b.Size(c.Sz).Limit(g.prices[c.Level]).WithCloid().Build(&g.config.Asset)
+	if err != nil {
+		return types.OrderRequest{}, fmt.Errorf("failed to build grid order: %w", err)
+	}
+	return order, nil
+}
+
+// save writes the state to StatePath, if set
+func (g *Grid) save() error {
+	if g.config.StatePath == "" {
+		return nil
+	}
+	return g.state.Save(g.config.StatePath)
+}
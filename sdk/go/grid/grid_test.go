@@ -0,0 +1,270 @@
+package grid
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/risk"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/strategy"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+)
+
+const testKey = "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestPrices(t *testing.T) {
+	btc := types.AssetInfo{Coin: "BTC", SzDecimals: 5}
+
+	tests := []struct {
+		name    string
+		config  Config
+		want    []string
+		wantErr bool
+	}{
+		{"arithmetic", Config{Lower: hltest.Dec("100"), Upper: hltest.Dec("200"), Levels: 5}, []string{"100", "125", "150", "175", "200"}, false},
+		{"geometric", Config{Lower: hltest.Dec("100"), Upper: hltest.Dec("400"), Levels: 3, Spacing: Geometric}, []string{"100", "200", "400"}, false},
+		{"collapsed by the tick", Config{Asset: btc, Lower: hltest.Dec("50000"), Upper: hltest.Dec("50001"), Levels: 3}, nil, true},
+		{"one level", Config{Lower: hltest.Dec("100"), Upper: hltest.Dec("200"), Levels: 1}, nil, true},
+		{"inverted bounds", Config{Lower: hltest.Dec("200"), Upper: hltest.Dec("100"), Levels: 3}, nil, true},
+		{"unknown spacing", Config{Lower: hltest.Dec("100"), Upper: hltest.Dec("200"), Levels: 3, Spacing: "log"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices, err := Prices(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", prices)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prices failed: %v", err)
+			}
+			if len(prices) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, prices)
+			}
+			for i, px := range prices {
+				if !px.Equal(hltest.Dec(tt.want[i])) {
+					t.Errorf("Expected %v, got %v", tt.want, prices)
+					break
+				}
+			}
+		})
+	}
+}
+
+// start runs g until the returned stop is called
+func start(t *testing.T, srv *hltest.Server, c *client.Client, g *Grid) (stop func()) {
+	t.Helper()
+
+	ws := websocket.NewManager(srv.WSURL)
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	rt := strategy.NewRuntime(strategy.Live(c), ws, strategy.Config{User: c.GetAddress(), Coins: []string{"BTC"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- rt.Run(ctx, g) }()
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Expected a clean stop on cancel, got %v", err)
+		}
+		ws.Disconnect()
+	}
+}
+
+func TestGridAgainstMock(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+	engine := srv.Engine()
+	engine.Place("0xmaker", hltest.Limit("BTC", true, "47000", "5", types.TifGtc))
+	engine.Place("0xmaker", hltest.Limit("BTC", false, "53000", "5", types.TifGtc))
+
+	address, _ := utils.GetAddressFromPrivateKey(testKey)
+	c := client.NewClient(srv.URL, srv.WSURL, testKey)
+	c.SetAddress(address)
+	asset, err := c.Info().GetAssetInfo(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("Failed to resolve BTC: %v", err)
+	}
+
+	config := Config{
+		Asset:     *asset,
+		Lower:     hltest.Dec("48000"),
+		Upper:     hltest.Dec("52000"),
+		Levels:    5,
+		Size:      hltest.Dec("0.01"),
+		StatePath: filepath.Join(t.TempDir(), "grid.json"),
+	}
+	g, err := NewGrid(config)
+	if err != nil {
+		t.Fatalf("NewGrid failed: %v", err)
+	}
+	stop := start(t, srv, c, g)
+
+	// Buys below the 50000 mid and sells above it, with 50000 left empty
+	srv.WaitForOrders(t, address, []string{"B48000/0.01", "B49000/0.01", "A51000/0.01", "A52000/0.01"})
+
+	// A partial fill is answered one level up at once
+	engine.Place("0xtaker", hltest.Limit("BTC", false, "49000", "0.004", types.TifIoc))
+	srv.WaitForOrders(t, address, []string{"B48000/0.01", "B49000/0.006", "A50000/0.004", "A51000/0.01", "A52000/0.01"})
+
+	// Filling the counter closes the round trip, and its size goes back down a level
+	engine.Place("0xtaker", hltest.Limit("BTC", true, "50000", "0.004", types.TifIoc))
+	srv.WaitForOrders(t, address, []string{"B48000/0.01", "B49000/0.006", "B49000/0.004", "A51000/0.01", "A52000/0.01"})
+
+	stop()
+	if open := engine.OpenOrders(address); len(open) != 0 {
+		t.Errorf("Expected grid orders pulled on stop, got %+v", open)
+	}
+	state := g.State()
+	if !state.Profit.Equal(hltest.Dec("4")) || state.RoundTrips != 1 {
+		t.Errorf("Expected a profit of 4 from one round trip, got %s from %d", state.Profit, state.RoundTrips)
+	}
+	if len(state.Orders) != 0 || len(state.Pending) != 5 {
+		t.Errorf("Expected every order kept pending, got %+v and %+v", state.Orders, state.Pending)
+	}
+
+	// A restart resumes the saved grid rather than placing a new one
+	resumed, err := NewGrid(config)
+	if err != nil {
+		t.Fatalf("NewGrid failed: %v", err)
+	}
+	stop = start(t, srv, c, resumed)
+	srv.WaitForOrders(t, address, []string{"B48000/0.01", "B49000/0.006", "B49000/0.004", "A51000/0.01", "A52000/0.01"})
+	stop()
+	if profit := resumed.State().Profit; !profit.Equal(hltest.Dec("4")) {
+		t.Errorf("Expected the profit restored, got %s", profit)
+	}
+
+	config.Levels = 4
+	moved, err := NewGrid(config)
+	if err != nil {
+		t.Fatalf("NewGrid failed: %v", err)
+	}
+	rt := strategy.NewRuntime(strategy.Live(c), nil, strategy.Config{User: address})
+	if err := moved.OnStart(context.Background(), rt); err == nil {
+		t.Errorf("Expected a state saved for other prices to be refused")
+	}
+}
+
+func TestRejectedOrdersStayPending(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+
+	address, _ := utils.GetAddressFromPrivateKey(testKey)
+	c := client.NewClient(srv.URL, srv.WSURL, testKey)
+	c.SetAddress(address)
+	asset, err := c.Info().GetAssetInfo(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("Failed to resolve BTC: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "grid.json")
+	g, err := NewGrid(Config{
+		Asset:     *asset,
+		Lower:     hltest.Dec("48000"),
+		Upper:     hltest.Dec("52000"),
+		Levels:    5,
+		Size:      hltest.Dec("0.01"),
+		StatePath: path,
+	})
+	if err != nil {
+		t.Fatalf("NewGrid failed: %v", err)
+	}
+
+	// The guard refuses every grid order before it is sent
+	limits := &risk.Limits{MaxOrderNotional: hltest.Dec("100")}
+	rt := strategy.NewRuntime(strategy.Live(c), nil, strategy.Config{User: address, Limits: limits})
+	if err := g.OnStart(context.Background(), rt); err != nil {
+		t.Fatalf("OnStart failed: %v", err)
+	}
+
+	book := types.L2BookData{Coin: "BTC", Levels: [][]interface{}{
+		{map[string]interface{}{"px": "49999", "sz": "1", "n": 1}},
+		{map[string]interface{}{"px": "50001", "sz": "1", "n": 1}},
+	}}
+	var violation *risk.Violation
+	if err := g.OnBook(context.Background(), book); !errors.As(err, &violation) {
+		t.Fatalf("Expected the guard's violation, got %v", err)
+	}
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Orders) != 0 || len(state.Pending) != 4 {
+		t.Errorf("Expected every counter kept pending for a retry, got %+v and %+v", state.Orders, state.Pending)
+	}
+	if open := srv.Engine().OpenOrders(address); len(open) != 0 {
+		t.Errorf("Expected nothing sent, got %+v", open)
+	}
+}
+
+// fills is a FillSource answering with fixed fills
+type fills []types.Fill
+
+func (f fills) GetUserFills(ctx context.Context, user string, startTime, endTime *int64) ([]types.Fill, error) {
+	return f, nil
+}
+
+func TestResumeCatchesUpOnFills(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grid.json")
+	cloid := "0x00000000000000000000000000000001"
+	config := Config{
+		Asset:       types.AssetInfo{Coin: "BTC", SzDecimals: 5},
+		Lower:       hltest.Dec("48000"),
+		Upper:       hltest.Dec("52000"),
+		Levels:      5,
+		Size:        hltest.Dec("0.01"),
+		MinNotional: hltest.Dec("1000"), // Keep the counter pending
+		StatePath:   path,
+	}
+	prices, _ := Prices(config)
+	saved := State{
+		Prices: prices,
+		Anchor: hltest.Dec("50000"),
+		Orders: []Order{{Cloid: cloid, Oid: 7, Level: 1, IsBuy: true, Px: hltest.Dec("49000"), Sz: hltest.Dec("0.01"), Created: 1000}},
+	}
+	if err := saved.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Newest first, with a fill of another order and a repeat
+	config.Fills = fills{
+		{Coin: "BTC", Px: hltest.Dec("49000"), Sz: hltest.Dec("0.003"), Side: "B", Time: 3000, Oid: 7, Tid: 2},
+		{Coin: "BTC", Px: hltest.Dec("48500"), Sz: hltest.Dec("1"), Side: "B", Time: 2500, Oid: 8, Tid: 3},
+		{Coin: "BTC", Px: hltest.Dec("49000"), Sz: hltest.Dec("0.002"), Side: "B", Time: 2000, Oid: 7, Cloid: &cloid, Tid: 1},
+		{Coin: "BTC", Px: hltest.Dec("49000"), Sz: hltest.Dec("0.002"), Side: "B", Time: 2000, Oid: 7, Cloid: &cloid, Tid: 1},
+	}
+	g, err := NewGrid(config)
+	if err != nil {
+		t.Fatalf("NewGrid failed: %v", err)
+	}
+	rt := strategy.NewRuntime(strategy.Live(client.NewClient("", "", testKey)), nil, strategy.Config{User: "0xgrid"})
+	if err := g.OnStart(context.Background(), rt); err != nil {
+		t.Fatalf("OnStart failed: %v", err)
+	}
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Orders) != 1 || !state.Orders[0].Filled.Equal(hltest.Dec("0.005")) {
+		t.Fatalf("Expected 0.005 of the saved order filled, got %+v", state.Orders)
+	}
+	if len(state.Pending) != 1 {
+		t.Fatalf("Expected one counter, got %+v", state.Pending)
+	}
+	if p := state.Pending[0]; p.Level != 2 || p.IsBuy || !p.Sz.Equal(hltest.Dec("0.005")) || !p.Entry.Equal(hltest.Dec("49000")) {
+		t.Errorf("Expected a 0.005 sell at level 2 closing 49000, got %+v", p)
+	}
+}
@@ -0,0 +1,98 @@
+package grid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shopspring/decimal"
+)
+
+// Order is a grid order on the exchange
+type Order struct {
+	Cloid   string          `json:"cloid"`
+	Oid     int64           `json:"oid,omitempty"` // Zero until acknowledged
+	Level   int             `json:"level"`
+	IsBuy   bool            `json:"isBuy"`
+	Px      decimal.Decimal `json:"px"`
+	Sz      decimal.Decimal `json:"sz"`
+	Filled  decimal.Decimal `json:"filled"`
+	Entry   decimal.Decimal `json:"entry"` // Price of the fills it closes; zero for the initial grid
+	Created int64           `json:"created"`
+	Tids    []int64         `json:"tids,omitempty"` // Fills already applied
+}
+
+// Remaining returns the unfilled size
+func (o Order) Remaining() decimal.Decimal {
+	return decimal.Max(o.Sz.Sub(o.Filled), decimal.Zero)
+}
+
+// Counter is size waiting to be placed at a level, usually the opposite
+// side of fills at the neighbouring level
+type Counter struct {
+	Level int             `json:"level"`
+	IsBuy bool            `json:"isBuy"`
+	Sz    decimal.Decimal `json:"sz"`
+	Entry decimal.Decimal `json:"entry"` // Volume weighted price of the fills it closes; zero for the initial grid
+}
+
+// State is everything a grid needs to resume after a restart
+type State struct {
+	Prices     []decimal.Decimal `json:"prices"`
+	Anchor     decimal.Decimal   `json:"anchor"` // Price the grid was first placed around; zero until then
+	Orders     []Order           `json:"orders"`
+	Pending    []Counter         `json:"pending"`
+	Profit     decimal.Decimal   `json:"profit"` // Realized on fills that close a round trip, before fees
+	Fees       decimal.Decimal   `json:"fees"`
+	RoundTrips int               `json:"roundTrips"` // Closing orders completely filled
+}
+
+// NetProfit returns the grid profit after fees
+func (s State) NetProfit() decimal.Decimal {
+	return s.Profit.Sub(s.Fees)
+}
+
+// LoadState reads a state saved by Save. It returns nil without an error
+// when the file does not exist.
+func LoadState(path string) (*State, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grid state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse grid state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes the state to path, replacing it atomically
+func (s State) Save(path string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode grid state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save grid state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save grid state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save grid state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save grid state: %w", err)
+	}
+	return nil
+}
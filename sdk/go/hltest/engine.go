@@ -0,0 +1,718 @@
+package hltest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// Order status values reported in orderUpdates
+const (
+	StatusOpen      = "open"
+	StatusFilled    = "filled"
+	StatusCanceled  = "canceled"
+	StatusTriggered = "triggered"
+	StatusRejected  = "rejected"
+)
+
+// Error messages returned in order statuses, matching the exchange's wording
+const (
+	ErrPostOnlyMatch      = "Post only order would have immediately matched"
+	ErrIocNoMatch         = "Order could not immediately match against any resting orders."
+	ErrReduceOnly         = "Reduce only order would increase position."
+	ErrOrderNotFound      = "Order was never placed, already canceled, or filled."
+	ErrInvalidSize        = "Order has zero size."
+	ErrInvalidPrice       = "Order has invalid price."
+	ErrUnknownTimeInForce = "Invalid time in force."
+)
+
+// Order is an open order held by the Engine
+type Order struct {
+	Oid        int64
+	Cloid      *string
+	User       string
+	Coin       string
+	IsBuy      bool
+	LimitPx    decimal.Decimal
+	Sz         decimal.Decimal // Remaining size
+	OrigSz     decimal.Decimal
+	Timestamp  int64
+	ReduceOnly bool
+	Tif        string
+	Trigger    *types.TriggerOrderType
+}
+
+// Side returns the order side as "B" or "A"
+func (o *Order) Side() string {
+	if o.IsBuy {
+		return "B"
+	}
+	return "A"
+}
+
+// BasicOrder converts the order to its orderUpdates representation
+func (o *Order) BasicOrder() types.BasicOrder {
+	return types.BasicOrder{
+		Coin:      o.Coin,
+		Side:      o.Side(),
+		LimitPx:   o.LimitPx,
+		Sz:        o.Sz,
+		Oid:       o.Oid,
+		Timestamp: o.Timestamp,
+		OrigSz:    o.OrigSz,
+		Cloid:     o.Cloid,
+	}
+}
+
+// OpenOrder converts the order to its openOrders representation
+func (o *Order) OpenOrder() types.OpenOrder {
+	orderType := "Limit"
+	if o.Trigger != nil {
+		orderType = "Stop Market"
+		if o.Trigger.TpSl == types.TpSlTakeProfit {
+			orderType = "Take Profit Market"
+		}
+	}
+
+	return types.OpenOrder{
+		Coin:       o.Coin,
+		LimitPx:    o.LimitPx,
+		Oid:        o.Oid,
+		Side:       o.Side(),
+		Sz:         o.Sz,
+		Timestamp:  o.Timestamp,
+		OrigSz:     o.OrigSz,
+		Cloid:      o.Cloid,
+		ReduceOnly: o.ReduceOnly,
+		OrderType:  orderType,
+	}
+}
+
+// Position is a user's net position in one coin
+type Position struct {
+	Szi         decimal.Decimal
+	EntryPx     decimal.Decimal
+	RealizedPnl decimal.Decimal
+}
+
+// eventKind identifies what changed in an engine event
+type eventKind int
+
+const (
+	eventBook eventKind = iota
+	eventFill
+	eventTrade
+	eventOrder
+)
+
+// event describes a change published to WebSocket subscribers
+type event struct {
+	kind   eventKind
+	coin   string
+	user   string
+	fill   types.Fill
+	trade  types.TradeData
+	order  Order
+	status string
+	time   int64
+}
+
+// Engine is an in-memory price-time priority matching engine. Orders match
+// at the resting order's price; fees are not charged. Trigger orders are
+// held open but never triggered.
+type Engine struct {
+	mu        sync.Mutex
+	bids      map[string][]*Order
+	asks      map[string][]*Order
+	orders    map[int64]*Order
+	positions map[string]map[string]*Position
+	fills     map[string][]types.Fill
+	history   map[int64]types.OrderUpdate // Latest status of every order
+	owners    map[int64]string
+	nextOid   int64
+	nextTid   int64
+	now       func() time.Time
+	listeners []func(event)
+}
+
+// NewEngine creates an empty engine
+func NewEngine() *Engine {
+	return &Engine{
+		bids:      make(map[string][]*Order),
+		asks:      make(map[string][]*Order),
+		orders:    make(map[int64]*Order),
+		positions: make(map[string]map[string]*Position),
+		fills:     make(map[string][]types.Fill),
+		history:   make(map[int64]types.OrderUpdate),
+		owners:    make(map[int64]string),
+		nextOid:   1,
+		nextTid:   1,
+		now:       time.Now,
+	}
+}
+
+// SetClock replaces the engine's time source
+func (e *Engine) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
+}
+
+// Now returns the engine's current time
+func (e *Engine) Now() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.now()
+}
+
+// subscribe registers a listener called after each change, outside the lock
+func (e *Engine) subscribe(listener func(event)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, listener)
+}
+
+// Place submits an order for user and returns its status as the exchange
+// would report it
+func (e *Engine) Place(user string, req types.OrderRequest) types.OrderStatus {
+	e.mu.Lock()
+	status, events := e.place(strings.ToLower(user), req)
+	listeners := e.listeners
+	e.mu.Unlock()
+
+	publish(listeners, events)
+	return status
+}
+
+// Cancel cancels user's order in coin by oid
+func (e *Engine) Cancel(user, coin string, oid int64) error {
+	e.mu.Lock()
+	order, ok := e.orders[oid]
+	if !ok || order.User != strings.ToLower(user) || order.Coin != coin {
+		e.mu.Unlock()
+		return errors.New(ErrOrderNotFound)
+	}
+	events := e.remove(order, StatusCanceled)
+	listeners := e.listeners
+	e.mu.Unlock()
+
+	publish(listeners, events)
+	return nil
+}
+
+// CancelByCloid cancels user's order in coin by client order ID
+func (e *Engine) CancelByCloid(user, coin, cloid string) error {
+	order, ok := e.findByCloid(user, cloid)
+	if !ok || order.Coin != coin {
+		return errors.New(ErrOrderNotFound)
+	}
+	return e.Cancel(user, coin, order.Oid)
+}
+
+// CancelAll cancels every open order of user
+func (e *Engine) CancelAll(user string) int {
+	user = strings.ToLower(user)
+
+	e.mu.Lock()
+	var events []event
+	count := 0
+	for _, order := range e.sortedOrders() {
+		if order.User == user {
+			events = append(events, e.remove(order, StatusCanceled)...)
+			count++
+		}
+	}
+	listeners := e.listeners
+	e.mu.Unlock()
+
+	publish(listeners, events)
+	return count
+}
+
+// Modify replaces user's order, found by oid or cloid, with req. The
+// replacement loses time priority.
+func (e *Engine) Modify(user string, oid *int64, cloid *string, req types.OrderRequest) types.OrderStatus {
+	var target *Order
+	switch {
+	case oid != nil:
+		e.mu.Lock()
+		target = e.orders[*oid]
+		e.mu.Unlock()
+	case cloid != nil:
+		target, _ = e.findByCloid(user, *cloid)
+	}
+
+	if target == nil || target.User != strings.ToLower(user) {
+		return errorStatus(ErrOrderNotFound)
+	}
+	if err := e.Cancel(user, target.Coin, target.Oid); err != nil {
+		return errorStatus(err.Error())
+	}
+	return e.Place(user, req)
+}
+
+// OpenOrders returns user's open orders, oldest first
+func (e *Engine) OpenOrders(user string) []types.OpenOrder {
+	user = strings.ToLower(user)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	orders := make([]types.OpenOrder, 0)
+	for _, order := range e.sortedOrders() {
+		if order.User == user {
+			orders = append(orders, order.OpenOrder())
+		}
+	}
+	return orders
+}
+
+// Order returns an open order by oid
+func (e *Engine) Order(oid int64) (Order, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, ok := e.orders[oid]
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// OrderStatus returns the latest status of one of user's orders by oid or
+// cloid, including orders no longer open
+func (e *Engine) OrderStatus(user string, oid *int64, cloid *string) (types.OrderUpdate, bool) {
+	user = strings.ToLower(user)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if oid != nil {
+		update, ok := e.history[*oid]
+		return update, ok && e.owners[*oid] == user
+	}
+	for id, update := range e.history {
+		if cloid != nil && update.Order.Cloid != nil && *update.Order.Cloid == *cloid && e.owners[id] == user {
+			return update, true
+		}
+	}
+	return types.OrderUpdate{}, false
+}
+
+// Fills returns user's fills, oldest first
+func (e *Engine) Fills(user string) []types.Fill {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.Fill{}, e.fills[strings.ToLower(user)]...)
+}
+
+// Position returns user's position in coin
+func (e *Engine) Position(user, coin string) Position {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if p, ok := e.positions[strings.ToLower(user)][coin]; ok {
+		return *p
+	}
+	return Position{}
+}
+
+// Positions returns user's non-zero positions keyed by coin
+func (e *Engine) Positions(user string) map[string]Position {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	positions := make(map[string]Position)
+	for coin, p := range e.positions[strings.ToLower(user)] {
+		if !p.Szi.IsZero() {
+			positions[coin] = *p
+		}
+	}
+	return positions
+}
+
+// Book returns an l2Book snapshot of coin with up to depth levels per side
+func (e *Engine) Book(coin string, depth int) types.L2BookData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.book(coin, depth)
+}
+
+// Mid returns the midpoint of coin's best bid and ask
+func (e *Engine) Mid(coin string) (decimal.Decimal, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.mid(coin)
+}
+
+// Mids returns the mid of every coin with both sides quoted
+func (e *Engine) Mids() map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mids := make(map[string]string)
+	for coin := range e.bids {
+		if mid, ok := e.mid(coin); ok {
+			mids[coin] = mid.String()
+		}
+	}
+	return mids
+}
+
+func (e *Engine) place(user string, req types.OrderRequest) (types.OrderStatus, []event) {
+	if !req.Sz.IsPositive() {
+		return errorStatus(ErrInvalidSize), nil
+	}
+	if !req.LimitPx.IsPositive() {
+		return errorStatus(ErrInvalidPrice), nil
+	}
+
+	now := e.now().UnixMilli()
+	order := &Order{
+		Oid:        e.nextOid,
+		Cloid:      req.Cloid,
+		User:       user,
+		Coin:       req.Asset,
+		IsBuy:      req.IsBuy,
+		LimitPx:    req.LimitPx,
+		Sz:         req.Sz,
+		OrigSz:     req.Sz,
+		Timestamp:  now,
+		ReduceOnly: req.ReduceOnly,
+		Trigger:    req.OrderType.Trigger,
+	}
+
+	if order.Trigger == nil {
+		order.Tif = types.TifGtc
+		if req.OrderType.Limit != nil && req.OrderType.Limit.Tif != "" {
+			order.Tif = req.OrderType.Limit.Tif
+		}
+		if order.Tif != types.TifGtc && order.Tif != types.TifIoc && order.Tif != types.TifAlo {
+			return errorStatus(ErrUnknownTimeInForce), nil
+		}
+	}
+
+	if order.ReduceOnly {
+		position := e.position(user, order.Coin).Szi
+		if position.IsZero() || position.IsPositive() == order.IsBuy {
+			return errorStatus(ErrReduceOnly), nil
+		}
+		order.Sz = decimal.Min(order.Sz, position.Abs())
+		order.OrigSz = order.Sz
+	}
+
+	e.nextOid++
+
+	// Trigger orders wait without matching
+	if order.Trigger != nil {
+		e.orders[order.Oid] = order
+		return restingStatus(order), []event{e.orderEvent(order, StatusOpen, now)}
+	}
+
+	opposite := e.asks[order.Coin]
+	if !order.IsBuy {
+		opposite = e.bids[order.Coin]
+	}
+
+	if order.Tif == types.TifAlo && len(opposite) > 0 && crosses(order, opposite[0].LimitPx) {
+		return errorStatus(fmt.Sprintf("%s, bbo was %s", ErrPostOnlyMatch, opposite[0].LimitPx)), nil
+	}
+
+	events, filled, notional := e.match(order, now)
+
+	if order.Sz.IsPositive() && order.Tif != types.TifIoc {
+		e.rest(order)
+		events = append(events, e.orderEvent(order, StatusOpen, now), event{kind: eventBook, coin: order.Coin})
+		return restingStatus(order), events
+	}
+
+	if filled.IsZero() {
+		return errorStatus(ErrIocNoMatch), events
+	}
+
+	return types.OrderStatus{Filled: &types.FilledOrder{
+		TotalSz: filled,
+		AvgPx:   notional.Div(filled),
+		Oid:     order.Oid,
+	}}, events
+}
+
+// match crosses order against the opposite side, returning the events, the
+// filled size and the filled notional
+func (e *Engine) match(order *Order, now int64) ([]event, decimal.Decimal, decimal.Decimal) {
+	var events []event
+	filled := decimal.Zero
+	notional := decimal.Zero
+
+	book := e.asks
+	if !order.IsBuy {
+		book = e.bids
+	}
+
+	levels := book[order.Coin]
+	for len(levels) > 0 && order.Sz.IsPositive() && crosses(order, levels[0].LimitPx) {
+		maker := levels[0]
+		sz := decimal.Min(order.Sz, maker.Sz)
+		px := maker.LimitPx
+		tid := e.nextTid
+		e.nextTid++
+
+		hash := fmt.Sprintf("0x%064x", tid)
+		events = append(events, e.fill(order, px, sz, true, tid, hash, now)...)
+		events = append(events, e.fill(maker, px, sz, false, tid, hash, now)...)
+		events = append(events, event{kind: eventTrade, coin: order.Coin, trade: types.TradeData{
+			Coin: order.Coin,
+			Side: order.Side(),
+			Px:   px,
+			Sz:   sz,
+			Time: now,
+			Hash: hash,
+			Tid:  tid,
+		}})
+
+		order.Sz = order.Sz.Sub(sz)
+		maker.Sz = maker.Sz.Sub(sz)
+		filled = filled.Add(sz)
+		notional = notional.Add(sz.Mul(px))
+
+		if maker.Sz.IsZero() {
+			levels = levels[1:]
+			delete(e.orders, maker.Oid)
+			events = append(events, e.orderEvent(maker, StatusFilled, now))
+		}
+	}
+	book[order.Coin] = levels
+
+	if filled.IsPositive() {
+		events = append(events, event{kind: eventBook, coin: order.Coin})
+		if order.Sz.IsZero() {
+			events = append(events, e.orderEvent(order, StatusFilled, now))
+		}
+	}
+
+	return events, filled, notional
+}
+
+// fill records one side of a match and updates the user's position
+func (e *Engine) fill(order *Order, px, sz decimal.Decimal, crossed bool, tid int64, hash string, now int64) []event {
+	position := e.position(order.User, order.Coin)
+	start := position.Szi
+
+	signed := sz
+	if !order.IsBuy {
+		signed = sz.Neg()
+	}
+
+	closedPnl := decimal.Zero
+	if start.IsZero() || start.IsPositive() == order.IsBuy {
+		// Opening or adding: average the entry price
+		total := start.Abs().Add(sz)
+		position.EntryPx = start.Abs().Mul(position.EntryPx).Add(sz.Mul(px)).Div(total)
+	} else {
+		closing := decimal.Min(start.Abs(), sz)
+		closedPnl = px.Sub(position.EntryPx).Mul(closing)
+		if start.IsNegative() {
+			closedPnl = closedPnl.Neg()
+		}
+		position.RealizedPnl = position.RealizedPnl.Add(closedPnl)
+
+		if sz.GreaterThan(start.Abs()) {
+			position.EntryPx = px
+		} else if sz.Equal(start.Abs()) {
+			position.EntryPx = decimal.Zero
+		}
+	}
+	position.Szi = start.Add(signed)
+
+	fill := types.Fill{
+		Coin:          order.Coin,
+		Px:            px,
+		Sz:            sz,
+		Side:          order.Side(),
+		Time:          now,
+		StartPosition: start,
+		Dir:           direction(start, order.IsBuy, position.Szi),
+		ClosedPnl:     closedPnl,
+		Hash:          hash,
+		Oid:           order.Oid,
+		Crossed:       crossed,
+		Fee:           decimal.Zero,
+		Tid:           tid,
+		FeeToken:      "USDC",
+		Cloid:         order.Cloid,
+	}
+	e.fills[order.User] = append(e.fills[order.User], fill)
+
+	return []event{{kind: eventFill, coin: order.Coin, user: order.User, fill: fill}}
+}
+
+func (e *Engine) rest(order *Order) {
+	e.orders[order.Oid] = order
+
+	if order.IsBuy {
+		levels := append(e.bids[order.Coin], order)
+		sort.SliceStable(levels, func(i, j int) bool { return levels[i].LimitPx.GreaterThan(levels[j].LimitPx) })
+		e.bids[order.Coin] = levels
+		return
+	}
+
+	levels := append(e.asks[order.Coin], order)
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].LimitPx.LessThan(levels[j].LimitPx) })
+	e.asks[order.Coin] = levels
+}
+
+// remove takes an open order off the engine
+func (e *Engine) remove(order *Order, status string) []event {
+	delete(e.orders, order.Oid)
+
+	events := []event{e.orderEvent(order, status, e.now().UnixMilli())}
+	if order.Trigger != nil {
+		return events
+	}
+
+	book := e.asks
+	if order.IsBuy {
+		book = e.bids
+	}
+	levels := book[order.Coin]
+	for i, o := range levels {
+		if o.Oid == order.Oid {
+			book[order.Coin] = append(levels[:i:i], levels[i+1:]...)
+			break
+		}
+	}
+
+	return append(events, event{kind: eventBook, coin: order.Coin})
+}
+
+func (e *Engine) findByCloid(user, cloid string) (*Order, bool) {
+	user = strings.ToLower(user)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range e.orders {
+		if order.User == user && order.Cloid != nil && *order.Cloid == cloid {
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+func (e *Engine) position(user, coin string) *Position {
+	positions, ok := e.positions[user]
+	if !ok {
+		positions = make(map[string]*Position)
+		e.positions[user] = positions
+	}
+	position, ok := positions[coin]
+	if !ok {
+		position = &Position{}
+		positions[coin] = position
+	}
+	return position
+}
+
+func (e *Engine) sortedOrders() []*Order {
+	orders := make([]*Order, 0, len(e.orders))
+	for _, order := range e.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Oid < orders[j].Oid })
+	return orders
+}
+
+func (e *Engine) book(coin string, depth int) types.L2BookData {
+	return types.L2BookData{
+		Coin:   coin,
+		Time:   e.now().UnixMilli(),
+		Levels: [][]interface{}{aggregate(e.bids[coin], depth), aggregate(e.asks[coin], depth)},
+	}
+}
+
+func (e *Engine) mid(coin string) (decimal.Decimal, bool) {
+	bids, asks := e.bids[coin], e.asks[coin]
+	if len(bids) == 0 || len(asks) == 0 {
+		return decimal.Zero, false
+	}
+	return bids[0].LimitPx.Add(asks[0].LimitPx).Div(decimal.NewFromInt(2)), true
+}
+
+// aggregate groups one side of the book into price levels
+func aggregate(orders []*Order, depth int) []interface{} {
+	levels := make([]interface{}, 0)
+	var last *types.OrderBookLevel
+	for _, order := range orders {
+		if last != nil && last.Price.Equal(order.LimitPx) {
+			last.Size = last.Size.Add(order.Sz)
+			last.NumOrders++
+			continue
+		}
+		if depth > 0 && len(levels) == depth {
+			break
+		}
+		last = &types.OrderBookLevel{Price: order.LimitPx, Size: order.Sz, NumOrders: 1}
+		levels = append(levels, last)
+	}
+	return levels
+}
+
+func crosses(order *Order, px decimal.Decimal) bool {
+	if order.IsBuy {
+		return px.LessThanOrEqual(order.LimitPx)
+	}
+	return px.GreaterThanOrEqual(order.LimitPx)
+}
+
+// direction describes a fill the way the exchange labels it
+func direction(start decimal.Decimal, isBuy bool, end decimal.Decimal) string {
+	switch {
+	case start.IsZero() || start.IsPositive() == isBuy:
+		if isBuy {
+			return "Open Long"
+		}
+		return "Open Short"
+	case !end.IsZero() && end.IsPositive() != start.IsPositive():
+		if isBuy {
+			return "Short > Long"
+		}
+		return "Long > Short"
+	case isBuy:
+		return "Close Short"
+	default:
+		return "Close Long"
+	}
+}
+
+// orderEvent records an order's new status and describes it for subscribers
+func (e *Engine) orderEvent(order *Order, status string, now int64) event {
+	e.history[order.Oid] = types.OrderUpdate{Order: order.BasicOrder(), Status: status, StatusTimestamp: now}
+	e.owners[order.Oid] = order.User
+	return event{kind: eventOrder, coin: order.Coin, user: order.User, order: *order, status: status, time: now}
+}
+
+func restingStatus(order *Order) types.OrderStatus {
+	resting := &types.RestingOrder{Oid: order.Oid}
+	if order.Cloid != nil {
+		resting.Cloid = *order.Cloid
+	}
+	return types.OrderStatus{Resting: resting}
+}
+
+func errorStatus(message string) types.OrderStatus {
+	return types.OrderStatus{Error: &message}
+}
+
+func publish(listeners []func(event), events []event) {
+	for _, ev := range events {
+		for _, listener := range listeners {
+			listener(ev)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package hltest
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// PollTimeout bounds how long WaitFor waits for a condition
+var PollTimeout = 3 * time.Second
+
+// Dec parses a decimal literal, panicking on malformed input
+func Dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// Limit builds a limit order request
+func Limit(coin string, isBuy bool, px, sz, tif string) types.OrderRequest {
+	return types.OrderRequest{
+		Asset:     coin,
+		IsBuy:     isBuy,
+		LimitPx:   Dec(px),
+		Sz:        Dec(sz),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: tif}},
+	}
+}
+
+// WaitFor polls get until it returns want, failing the test after PollTimeout
+func WaitFor(t testing.TB, want []string, get func() []string) {
+	t.Helper()
+	deadline := time.Now().Add(PollTimeout)
+	for {
+		got := get()
+		if equal(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// OrderSummary lists a user's open orders as "B49000/0.01", sorted
+func (s *Server) OrderSummary(user string) []string {
+	open := s.engine.OpenOrders(user)
+	out := make([]string, len(open))
+	for i, o := range open {
+		out[i] = o.Side + o.LimitPx.String() + "/" + o.Sz.String()
+	}
+	sort.Strings(out)
+	return out
+}
+
+// WaitForOrders polls until a user's open orders match want in any order
+func (s *Server) WaitForOrders(t testing.TB, user string, want []string) {
+	t.Helper()
+	sorted := append([]string(nil), want...)
+	sort.Strings(sorted)
+	WaitFor(t, sorted, func() []string { return s.OrderSummary(user) })
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
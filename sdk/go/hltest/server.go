@@ -0,0 +1,760 @@
+// Package hltest provides an in-process fake of the Hyperliquid API for
+// offline tests. A Server answers /info and /exchange over HTTP and serves
+// WebSocket subscriptions, backed by an in-memory matching engine:
+//
+//	srv := hltest.NewServer(hltest.Config{})
+//	defer srv.Close()
+//	c := client.NewClient(srv.URL, srv.WSURL, key)
+package hltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+// InfoHandler answers an /info request. The returned value is encoded as the
+// response body; an error becomes an HTTP 500.
+type InfoHandler func(body map[string]interface{}) (interface{}, error)
+
+// ActionHandler answers an /exchange action signed by user. The returned
+// value is encoded as the response body; an error becomes an error envelope.
+type ActionHandler func(user string, action map[string]interface{}) (interface{}, error)
+
+// Config configures a Server
+type Config struct {
+	Meta     *types.Meta     // Perp universe (default BTC, ETH, SOL)
+	SpotMeta *types.SpotMeta // Spot universe (default PURR/USDC)
+
+	// Accounts restricts which signers may submit actions. When empty any
+	// valid signature is accepted.
+	Accounts []string
+
+	// SkipSignatureCheck accepts actions without verifying signatures; the
+	// acting user is then taken from VaultAddress or "0x0".
+	SkipSignatureCheck bool
+}
+
+// Request is an HTTP request received by the Server
+type Request struct {
+	Endpoint string
+	Body     map[string]interface{}
+	Signer   string // Recovered signer for /exchange requests
+}
+
+// Server is a fake Hyperliquid API
+type Server struct {
+	*httptest.Server
+	WSURL string
+
+	config   Config
+	engine   *Engine
+	hub      *hub
+	meta     types.Meta
+	spotMeta types.SpotMeta
+
+	mu        sync.Mutex
+	info      map[string]InfoHandler
+	actions   map[string]ActionHandler
+	requests  []Request
+	nonces    map[string]map[int64]bool
+	balances  map[string]decimal.Decimal
+	leverage  map[string]map[string]types.Leverage
+	scheduled map[string]time.Time
+	timers    map[string]*time.Timer
+}
+
+// NewServer starts a fake API server
+func NewServer(config Config) *Server {
+	s := &Server{
+		config:    config,
+		engine:    NewEngine(),
+		meta:      defaultMeta(),
+		spotMeta:  defaultSpotMeta(),
+		info:      make(map[string]InfoHandler),
+		actions:   make(map[string]ActionHandler),
+		nonces:    make(map[string]map[int64]bool),
+		balances:  make(map[string]decimal.Decimal),
+		leverage:  make(map[string]map[string]types.Leverage),
+		scheduled: make(map[string]time.Time),
+		timers:    make(map[string]*time.Timer),
+	}
+	if config.Meta != nil {
+		s.meta = *config.Meta
+	}
+	if config.SpotMeta != nil {
+		s.spotMeta = *config.SpotMeta
+	}
+
+	s.hub = newHub(s)
+	s.engine.subscribe(s.hub.publish)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", s.handleInfo)
+	mux.HandleFunc("/exchange", s.handleExchange)
+	mux.HandleFunc("/ws", s.hub.serve)
+
+	s.Server = httptest.NewServer(mux)
+	s.WSURL = "ws" + strings.TrimPrefix(s.URL, "http") + "/ws"
+
+	return s
+}
+
+// Close drops WebSocket connections and shuts the server down
+func (s *Server) Close() {
+	s.mu.Lock()
+	for _, timer := range s.timers {
+		timer.Stop()
+	}
+	s.mu.Unlock()
+
+	s.hub.closeAll()
+	s.Server.Close()
+}
+
+// Engine returns the matching engine, e.g. to seed liquidity
+func (s *Server) Engine() *Engine {
+	return s.engine
+}
+
+// HandleInfo overrides the response for an /info request type
+func (s *Server) HandleInfo(infoType string, handler InfoHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info[infoType] = handler
+}
+
+// HandleAction overrides the response for an /exchange action type. Action
+// types the server does not implement are rejected unless handled here.
+func (s *Server) HandleAction(actionType string, handler ActionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions[actionType] = handler
+}
+
+// SetBalance sets user's USDC balance used for account value
+func (s *Server) SetBalance(user string, usdc decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[strings.ToLower(user)] = usdc
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ScheduledCancel returns user's pending scheduled cancel time, if any
+func (s *Server) ScheduledCancel(user string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.scheduled[strings.ToLower(user)]
+	return at, ok
+}
+
+// RunScheduledCancels cancels every open order of users whose scheduled
+// cancel is due by the engine's clock. It runs before each request and when
+// a scheduled time passes, so tests that move the clock with
+// Engine.SetClock can also call it directly.
+func (s *Server) RunScheduledCancels() {
+	now := s.engine.Now()
+
+	s.mu.Lock()
+	var due []string
+	for user, at := range s.scheduled {
+		if !at.After(now) {
+			due = append(due, user)
+			delete(s.scheduled, user)
+			if timer, ok := s.timers[user]; ok {
+				timer.Stop()
+				delete(s.timers, user)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, user := range due {
+		s.engine.CancelAll(user)
+	}
+}
+
+// Leverage returns user's leverage setting for coin
+func (s *Server) Leverage(user, coin string) types.Leverage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leverageFor(strings.ToLower(user), coin)
+}
+
+// Publish pushes data on channel to every WebSocket connection subscribed
+// to it, regardless of coin or user
+func (s *Server) Publish(channel string, data interface{}) {
+	s.hub.broadcast(channel, data)
+}
+
+// DropConnections closes every WebSocket connection, as a network failure
+// would. Clients are free to reconnect.
+func (s *Server) DropConnections() {
+	s.hub.closeAll()
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	infoType, _ := body["type"].(string)
+	s.RunScheduledCancels()
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Endpoint: "/info", Body: body})
+	handler, ok := s.info[infoType]
+	s.mu.Unlock()
+
+	if !ok {
+		handler = s.defaultInfo(infoType)
+	}
+	if handler == nil {
+		http.Error(w, fmt.Sprintf("unsupported info type: %q", infoType), http.StatusUnprocessableEntity)
+		return
+	}
+
+	resp, err := handler(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleExchange(w http.ResponseWriter, r *http.Request) {
+	raw := new(bytes.Buffer)
+	if _, err := raw.ReadFrom(r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw.Bytes(), &body); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Decode the nonce separately so that it keeps full precision
+	var envelope struct {
+		Nonce     int64  `json:"nonce"`
+		Signature string `json:"signature"`
+	}
+	json.Unmarshal(raw.Bytes(), &envelope)
+
+	action, ok := body["action"].(map[string]interface{})
+	if !ok {
+		http.Error(w, "missing action", http.StatusBadRequest)
+		return
+	}
+	actionType, _ := action["type"].(string)
+	s.RunScheduledCancels()
+
+	user, err := s.authenticate(action, envelope.Signature, envelope.Nonce)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Endpoint: "/exchange", Body: body, Signer: user})
+	handler, overridden := s.actions[actionType]
+	s.mu.Unlock()
+
+	if err != nil {
+		writeJSON(w, errorResponse(err.Error()))
+		return
+	}
+
+	if !overridden {
+		handler = s.defaultAction(actionType)
+	}
+
+	resp, err := handler(user, action)
+	if err != nil {
+		writeJSON(w, errorResponse(err.Error()))
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// authenticate recovers the signer and checks it against the configured
+// accounts and previously used nonces
+func (s *Server) authenticate(action map[string]interface{}, signature string, nonce int64) (string, error) {
+	if s.config.SkipSignatureCheck {
+		if vault, ok := action["vaultAddress"].(string); ok {
+			return strings.ToLower(vault), nil
+		}
+		return "0x0", nil
+	}
+
+	signer, err := utils.RecoverSigner(action, signature, nonce)
+	if err != nil {
+		return "", fmt.Errorf("Invalid signature: %v", err)
+	}
+	signer = strings.ToLower(signer)
+
+	if len(s.config.Accounts) > 0 {
+		known := false
+		for _, account := range s.config.Accounts {
+			if strings.EqualFold(account, signer) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return signer, fmt.Errorf("User or API Wallet %s does not exist.", signer)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nonces[signer] == nil {
+		s.nonces[signer] = make(map[int64]bool)
+	}
+	if s.nonces[signer][nonce] {
+		return signer, fmt.Errorf("Invalid nonce: duplicate nonce %d", nonce)
+	}
+	s.nonces[signer][nonce] = true
+
+	return signer, nil
+}
+
+func (s *Server) defaultInfo(infoType string) InfoHandler {
+	switch infoType {
+	case "meta":
+		return func(map[string]interface{}) (interface{}, error) { return s.meta, nil }
+	case "spotMeta":
+		return func(map[string]interface{}) (interface{}, error) { return s.spotMeta, nil }
+	case "allMids":
+		return func(map[string]interface{}) (interface{}, error) { return s.engine.Mids(), nil }
+	case "l2Book":
+		return func(body map[string]interface{}) (interface{}, error) {
+			coin, _ := body["coin"].(string)
+			return s.engine.Book(coin, bookDepth), nil
+		}
+	case "openOrders", "frontendOpenOrders":
+		return func(body map[string]interface{}) (interface{}, error) {
+			return s.engine.OpenOrders(userField(body)), nil
+		}
+	case "userFills", "userFillsByTime":
+		return func(body map[string]interface{}) (interface{}, error) {
+			fills := s.engine.Fills(userField(body))
+			start, hasStart := body["startTime"].(float64)
+			end, hasEnd := body["endTime"].(float64)
+
+			// Newest first, as the exchange returns them
+			filtered := make([]types.Fill, 0, len(fills))
+			for i := len(fills) - 1; i >= 0; i-- {
+				if hasStart && fills[i].Time < int64(start) {
+					continue
+				}
+				if hasEnd && fills[i].Time > int64(end) {
+					continue
+				}
+				filtered = append(filtered, fills[i])
+			}
+			return filtered, nil
+		}
+	case "clearinghouseState":
+		return func(body map[string]interface{}) (interface{}, error) {
+			return s.userState(userField(body)), nil
+		}
+	case "maxBuilderFee":
+		return func(map[string]interface{}) (interface{}, error) { return 0, nil }
+	case "orderStatus":
+		return func(body map[string]interface{}) (interface{}, error) {
+			var oid *int64
+			if raw, ok := body["oid"].(float64); ok {
+				id := int64(raw)
+				oid = &id
+			}
+			var cloid *string
+			if raw, ok := body["cloid"].(string); ok {
+				cloid = &raw
+			}
+
+			update, ok := s.engine.OrderStatus(userField(body), oid, cloid)
+			if !ok {
+				return map[string]interface{}{"status": "unknownOid"}, nil
+			}
+			return map[string]interface{}{"status": "order", "order": update}, nil
+		}
+	}
+	return nil
+}
+
+func (s *Server) defaultAction(actionType string) ActionHandler {
+	switch actionType {
+	case "order":
+		return s.handleOrder
+	case "cancel":
+		return s.handleCancel
+	case "cancelByCloid":
+		return s.handleCancelByCloid
+	case "batchModify":
+		return s.handleBatchModify
+	case "scheduleCancel":
+		return s.handleScheduleCancel
+	case "updateLeverage":
+		return s.handleUpdateLeverage
+	}
+	return func(string, map[string]interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("unsupported action type: %q", actionType)
+	}
+}
+
+func (s *Server) handleOrder(user string, action map[string]interface{}) (interface{}, error) {
+	var orders []types.OrderRequest
+	if err := remarshal(action["orders"], &orders); err != nil {
+		return nil, fmt.Errorf("invalid orders: %v", err)
+	}
+
+	statuses := make([]types.OrderStatus, 0, len(orders))
+	for _, order := range orders {
+		if _, err := s.asset(order.Asset); err != nil {
+			statuses = append(statuses, errorStatus(err.Error()))
+			continue
+		}
+		statuses = append(statuses, s.engine.Place(user, order))
+	}
+
+	return orderResponse("order", statuses), nil
+}
+
+func (s *Server) handleCancel(user string, action map[string]interface{}) (interface{}, error) {
+	var cancels []struct {
+		Asset *int    `json:"a"`
+		Oid   *int64  `json:"o"`
+		Coin  string  `json:"coin"`
+		OidV1 *int64  `json:"oid"`
+		Cloid *string `json:"cloid"`
+	}
+	if err := remarshal(action["cancels"], &cancels); err != nil {
+		return nil, fmt.Errorf("invalid cancels: %v", err)
+	}
+
+	statuses := make([]interface{}, 0, len(cancels))
+	for _, cancel := range cancels {
+		// Accept both the wire format ({a, o}) and the named format ({coin, oid})
+		coin, oid := cancel.Coin, cancel.OidV1
+		if cancel.Asset != nil {
+			coin = s.coinForAsset(*cancel.Asset)
+			oid = cancel.Oid
+		}
+
+		var err error
+		switch {
+		case oid != nil:
+			err = s.engine.Cancel(user, coin, *oid)
+		case cancel.Cloid != nil:
+			err = s.engine.CancelByCloid(user, coin, *cancel.Cloid)
+		default:
+			err = errors.New(ErrOrderNotFound)
+		}
+		statuses = append(statuses, actionStatus(err))
+	}
+
+	return cancelResponse(statuses), nil
+}
+
+func (s *Server) handleCancelByCloid(user string, action map[string]interface{}) (interface{}, error) {
+	var cancels []struct {
+		Asset int    `json:"asset"`
+		Cloid string `json:"cloid"`
+	}
+	if err := remarshal(action["cancels"], &cancels); err != nil {
+		return nil, fmt.Errorf("invalid cancels: %v", err)
+	}
+
+	statuses := make([]interface{}, 0, len(cancels))
+	for _, cancel := range cancels {
+		err := s.engine.CancelByCloid(user, s.coinForAsset(cancel.Asset), cancel.Cloid)
+		statuses = append(statuses, actionStatus(err))
+	}
+
+	return cancelResponse(statuses), nil
+}
+
+func (s *Server) handleBatchModify(user string, action map[string]interface{}) (interface{}, error) {
+	var modifies []struct {
+		Oid   interface{}        `json:"oid"`
+		Order types.OrderRequest `json:"order"`
+	}
+	if err := remarshal(action["modifies"], &modifies); err != nil {
+		return nil, fmt.Errorf("invalid modifies: %v", err)
+	}
+
+	statuses := make([]types.OrderStatus, 0, len(modifies))
+	for _, modify := range modifies {
+		switch id := modify.Oid.(type) {
+		case float64:
+			oid := int64(id)
+			statuses = append(statuses, s.engine.Modify(user, &oid, nil, modify.Order))
+		case string:
+			statuses = append(statuses, s.engine.Modify(user, nil, &id, modify.Order))
+		default:
+			statuses = append(statuses, errorStatus(ErrOrderNotFound))
+		}
+	}
+
+	return orderResponse("order", statuses), nil
+}
+
+func (s *Server) handleScheduleCancel(user string, action map[string]interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[user]; ok {
+		timer.Stop()
+		delete(s.timers, user)
+	}
+
+	at, ok := action["time"].(float64)
+	if !ok {
+		delete(s.scheduled, user)
+		return defaultResponse(), nil
+	}
+
+	scheduled := time.UnixMilli(int64(at))
+	wait := scheduled.Sub(s.engine.Now())
+	if wait < 5*time.Second {
+		return nil, fmt.Errorf("Scheduled cancel time too early, must be at least 5 seconds from now.")
+	}
+	s.scheduled[user] = scheduled
+	s.timers[user] = time.AfterFunc(wait, s.RunScheduledCancels)
+
+	return defaultResponse(), nil
+}
+
+func (s *Server) handleUpdateLeverage(user string, action map[string]interface{}) (interface{}, error) {
+	asset, _ := action["asset"].(float64)
+	leverage, _ := action["leverage"].(float64)
+	isCross, _ := action["isCross"].(bool)
+
+	coin := s.coinForAsset(int(asset))
+	info, err := s.asset(coin)
+	if err != nil {
+		return nil, err
+	}
+	if leverage < 1 || int(leverage) > info.MaxLeverage {
+		return nil, fmt.Errorf("Invalid leverage value")
+	}
+
+	mode := types.MarginIsolated
+	if isCross {
+		mode = types.MarginCross
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leverage[user] == nil {
+		s.leverage[user] = make(map[string]types.Leverage)
+	}
+	s.leverage[user][coin] = types.Leverage{Type: string(mode), Value: decimal.NewFromFloat(leverage)}
+
+	return defaultResponse(), nil
+}
+
+// userState builds clearinghouseState from engine positions, valuing them
+// at the current mid (or entry price when the book is one-sided). As on the
+// exchange, account value is raw USD plus each position's signed value.
+func (s *Server) userState(user string) types.UserState {
+	user = strings.ToLower(user)
+
+	s.mu.Lock()
+	balance := s.balances[user]
+	s.mu.Unlock()
+
+	state := types.UserState{AssetPositions: []types.AssetPosition{}}
+	accountValue := balance
+	rawUsd := balance
+	marginUsed := decimal.Zero
+	notional := decimal.Zero
+
+	for coin, p := range s.engine.Positions(user) {
+		markPx, ok := s.engine.Mid(coin)
+		if !ok {
+			markPx = p.EntryPx
+		}
+
+		s.mu.Lock()
+		leverage := s.leverageFor(user, coin)
+		s.mu.Unlock()
+
+		value := p.Szi.Abs().Mul(markPx)
+		upnl := markPx.Sub(p.EntryPx).Mul(p.Szi)
+		margin := value.Div(leverage.Value)
+
+		accountValue = accountValue.Add(upnl).Add(p.RealizedPnl)
+		rawUsd = rawUsd.Add(p.RealizedPnl).Sub(p.Szi.Mul(p.EntryPx))
+		marginUsed = marginUsed.Add(margin)
+		notional = notional.Add(value)
+
+		state.AssetPositions = append(state.AssetPositions, types.AssetPosition{
+			Type: "oneWay",
+			Position: types.Position{
+				Coin:          coin,
+				EntryPx:       p.EntryPx,
+				Szi:           p.Szi,
+				Leverage:      leverage,
+				UnrealizedPnl: upnl,
+				RealizedPnl:   p.RealizedPnl,
+				PositionValue: value,
+				MarginUsed:    margin,
+			},
+		})
+	}
+
+	state.MarginSummary = types.MarginSummary{
+		AccountValue:    accountValue,
+		TotalMarginUsed: marginUsed,
+		TotalNtlPos:     notional,
+		TotalRawUsd:     rawUsd,
+		WithdrawableUsd: decimal.Max(accountValue.Sub(marginUsed), decimal.Zero),
+	}
+	state.CrossMarginSummary = types.CrossMarginSummary{
+		AccountValue:    accountValue,
+		TotalMarginUsed: marginUsed,
+		TotalNtlPos:     notional,
+		TotalRawUsd:     rawUsd,
+	}
+
+	return state
+}
+
+// leverageFor returns the user's leverage for coin, defaulting to cross at
+// the lower of 20x and the asset maximum. Callers hold s.mu.
+func (s *Server) leverageFor(user, coin string) types.Leverage {
+	if leverage, ok := s.leverage[user][coin]; ok {
+		return leverage
+	}
+
+	value := 20
+	if info, err := s.asset(coin); err == nil && info.MaxLeverage > 0 && info.MaxLeverage < value {
+		value = info.MaxLeverage
+	}
+	return types.Leverage{Type: string(types.MarginCross), Value: decimal.NewFromInt(int64(value))}
+}
+
+// asset resolves a coin name against the configured universes
+func (s *Server) asset(coin string) (types.AssetInfo, error) {
+	for idx, asset := range s.meta.Universe {
+		if asset.Name == coin {
+			return types.AssetInfo{ID: idx, Coin: coin, SzDecimals: asset.SzDecimals, MaxLeverage: asset.MaxLeverage, OnlyIsolated: asset.OnlyIsolated}, nil
+		}
+	}
+	for _, pair := range s.spotMeta.Universe {
+		if pair.Name == coin || fmt.Sprintf("@%d", pair.Index) == coin {
+			return types.AssetInfo{ID: 10000 + pair.Index, Coin: pair.Name, SzDecimals: pair.SzDecimals, IsSpot: true}, nil
+		}
+	}
+	return types.AssetInfo{}, fmt.Errorf("Unknown asset %s", coin)
+}
+
+// coinForAsset maps a wire asset ID back to its coin name
+func (s *Server) coinForAsset(id int) string {
+	if id >= 10000 {
+		for _, pair := range s.spotMeta.Universe {
+			if 10000+pair.Index == id {
+				return pair.Name
+			}
+		}
+		return ""
+	}
+	if id >= 0 && id < len(s.meta.Universe) {
+		return s.meta.Universe[id].Name
+	}
+	return ""
+}
+
+// bookDepth is the number of levels per side in l2Book responses
+const bookDepth = 20
+
+func defaultMeta() types.Meta {
+	return types.Meta{Universe: []types.Asset{
+		{Name: "BTC", SzDecimals: 5, MaxLeverage: 50},
+		{Name: "ETH", SzDecimals: 4, MaxLeverage: 25},
+		{Name: "SOL", SzDecimals: 2, MaxLeverage: 20},
+	}}
+}
+
+func defaultSpotMeta() types.SpotMeta {
+	return types.SpotMeta{
+		Universe: []types.SpotToken{
+			{Name: "PURR/USDC", Tokens: []int{1, 0}, Index: 0},
+		},
+		Tokens: []types.SpotTokenInfo{
+			{Name: "USDC", SzDecimals: 8, WeiDecimals: 8, Index: 0, IsCanonical: true},
+			{Name: "PURR", SzDecimals: 0, WeiDecimals: 5, Index: 1, IsCanonical: true},
+		},
+	}
+}
+
+func userField(body map[string]interface{}) string {
+	user, _ := body["user"].(string)
+	return strings.ToLower(user)
+}
+
+func remarshal(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func errorResponse(message string) map[string]interface{} {
+	return map[string]interface{}{"status": "err", "response": message}
+}
+
+func defaultResponse() map[string]interface{} {
+	return map[string]interface{}{"status": "ok", "response": map[string]interface{}{"type": "default"}}
+}
+
+func orderResponse(responseType string, statuses []types.OrderStatus) map[string]interface{} {
+	return map[string]interface{}{
+		"status": "ok",
+		"response": map[string]interface{}{
+			"type": responseType,
+			"data": map[string]interface{}{"statuses": statuses},
+		},
+	}
+}
+
+func cancelResponse(statuses []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"status": "ok",
+		"response": map[string]interface{}{
+			"type": "cancel",
+			"data": map[string]interface{}{"statuses": statuses},
+		},
+	}
+}
+
+// actionStatus encodes a cancel result as "success" or {"error": "..."}
+func actionStatus(err error) interface{} {
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	return "success"
+}
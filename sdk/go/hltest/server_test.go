@@ -0,0 +1,321 @@
+package hltest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// Test private keys (DO NOT USE IN PRODUCTION)
+const (
+	makerKey = "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	takerKey = "0xfedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+func newClient(t *testing.T, srv *Server, key string) *client.Client {
+	address, err := utils.GetAddressFromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to derive address: %v", err)
+	}
+
+	c := client.NewClient(srv.URL, srv.WSURL, key)
+	c.SetAddress(address)
+	return c
+}
+
+func TestOrderFlow(t *testing.T) {
+	srv := NewServer(Config{})
+	defer srv.Close()
+
+	ctx := context.Background()
+	maker := newClient(t, srv, makerKey)
+	taker := newClient(t, srv, takerKey)
+
+	resp, err := maker.Exchange().PlaceOrders(ctx, []types.OrderRequest{
+		Limit("BTC", true, "49000", "1", types.TifGtc),
+		Limit("BTC", false, "51000", "1", types.TifGtc),
+	}, types.GroupingNA)
+	if err != nil {
+		t.Fatalf("Failed to place maker orders: %v", err)
+	}
+	for i, status := range resp.Response.Data.Statuses {
+		if status.Resting == nil {
+			t.Errorf("Expected order %d to rest, got %+v", i, status)
+		}
+	}
+
+	filled, err := taker.Exchange().MarketOpen(ctx, "BTC", true, Dec("0.5"), client.DefaultSlippage)
+	if err != nil {
+		t.Fatalf("Failed to open market position: %v", err)
+	}
+	if !filled.TotalSz.Equal(Dec("0.5")) || !filled.AvgPx.Equal(decimal.NewFromInt(51000)) {
+		t.Errorf("Expected 0.5 filled at 51000, got %s at %s", filled.TotalSz, filled.AvgPx)
+	}
+
+	state, err := taker.Info().GetUserState(ctx, taker.GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to get taker state: %v", err)
+	}
+	if len(state.AssetPositions) != 1 || !state.AssetPositions[0].Position.Szi.Equal(Dec("0.5")) {
+		t.Errorf("Expected taker long 0.5 BTC, got %+v", state.AssetPositions)
+	}
+	if p := srv.Engine().Position(maker.GetAddress(), "BTC"); !p.Szi.Equal(Dec("-0.5")) {
+		t.Errorf("Expected maker short 0.5 BTC, got %s", p.Szi)
+	}
+
+	fills, err := maker.Info().GetUserFills(ctx, maker.GetAddress(), nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get maker fills: %v", err)
+	}
+	if len(fills) != 1 || fills[0].Crossed || fills[0].Dir != "Open Short" {
+		t.Errorf("Expected one maker fill opening a short, got %+v", fills)
+	}
+
+	results, err := maker.Exchange().CancelAll(ctx, types.CancelFilter{})
+	if err != nil {
+		t.Fatalf("Failed to cancel maker orders: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 cancels, got %d", len(results))
+	}
+	for _, result := range results {
+		if !result.Status.Success {
+			t.Errorf("Expected cancel of %d to succeed, got %v", result.Order.Oid, *result.Status.Error)
+		}
+	}
+
+	orders, err := maker.Info().GetOpenOrders(ctx, maker.GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to get open orders: %v", err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("Expected no open orders, got %d", len(orders))
+	}
+}
+
+func TestSignatureCheck(t *testing.T) {
+	maker, _ := utils.GetAddressFromPrivateKey(makerKey)
+	srv := NewServer(Config{Accounts: []string{maker}})
+	defer srv.Close()
+
+	ctx := context.Background()
+	stranger := newClient(t, srv, takerKey)
+
+	_, err := stranger.Exchange().PlaceOrder(ctx, Limit("BTC", true, "49000", "1", types.TifGtc))
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected unknown signer to be rejected, got %v", err)
+	}
+
+	resp, err := newClient(t, srv, makerKey).Exchange().PlaceOrder(ctx, Limit("BTC", true, "49000", "1", types.TifGtc))
+	if err != nil {
+		t.Fatalf("Expected known signer to be accepted: %v", err)
+	}
+	if resp.Response.Data.Statuses[0].Resting == nil {
+		t.Errorf("Expected order to rest, got %+v", resp.Response.Data.Statuses[0])
+	}
+
+	requests := srv.Requests()
+	last := requests[len(requests)-1]
+	if !strings.EqualFold(last.Signer, maker) {
+		t.Errorf("Expected signer %s, got %s", maker, last.Signer)
+	}
+}
+
+func TestProgrammableResponses(t *testing.T) {
+	srv := NewServer(Config{})
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := newClient(t, srv, makerKey)
+
+	srv.HandleInfo("allMids", func(map[string]interface{}) (interface{}, error) {
+		return map[string]string{"BTC": "12345"}, nil
+	})
+	mids, err := c.Info().GetAllMids(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get mids: %v", err)
+	}
+	if mids["BTC"] != "12345" {
+		t.Errorf("Expected overridden mid 12345, got %q", mids["BTC"])
+	}
+
+	srv.HandleAction("order", func(user string, action map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("Insufficient margin to place order.")
+	})
+	_, err = c.Exchange().PlaceOrder(ctx, Limit("BTC", true, "49000", "1", types.TifGtc))
+	if err == nil || !strings.Contains(err.Error(), "Insufficient margin") {
+		t.Errorf("Expected overridden order error, got %v", err)
+	}
+
+	at := time.Now().Add(time.Minute)
+	if _, err := c.Exchange().ScheduleCancel(ctx, &at); err != nil {
+		t.Fatalf("Failed to schedule cancel: %v", err)
+	}
+	if scheduled, ok := srv.ScheduledCancel(c.GetAddress()); !ok || scheduled.UnixMilli() != at.UnixMilli() {
+		t.Errorf("Expected cancel scheduled at %v, got %v", at, scheduled)
+	}
+}
+
+func TestUnknownActionsRejected(t *testing.T) {
+	srv := NewServer(Config{})
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := newClient(t, srv, makerKey)
+
+	if _, err := c.Exchange().ApproveBuilderFee(ctx, "0x1234567890123456789012345678901234567890", 10); err == nil || !strings.Contains(err.Error(), "unsupported action") {
+		t.Errorf("Expected an unsupported action error, got %v", err)
+	}
+
+	srv.HandleAction("approveBuilderFee", func(string, map[string]interface{}) (interface{}, error) {
+		return defaultResponse(), nil
+	})
+	if _, err := c.Exchange().ApproveBuilderFee(ctx, "0x1234567890123456789012345678901234567890", 10); err != nil {
+		t.Errorf("Expected a handled action to pass, got %v", err)
+	}
+}
+
+func TestOrderStatus(t *testing.T) {
+	srv := NewServer(Config{})
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := newClient(t, srv, makerKey)
+
+	cloid := "0x00000000000000000000000000000001"
+	order := Limit("BTC", true, "49000", "1", types.TifGtc)
+	order.Cloid = &cloid
+	if _, err := c.Exchange().PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("Failed to place order: %v", err)
+	}
+	if _, err := c.Exchange().CancelByCloid(ctx, "BTC", cloid); err != nil {
+		t.Fatalf("Failed to cancel: %v", err)
+	}
+
+	status, err := c.Info().GetOrderStatus(ctx, c.GetAddress(), nil, &cloid)
+	if err != nil {
+		t.Fatalf("Failed to get order status: %v", err)
+	}
+	update, _ := status["order"].(map[string]interface{})
+	if status["status"] != "order" || update["status"] != StatusCanceled {
+		t.Errorf("Expected the canceled order, got %v", status)
+	}
+
+	unknown := int64(999)
+	if status, err := c.Info().GetOrderStatus(ctx, c.GetAddress(), &unknown, nil); err != nil || status["status"] != "unknownOid" {
+		t.Errorf("Expected unknownOid, got %v (%v)", status, err)
+	}
+}
+
+func TestScheduledCancelFires(t *testing.T) {
+	srv := NewServer(Config{})
+	defer srv.Close()
+
+	var mu sync.Mutex
+	now := time.Now()
+	srv.Engine().SetClock(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+
+	ctx := context.Background()
+	c := newClient(t, srv, makerKey)
+	if _, err := c.Exchange().PlaceOrder(ctx, Limit("BTC", true, "49000", "1", types.TifGtc)); err != nil {
+		t.Fatalf("Failed to place order: %v", err)
+	}
+
+	at := now.Add(10 * time.Second)
+	if _, err := c.Exchange().ScheduleCancel(ctx, &at); err != nil {
+		t.Fatalf("Failed to schedule cancel: %v", err)
+	}
+	if open, _ := c.Info().GetOpenOrders(ctx, c.GetAddress()); len(open) != 1 {
+		t.Fatalf("Expected the order open before the deadline, got %+v", open)
+	}
+
+	mu.Lock()
+	now = now.Add(11 * time.Second)
+	mu.Unlock()
+
+	// The next request finds the deadline passed
+	if open, _ := c.Info().GetOpenOrders(ctx, c.GetAddress()); len(open) != 0 {
+		t.Errorf("Expected the scheduled cancel to clear the book, got %+v", open)
+	}
+	if _, ok := srv.ScheduledCancel(c.GetAddress()); ok {
+		t.Errorf("Expected the fired cancel to be cleared")
+	}
+}
+
+func TestWebSocketFeeds(t *testing.T) {
+	srv := NewServer(Config{})
+	defer srv.Close()
+
+	ctx := context.Background()
+	maker := newClient(t, srv, makerKey)
+	taker := newClient(t, srv, takerKey)
+
+	ws := websocket.NewManager(srv.WSURL)
+	if err := ws.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Disconnect()
+
+	books := make(chan types.L2BookData, 16)
+	if _, err := ws.SubscribeToL2Book("BTC", func(data types.L2BookData) error {
+		books <- data
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe to l2Book: %v", err)
+	}
+
+	fills := make(chan types.UserFillsData, 16)
+	if _, err := ws.SubscribeToUserFills(maker.GetAddress(), func(data types.UserFillsData) error {
+		fills <- data
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe to userFills: %v", err)
+	}
+
+	if book := receive(t, books); len(book.Levels) != 2 || len(book.Levels[0]) != 0 {
+		t.Errorf("Expected empty book snapshot, got %+v", book.Levels)
+	}
+	if snapshot := receive(t, fills); !snapshot.IsSnapshot {
+		t.Errorf("Expected userFills snapshot first")
+	}
+
+	if _, err := maker.Exchange().PlaceOrder(ctx, Limit("BTC", false, "51000", "1", types.TifGtc)); err != nil {
+		t.Fatalf("Failed to place ask: %v", err)
+	}
+	if book := receive(t, books); len(book.Levels[1]) != 1 {
+		t.Errorf("Expected one ask level, got %+v", book.Levels)
+	}
+
+	if _, err := taker.Exchange().PlaceOrder(ctx, Limit("BTC", true, "51000", "0.25", types.TifGtc)); err != nil {
+		t.Fatalf("Failed to place crossing bid: %v", err)
+	}
+	update := receive(t, fills)
+	if update.IsSnapshot || len(update.Fills) != 1 || !update.Fills[0].Sz.Equal(Dec("0.25")) {
+		t.Errorf("Expected a 0.25 maker fill, got %+v", update)
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for message")
+	}
+	var zero T
+	return zero
+}
@@ -0,0 +1,229 @@
+package hltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// hub serves the fake WebSocket endpoint and fans engine events out to
+// subscribed connections
+type hub struct {
+	server   *Server
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	conns map[*wsConn]struct{}
+}
+
+// wsConn is one client connection and its subscriptions
+type wsConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu   sync.Mutex
+	subs []types.WSSubscription
+}
+
+func newHub(server *Server) *hub {
+	return &hub{
+		server:   server,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		conns:    make(map[*wsConn]struct{}),
+	}
+}
+
+func (h *hub) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &wsConn{conn: conn}
+	h.mu.Lock()
+	h.conns[c] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, c)
+		h.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req types.WSRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			continue
+		}
+
+		switch req.Method {
+		case "ping":
+			c.send("pong", nil)
+		case "subscribe":
+			c.mu.Lock()
+			c.subs = append(c.subs, req.Subscription)
+			c.mu.Unlock()
+			c.send("subscriptionResponse", req)
+			h.snapshot(c, req.Subscription)
+		case "unsubscribe":
+			c.mu.Lock()
+			for i, sub := range c.subs {
+				if sameSubscription(sub, req.Subscription) {
+					c.subs = append(c.subs[:i], c.subs[i+1:]...)
+					break
+				}
+			}
+			c.mu.Unlock()
+			c.send("subscriptionResponse", req)
+		}
+	}
+}
+
+// snapshot sends the initial state for a new subscription
+func (h *hub) snapshot(c *wsConn, sub types.WSSubscription) {
+	engine := h.server.engine
+
+	switch sub.Type {
+	case "l2Book":
+		c.send("l2Book", engine.Book(sub.Coin, bookDepth))
+	case "allMids":
+		c.send("allMids", types.AllMidsData{Mids: engine.Mids()})
+	case "userFills":
+		user := strings.ToLower(sub.User)
+		fills := engine.Fills(user)
+		data := types.UserFillsData{IsSnapshot: true, User: user, Fills: make([]types.UserFillData, 0, len(fills))}
+		for _, fill := range fills {
+			data.Fills = append(data.Fills, userFill(user, fill))
+		}
+		c.send("userFills", data)
+	}
+}
+
+// publish converts an engine event into channel messages
+func (h *hub) publish(ev event) {
+	engine := h.server.engine
+
+	switch ev.kind {
+	case eventBook:
+		h.send("l2Book", ev.coin, "", engine.Book(ev.coin, bookDepth))
+		h.send("allMids", "", "", types.AllMidsData{Mids: engine.Mids()})
+	case eventTrade:
+		h.send("trades", ev.coin, "", []types.TradeData{ev.trade})
+	case eventFill:
+		h.send("userFills", "", ev.user, types.UserFillsData{
+			User:  ev.user,
+			Fills: []types.UserFillData{userFill(ev.user, ev.fill)},
+		})
+	case eventOrder:
+		h.send("orderUpdates", "", ev.user, []types.OrderUpdate{{
+			Order:           ev.order.BasicOrder(),
+			Status:          ev.status,
+			StatusTimestamp: ev.time,
+		}})
+	}
+}
+
+// send delivers data to connections subscribed to channel, filtered by
+// coin and user when given
+func (h *hub) send(channel, coin, user string, data interface{}) {
+	for _, c := range h.connections() {
+		if c.subscribed(channel, coin, user) {
+			c.send(channel, data)
+		}
+	}
+}
+
+// broadcast delivers data to every connection subscribed to channel
+func (h *hub) broadcast(channel string, data interface{}) {
+	h.send(channel, "", "", data)
+}
+
+func (h *hub) closeAll() {
+	for _, c := range h.connections() {
+		c.conn.Close()
+	}
+}
+
+func (h *hub) connections() []*wsConn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	conns := make([]*wsConn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (c *wsConn) subscribed(channel, coin, user string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sub := range c.subs {
+		if subscriptionChannel(sub.Type) != channel {
+			continue
+		}
+		if coin != "" && sub.Coin != "" && sub.Coin != coin {
+			continue
+		}
+		if user != "" && sub.User != "" && !strings.EqualFold(sub.User, user) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (c *wsConn) send(channel string, data interface{}) {
+	msg := map[string]interface{}{"channel": channel}
+	if data != nil {
+		msg["data"] = data
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.WriteJSON(msg)
+}
+
+// subscriptionChannel returns the channel a subscription type publishes on
+func subscriptionChannel(subType string) string {
+	if subType == "userEvents" {
+		return "user"
+	}
+	return subType
+}
+
+func sameSubscription(a, b types.WSSubscription) bool {
+	return a.Type == b.Type && a.Coin == b.Coin && strings.EqualFold(a.User, b.User) && a.Interval == b.Interval
+}
+
+func userFill(user string, fill types.Fill) types.UserFillData {
+	return types.UserFillData{
+		User:          user,
+		Coin:          fill.Coin,
+		Px:            fill.Px,
+		Sz:            fill.Sz,
+		Side:          fill.Side,
+		Time:          fill.Time,
+		StartPosition: fill.StartPosition,
+		Dir:           fill.Dir,
+		ClosedPnl:     fill.ClosedPnl,
+		Hash:          fill.Hash,
+		Oid:           fill.Oid,
+		Crossed:       fill.Crossed,
+		Fee:           fill.Fee,
+		Tid:           fill.Tid,
+		FeeToken:      fill.FeeToken,
+		Cloid:         fill.Cloid,
+	}
+}
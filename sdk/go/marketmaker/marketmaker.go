@@ -0,0 +1,475 @@
+// Package marketmaker is a reference two-sided quoting strategy for the
+// strategy runtime.
+package marketmaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orderbook"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/strategy"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Fair price sources
+const (
+	FairMid        = "mid"
+	FairMicroprice = "microprice"
+)
+
+// volTimer is the runtime timer that samples volatility
+const volTimer = "marketmaker.volatility"
+
+// retireBooks is how many book updates a moved or pulled quote is still
+// excluded from, since snapshots sent before the change may show it
+const retireBooks = 3
+
+// Config configures a MarketMaker. Spreads, spacing and thresholds are
+// fractions of the fair price, e.g. 0.001 for 10 bps.
+type Config struct {
+	Asset            types.AssetInfo // Market to quote; resolve with InfoClient.GetAssetInfo
+	Levels           int             // Quotes per side (default 1)
+	Size             decimal.Decimal // Size of each quote
+	Spread           float64         // Distance of the first level from the fair price
+	LevelSpacing     float64         // Extra distance of each further level
+	MaxPosition      decimal.Decimal // Absolute inventory limit; quotes that could exceed it are shrunk or dropped
+	Skew             float64         // Shift of the ladder at full inventory, in multiples of the spread, toward reducing it
+	RequoteThreshold float64         // Price drift a resting quote tolerates before it is moved (default Spread/2)
+	Fair             string          // FairMid or FairMicroprice (default FairMid)
+	VolInterval      time.Duration   // How often the mid is sampled for volatility (default 1s)
+	VolHalfLife      time.Duration   // Half-life of the volatility estimate (default 1m)
+	VolMultiplier    float64         // Spread added per unit of volatility; zero disables widening
+}
+
+// Quote is one order of the ladder. Level 0 is closest to the fair price.
+type Quote struct {
+	IsBuy bool
+	Level int
+	Px    decimal.Decimal
+	Sz    decimal.Decimal
+}
+
+// slot is a resting quote
+type slot struct {
+	quote Quote
+	cloid string
+}
+
+// retiredQuote is a quote no longer resting that a stale book may still show
+type retiredQuote struct {
+	quote Quote
+	books int
+}
+
+// slotKey identifies a ladder position
+type slotKey struct {
+	isBuy bool
+	level int
+}
+
+// MarketMaker quotes a ladder of post-only orders on both sides of a fair
+// price taken from the book. The ladder leans against inventory, widens
+// with volatility and stops growing a side at the inventory limit. Quotes
+// move with BatchModify only once they drift past the requote threshold,
+// which keeps churn down.
+//
+// It is a strategy.Strategy: run it with strategy.NewRuntime for live or
+// paper trading, or strategy.Backtest. Callbacks must not be called
+// concurrently, which the runtime guarantees.
+type MarketMaker struct {
+	strategy.Base
+	config Config
+	rt     *strategy.Runtime
+	book   *orderbook.Book
+	slots  map[slotKey]*slot
+	// retired quotes are excluded from the next few books as well
+	retired []retiredQuote
+
+	lastMid  float64
+	variance float64
+	samples  int
+}
+
+var _ strategy.Strategy = (*MarketMaker)(nil)
+
+// NewMarketMaker creates a market maker for config.Asset
+func NewMarketMaker(config Config) *MarketMaker {
+	if config.Levels <= 0 {
+		config.Levels = 1
+	}
+	if config.RequoteThreshold <= 0 {
+		config.RequoteThreshold = config.Spread / 2
+	}
+	if config.Fair == "" {
+		config.Fair = FairMid
+	}
+	if config.VolInterval <= 0 {
+		config.VolInterval = time.Second
+	}
+	if config.VolHalfLife <= 0 {
+		config.VolHalfLife = time.Minute
+	}
+
+	return &MarketMaker{
+		config: config,
+		book:   orderbook.NewBook(config.Asset.Coin),
+		slots:  make(map[slotKey]*slot),
+	}
+}
+
+// Ladder returns the quotes wanted at fair price fair with the given
+// signed position and volatility, rounded to the asset's tick and lot
+// sizes. Bids come first, then asks, each closest first.
+func (m *MarketMaker) Ladder(fair, position decimal.Decimal, volatility float64) []Quote {
+	c := m.config
+	fairF, _ := fair.Float64()
+	if fairF <= 0 {
+		return nil
+	}
+
+	inventory := 0.0
+	if c.MaxPosition.IsPositive() {
+		inventory, _ = position.Div(c.MaxPosition).Float64()
+		inventory = math.Max(-1, math.Min(1, inventory))
+	}
+	half := c.Spread + c.VolMultiplier*volatility
+	center := fairF * (1 - inventory*c.Skew*half)
+
+	var quotes []Quote
+	for _, isBuy := range []bool{true, false} {
+		room := decimal.NewFromInt(math.MaxInt32)
+		if c.MaxPosition.IsPositive() {
+			if isBuy {
+				room = c.MaxPosition.Sub(position)
+			} else {
+				room = c.MaxPosition.Add(position)
+			}
+		}
+
+		for level := 0; level < c.Levels; level++ {
+			sz := decimal.Min(c.Size, room).Truncate(int32(c.Asset.SzDecimals))
+			if !sz.IsPositive() {
+				break
+			}
+			room = room.Sub(sz)
+
+			offset := fairF * (half + float64(level)*c.LevelSpacing)
+			px := center - offset
+			if !isBuy {
+				px = center + offset
+			}
+			if px <= 0 {
+				break
+			}
+			quotes = append(quotes, Quote{
+				IsBuy: isBuy,
+				Level: level,
+				Px:    utils.RoundPrice(decimal.NewFromFloat(px), c.Asset.SzDecimals, c.Asset.IsSpot),
+				Sz:    sz,
+			})
+		}
+	}
+	return quotes
+}
+
+// Quotes returns the resting quotes, bids then asks, closest first
+func (m *MarketMaker) Quotes() []Quote {
+	quotes := make([]Quote, 0, len(m.slots))
+	for _, s := range m.slots {
+		quotes = append(quotes, s.quote)
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		if quotes[i].IsBuy != quotes[j].IsBuy {
+			return quotes[i].IsBuy
+		}
+		return quotes[i].Level < quotes[j].Level
+	})
+	return quotes
+}
+
+// Fair returns the fair price from the current book
+func (m *MarketMaker) Fair() (decimal.Decimal, bool) {
+	if m.config.Fair == FairMicroprice {
+		return m.book.Microprice()
+	}
+	return m.book.Mid()
+}
+
+// Volatility returns the estimated standard deviation of the mid's log
+// return per VolInterval
+func (m *MarketMaker) Volatility() float64 {
+	return math.Sqrt(m.variance)
+}
+
+// OnStart starts volatility sampling
+func (m *MarketMaker) OnStart(ctx context.Context, rt *strategy.Runtime) error {
+	m.rt = rt
+	if m.config.VolMultiplier > 0 {
+		rt.SetTimer(volTimer, m.config.VolInterval)
+	}
+	return nil
+}
+
+// OnBook requotes against the new book
+func (m *MarketMaker) OnBook(ctx context.Context, book types.L2BookData) error {
+	if book.Coin != m.config.Asset.Coin {
+		return nil
+	}
+	ob, err := book.OrderBook()
+	if err != nil {
+		return fmt.Errorf("failed to parse book: %w", err)
+	}
+	ob.Bids = m.withoutQuotes(ob.Bids, true)
+	ob.Asks = m.withoutQuotes(ob.Asks, false)
+	if _, err := m.book.Apply(*ob); err != nil {
+		return fmt.Errorf("failed to apply book: %w", err)
+	}
+
+	kept := m.retired[:0]
+	for _, r := range m.retired {
+		if r.books++; r.books < retireBooks {
+			kept = append(kept, r)
+		}
+	}
+	m.retired = kept
+	return m.Requote(ctx)
+}
+
+// withoutQuotes removes the size of our own quotes, resting or just
+// retired, from one side of the book, so the fair price does not chase them
+func (m *MarketMaker) withoutQuotes(levels []types.OrderBookLevel, isBuy bool) []types.OrderBookLevel {
+	own := make(map[string]decimal.Decimal)
+	add := func(q Quote) {
+		if q.IsBuy == isBuy {
+			px := q.Px.String()
+			own[px] = own[px].Add(q.Sz)
+		}
+	}
+	for _, s := range m.slots {
+		add(s.quote)
+	}
+	for _, r := range m.retired {
+		add(r.quote)
+	}
+	if len(own) == 0 {
+		return levels
+	}
+
+	kept := make([]types.OrderBookLevel, 0, len(levels))
+	for _, level := range levels {
+		if sz, ok := own[level.Price.String()]; ok {
+			level.Size = level.Size.Sub(sz)
+			if !level.Size.IsPositive() {
+				continue
+			}
+		}
+		kept = append(kept, level)
+	}
+	return kept
+}
+
+// OnFill requotes for the new inventory
+func (m *MarketMaker) OnFill(ctx context.Context, fill types.UserFillData) error {
+	if fill.Coin != m.config.Asset.Coin {
+		return nil
+	}
+	return m.Requote(ctx)
+}
+
+// OnOrderUpdate forgets quotes that are filled, canceled or rejected
+func (m *MarketMaker) OnOrderUpdate(ctx context.Context, event orders.Event) error {
+	if !event.Order.State.Terminal() {
+		return nil
+	}
+	for key, s := range m.slots {
+		if s.cloid == event.Order.Cloid {
+			m.retire(key)
+		}
+	}
+	return nil
+}
+
+// OnTimer samples the mid for the volatility estimate
+func (m *MarketMaker) OnTimer(ctx context.Context, name string, now time.Time) error {
+	if name != volTimer {
+		return nil
+	}
+	mid, ok := m.book.Mid()
+	if !ok {
+		return nil
+	}
+
+	midF, _ := mid.Float64()
+	if m.lastMid > 0 {
+		r := math.Log(midF / m.lastMid)
+		alpha := 1 - math.Exp(-math.Ln2*float64(m.config.VolInterval)/float64(m.config.VolHalfLife))
+		if m.samples == 0 {
+			m.variance = r * r
+		} else {
+			m.variance = (1-alpha)*m.variance + alpha*r*r
+		}
+		m.samples++
+	}
+	m.lastMid = midF
+	return nil
+}
+
+// OnStop pulls every quote
+func (m *MarketMaker) OnStop(ctx context.Context) error {
+	if len(m.slots) == 0 {
+		return nil
+	}
+	cloids := make([]string, 0, len(m.slots))
+	for _, s := range m.slots {
+		cloids = append(cloids, s.cloid)
+	}
+	m.slots = make(map[slotKey]*slot)
+
+	if _, err := m.rt.Trader().CancelByCloid(ctx, m.config.Asset.Coin, cloids...); err != nil {
+		return fmt.Errorf("failed to cancel quotes: %w", err)
+	}
+	return nil
+}
+
+// Requote brings the resting quotes in line with the ladder. New levels are
+// placed in one batch, drifted ones moved in one BatchModify and unwanted
+// ones canceled. Levels that would cross the book are left out.
+func (m *MarketMaker) Requote(ctx context.Context) error {
+	fair, ok := m.Fair()
+	if !ok {
+		return nil
+	}
+	position := decimal.Zero
+	if p, ok := m.rt.Positions().Position(m.config.Asset.Coin); ok {
+		position = p.Szi
+	}
+	bestBid, hasBid := m.book.BestBid()
+	bestAsk, hasAsk := m.book.BestAsk()
+	fairF, _ := fair.Float64()
+
+	var (
+		places    []types.OrderRequest
+		placeKeys []slotKey
+		modifies  []types.ModifyRequest
+		modKeys   []slotKey
+		cancels   []string
+	)
+	wanted := make(map[slotKey]bool)
+
+	for _, q := range m.Ladder(fair, position, m.Volatility()) {
+		if q.IsBuy && hasAsk && q.Px.GreaterThanOrEqual(bestAsk.Price) ||
+			!q.IsBuy && hasBid && q.Px.LessThanOrEqual(bestBid.Price) {
+			continue
+		}
+		key := slotKey{q.IsBuy, q.Level}
+		wanted[key] = true
+
+		current, resting := m.slots[key]
+		if resting {
+			drift, _ := current.quote.Px.Sub(q.Px).Abs().Float64()
+			if drift/fairF <= m.config.RequoteThreshold && current.quote.Sz.Equal(q.Sz) {
+				continue
+			}
+		}
+
+		order, err := m.order(q)
+		if err != nil {
+			return err
+		}
+		if resting {
+			cloid := current.cloid
+			modifies = append(modifies, types.ModifyRequest{Cloid: &cloid, Order: order})
+			modKeys = append(modKeys, key)
+		} else {
+			places = append(places, order)
+			placeKeys = append(placeKeys, key)
+		}
+	}
+
+	for key, s := range m.slots {
+		if !wanted[key] {
+			cancels = append(cancels, s.cloid)
+			m.retire(key)
+		}
+	}
+
+	var errs []error
+	if len(modifies) > 0 {
+		resp, err := m.rt.Trader().BatchModify(ctx, modifies)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to move quotes: %w", err))
+		} else {
+			sent := make([]types.OrderRequest, len(modifies))
+			for i, modify := range modifies {
+				sent[i] = modify.Order
+			}
+			// A failed modify may leave the old order resting, so pull it
+			for _, i := range m.record(modKeys, sent, resp) {
+				cancels = append(cancels, *modifies[i].Cloid)
+			}
+		}
+	}
+	if len(places) > 0 {
+		resp, err := m.rt.Trader().PlaceOrders(ctx, places, types.GroupingNA)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to place quotes: %w", err))
+		} else {
+			m.record(placeKeys, places, resp)
+		}
+	}
+	if len(cancels) > 0 {
+		if _, err := m.rt.Trader().CancelByCloid(ctx, m.config.Asset.Coin, cancels...); err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel quotes: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// order builds a post-only order for q with a fresh cloid
+func (m *MarketMaker) order(q Quote) (types.OrderRequest, error) {
+	b := client.Sell(m.config.Asset.Coin)
+	if q.IsBuy {
+		b = client.Buy(m.config.Asset.Coin)
+	}
+	order, err := b.Size(q.Sz).Limit(q.Px).PostOnly().WithCloid().Build(&m.config.Asset)
+	if err != nil {
+		return types.OrderRequest{}, fmt.Errorf("failed to build quote: %w", err)
+	}
+	return order, nil
+}
+
+// record stores the quotes that now rest and returns the indexes of those
+// that did not
+func (m *MarketMaker) record(keys []slotKey, sent []types.OrderRequest, resp *types.OrderResponse) []int {
+	statuses := resp.Response.Data.Statuses
+	var failed []int
+	for i, key := range keys {
+		m.retire(key)
+		if i >= len(statuses) || statuses[i].Resting == nil {
+			failed = append(failed, i)
+			continue
+		}
+		order := sent[i]
+		m.slots[key] = &slot{
+			quote: Quote{IsBuy: key.isBuy, Level: key.level, Px: order.LimitPx, Sz: order.Sz},
+			cloid: *order.Cloid,
+		}
+	}
+	return failed
+}
+
+// retire drops the quote at key, if any, keeping it out of the next books
+func (m *MarketMaker) retire(key slotKey) {
+	if s, ok := m.slots[key]; ok {
+		m.retired = append(m.retired, retiredQuote{quote: s.quote})
+		delete(m.slots, key)
+	}
+}
@@ -0,0 +1,168 @@
+package marketmaker
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/strategy"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+)
+
+const testKey = "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestLadder(t *testing.T) {
+	m := NewMarketMaker(Config{
+		Asset:         types.AssetInfo{Coin: "BTC", SzDecimals: 3},
+		Levels:        2,
+		Size:          hltest.Dec("1"),
+		Spread:        0.01,
+		LevelSpacing:  0.005,
+		MaxPosition:   hltest.Dec("3"),
+		Skew:          1,
+		VolMultiplier: 2,
+	})
+
+	tests := []struct {
+		name       string
+		position   string
+		volatility float64
+		bids, asks []string // "px@sz", closest first
+	}{
+		{"flat", "0", 0, []string{"99@1", "98.5@1"}, []string{"101@1", "101.5@1"}},
+		{"long skews down and caps bids", "1.5", 0, []string{"98.5@1", "98@0.5"}, []string{"100.5@1", "101@1"}},
+		{"at the limit", "3", 0, nil, []string{"100@1", "100.5@1"}},
+		{"short at the limit", "-3", 0, []string{"100@1", "99.5@1"}, nil},
+		{"volatility widens", "0", 0.005, []string{"98@1", "97.5@1"}, []string{"102@1", "102.5@1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bids, asks []string
+			for _, q := range m.Ladder(hltest.Dec("100"), hltest.Dec(tt.position), tt.volatility) {
+				s := q.Px.String() + "@" + q.Sz.String()
+				if q.IsBuy {
+					bids = append(bids, s)
+				} else {
+					asks = append(asks, s)
+				}
+			}
+			if !equal(bids, tt.bids) || !equal(asks, tt.asks) {
+				t.Errorf("Expected bids %v asks %v, got %v %v", tt.bids, tt.asks, bids, asks)
+			}
+		})
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// quotes lists an account's open orders as "B@px" or "A@px", best first
+func quotes(srv *hltest.Server, user string) []string {
+	open := srv.Engine().OpenOrders(user)
+	sort.Slice(open, func(i, j int) bool {
+		if open[i].Side != open[j].Side {
+			return open[i].Side == "B"
+		}
+		if open[i].Side == "B" {
+			return open[i].LimitPx.GreaterThan(open[j].LimitPx)
+		}
+		return open[i].LimitPx.LessThan(open[j].LimitPx)
+	})
+	out := make([]string, len(open))
+	for i, o := range open {
+		out[i] = o.Side + "@" + o.LimitPx.String()
+	}
+	return out
+}
+
+func countModifies(srv *hltest.Server) int {
+	n := 0
+	for _, req := range srv.Requests() {
+		if action, ok := req.Body["action"].(map[string]interface{}); ok && action["type"] == "batchModify" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMarketMakerAgainstMock(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+	engine := srv.Engine()
+	engine.Place("0xmaker", hltest.Limit("BTC", true, "49900", "5", types.TifGtc))
+	engine.Place("0xmaker", hltest.Limit("BTC", false, "50100", "5", types.TifGtc))
+
+	address, _ := utils.GetAddressFromPrivateKey(testKey)
+	c := client.NewClient(srv.URL, srv.WSURL, testKey)
+	c.SetAddress(address)
+	asset, err := c.Info().GetAssetInfo(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("Failed to resolve BTC: %v", err)
+	}
+
+	ws := websocket.NewManager(srv.WSURL)
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Disconnect()
+
+	mm := NewMarketMaker(Config{
+		Asset:            *asset,
+		Levels:           2,
+		Size:             hltest.Dec("0.1"),
+		Spread:           0.001,
+		LevelSpacing:     0.001,
+		MaxPosition:      hltest.Dec("0.2"),
+		Skew:             1,
+		RequoteThreshold: 0.0003,
+	})
+	rt := strategy.NewRuntime(strategy.Live(c), ws, strategy.Config{User: address, Coins: []string{"BTC"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- rt.Run(ctx, mm) }()
+	defer cancel()
+
+	// Two levels each side of the 50000 mid
+	hltest.WaitFor(t, []string{"B@49950", "B@49900", "A@50050", "A@50100"}, func() []string { return quotes(srv, address) })
+
+	// A move inside the threshold leaves the quotes alone
+	engine.Place("0xmaker", hltest.Limit("BTC", true, "49910", "1", types.TifGtc))
+	time.Sleep(200 * time.Millisecond)
+	if n := countModifies(srv); n != 0 {
+		t.Errorf("Expected no requotes for a small move, got %d", n)
+	}
+
+	// A fill to half the inventory limit leaves room for one bid and leans
+	// the ladder half a spread down from the 50005 mid, moving both asks in
+	// one batch
+	engine.Place("0xtaker", hltest.Limit("BTC", false, "49950", "0.1", types.TifIoc))
+	hltest.WaitFor(t, []string{"B@49930", "A@50030", "A@50080"}, func() []string { return quotes(srv, address) })
+	time.Sleep(200 * time.Millisecond)
+	if n := countModifies(srv); n != 1 {
+		t.Errorf("Expected a single batchModify, got %d", n)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected a clean stop on cancel, got %v", err)
+	}
+	if open := engine.OpenOrders(address); len(open) != 0 {
+		t.Errorf("Expected quotes pulled on shutdown, got %+v", open)
+	}
+}
@@ -0,0 +1,216 @@
+package orderbook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// Tier is one server-side aggregation level feeding an Aggregator. A nil
+// NSigFigs requests full precision.
+type Tier struct {
+	NSigFigs *int
+	Mantissa *int
+}
+
+// Aggregator combines books at several aggregation levels into a single
+// consolidated depth view: fine levels near the touch and coarse levels
+// further out. Tiers must be ordered from finest to coarsest.
+type Aggregator struct {
+	coin  string
+	tiers []Tier
+	mu    sync.RWMutex
+	books []*types.OrderBook
+}
+
+// NewAggregator creates an aggregator for coin over the given tiers
+func NewAggregator(coin string, tiers ...Tier) *Aggregator {
+	return &Aggregator{
+		coin:  coin,
+		tiers: tiers,
+		books: make([]*types.OrderBook, len(tiers)),
+	}
+}
+
+// Tiers returns the configured tiers
+func (a *Aggregator) Tiers() []Tier {
+	return append([]Tier(nil), a.tiers...)
+}
+
+// Update stores the latest snapshot for the tier at index tier
+func (a *Aggregator) Update(tier int, ob types.OrderBook) error {
+	if tier < 0 || tier >= len(a.tiers) {
+		return fmt.Errorf("tier %d out of range", tier)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if current := a.books[tier]; current != nil && ob.Time < current.Time {
+		return nil
+	}
+	a.books[tier] = &ob
+	return nil
+}
+
+// Refresh fetches every tier over REST
+func (a *Aggregator) Refresh(ctx context.Context, info *client.InfoClient) error {
+	for i, tier := range a.tiers {
+		book, err := info.GetL2BookAggregated(ctx, a.coin, tier.NSigFigs, tier.Mantissa)
+		if err != nil {
+			return fmt.Errorf("failed to refresh tier %d: %w", i, err)
+		}
+
+		ob, err := book.OrderBook()
+		if err != nil {
+			return fmt.Errorf("failed to parse tier %d: %w", i, err)
+		}
+
+		if err := a.Update(i, *ob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe streams every tier over WebSocket. A connection can only carry
+// one aggregation per coin, so tiers are streamed through a Pool, which
+// places each tier on its own connection.
+func (a *Aggregator) Subscribe(pool *websocket.Pool) ([]string, error) {
+	subIDs := make([]string, 0, len(a.tiers))
+	for i, tier := range a.tiers {
+		if err := utils.ValidateL2Aggregation(tier.NSigFigs, tier.Mantissa); err != nil {
+			return subIDs, fmt.Errorf("invalid tier %d: %w", i, err)
+		}
+
+		tierIndex := i
+		sub := types.WSSubscription{
+			Type:     "l2Book",
+			Coin:     a.coin,
+			NSigFigs: tier.NSigFigs,
+			Mantissa: tier.Mantissa,
+		}
+		subID, err := pool.Subscribe(sub, func(raw json.RawMessage) error {
+			var data types.L2BookData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			ob, err := data.OrderBook()
+			if err != nil {
+				return err
+			}
+			return a.Update(tierIndex, *ob)
+		})
+		if err != nil {
+			return subIDs, fmt.Errorf("failed to subscribe tier %d: %w", i, err)
+		}
+		subIDs = append(subIDs, subID)
+	}
+
+	return subIDs, nil
+}
+
+// Depth returns the consolidated book. Each tier contributes only the levels
+// beyond the deepest price covered by finer tiers, sized so that cumulative
+// depth matches the coarser tier.
+func (a *Aggregator) Depth() types.OrderBook {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	consolidated := types.OrderBook{Coin: a.coin}
+	var bidTiers, askTiers [][]types.OrderBookLevel
+	for _, book := range a.books {
+		if book == nil {
+			continue
+		}
+		if book.Time > consolidated.Time {
+			consolidated.Time = book.Time
+		}
+		bidTiers = append(bidTiers, book.Bids)
+		askTiers = append(askTiers, book.Asks)
+	}
+
+	consolidated.Bids = consolidate(Bid, bidTiers)
+	consolidated.Asks = consolidate(Ask, askTiers)
+	return consolidated
+}
+
+// consolidate merges one side of several tiers, finest first
+func consolidate(side Side, tiers [][]types.OrderBookLevel) []types.OrderBookLevel {
+	var merged []types.OrderBookLevel
+	emitted := decimal.Zero
+
+	for _, levels := range tiers {
+		if len(merged) == 0 {
+			merged = append(merged, levels...)
+			for _, level := range levels {
+				emitted = emitted.Add(level.Size)
+			}
+			continue
+		}
+
+		boundary := merged[len(merged)-1].Price
+		cumulative := decimal.Zero
+		for _, level := range levels {
+			cumulative = cumulative.Add(level.Size)
+			if !beyond(side, level.Price, boundary) {
+				continue
+			}
+
+			// The first coarse bucket past the boundary may overlap finer
+			// levels, so size it from the cumulative difference
+			size := cumulative.Sub(emitted)
+			if !size.IsPositive() {
+				continue
+			}
+			merged = append(merged, types.OrderBookLevel{
+				Price:     level.Price,
+				Size:      size,
+				NumOrders: level.NumOrders,
+			})
+			emitted = cumulative
+		}
+	}
+
+	return merged
+}
+
+// beyond reports whether px is further from the touch than boundary
+func beyond(side Side, px, boundary decimal.Decimal) bool {
+	if side == Bid {
+		return px.LessThan(boundary)
+	}
+	return px.GreaterThan(boundary)
+}
+
+// Bucket groups levels into price buckets of width step for display. Bids
+// round down and asks round up so that buckets never cross the spread.
+func Bucket(side Side, levels []types.OrderBookLevel, step decimal.Decimal) []types.OrderBookLevel {
+	if !step.IsPositive() {
+		return append([]types.OrderBookLevel(nil), levels...)
+	}
+
+	var buckets []types.OrderBookLevel
+	for _, level := range levels {
+		px := level.Price.Div(step).Floor().Mul(step)
+		if side == Ask {
+			px = level.Price.Div(step).Ceil().Mul(step)
+		}
+
+		if n := len(buckets); n > 0 && buckets[n-1].Price.Equal(px) {
+			buckets[n-1].Size = buckets[n-1].Size.Add(level.Size)
+			buckets[n-1].NumOrders += level.NumOrders
+			continue
+		}
+		buckets = append(buckets, types.OrderBookLevel{Price: px, Size: level.Size, NumOrders: level.NumOrders})
+	}
+
+	return buckets
+}
@@ -0,0 +1,306 @@
+package orderbook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// Side identifies one side of the book
+type Side int
+
+const (
+	// Bid is the buy side of the book
+	Bid Side = iota
+	// Ask is the sell side of the book
+	Ask
+)
+
+func (s Side) String() string {
+	if s == Bid {
+		return "bid"
+	}
+	return "ask"
+}
+
+// Impact describes the estimated cost of taking liquidity for a given size
+type Impact struct {
+	Size     decimal.Decimal // Size actually available within the book
+	VWAP     decimal.Decimal // Volume weighted average fill price
+	WorstPx  decimal.Decimal // Price of the deepest level touched
+	Levels   int             // Number of levels consumed
+	Slippage decimal.Decimal // Fractional distance of the VWAP from the mid
+	Complete bool            // Whether the book was deep enough for the full size
+}
+
+// Book is a thread-safe typed order book for a single coin
+type Book struct {
+	mu      sync.RWMutex
+	coin    string
+	time    int64
+	bids    []types.OrderBookLevel
+	asks    []types.OrderBookLevel
+	updated time.Time
+}
+
+// NewBook creates an empty book for a coin
+func NewBook(coin string) *Book {
+	return &Book{coin: coin}
+}
+
+// Coin returns the coin this book tracks
+func (b *Book) Coin() string {
+	return b.coin
+}
+
+// Apply replaces the book contents with a snapshot. Snapshots older than the
+// current book are ignored and reported as not applied.
+func (b *Book) Apply(ob types.OrderBook) (bool, error) {
+	if ob.Coin != "" && ob.Coin != b.coin {
+		return false, fmt.Errorf("snapshot for %s applied to %s book", ob.Coin, b.coin)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ob.Time < b.time {
+		return false, nil
+	}
+
+	b.time = ob.Time
+	b.bids = append([]types.OrderBookLevel(nil), ob.Bids...)
+	b.asks = append([]types.OrderBookLevel(nil), ob.Asks...)
+	b.updated = time.Now()
+
+	return true, nil
+}
+
+// ApplyL2 parses and applies an l2Book payload
+func (b *Book) ApplyL2(data types.L2BookData) (bool, error) {
+	ob, err := data.OrderBook()
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s book: %w", data.Coin, err)
+	}
+	return b.Apply(*ob)
+}
+
+// Time returns the exchange timestamp (ms) of the current snapshot
+func (b *Book) Time() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.time
+}
+
+// UpdatedAt returns the local time the last snapshot was applied
+func (b *Book) UpdatedAt() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.updated
+}
+
+// Snapshot returns a copy of the current book
+func (b *Book) Snapshot() types.OrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return types.OrderBook{
+		Coin: b.coin,
+		Time: b.time,
+		Bids: append([]types.OrderBookLevel(nil), b.bids...),
+		Asks: append([]types.OrderBookLevel(nil), b.asks...),
+	}
+}
+
+// Levels returns a copy of up to depth levels on one side, or all levels
+// when depth is zero
+func (b *Book) Levels(side Side, depth int) []types.OrderBookLevel {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	levels := b.side(side)
+	if depth > 0 && depth < len(levels) {
+		levels = levels[:depth]
+	}
+	return append([]types.OrderBookLevel(nil), levels...)
+}
+
+// BestBid returns the highest bid
+func (b *Book) BestBid() (types.OrderBookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.bids)
+}
+
+// BestAsk returns the lowest ask
+func (b *Book) BestAsk() (types.OrderBookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.asks)
+}
+
+// Spread returns the difference between the best ask and best bid
+func (b *Book) Spread() (decimal.Decimal, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bid, okBid := top(b.bids)
+	ask, okAsk := top(b.asks)
+	if !okBid || !okAsk {
+		return decimal.Zero, false
+	}
+	return ask.Price.Sub(bid.Price), true
+}
+
+// Mid returns the midpoint between the best bid and best ask
+func (b *Book) Mid() (decimal.Decimal, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.mid()
+}
+
+// Microprice returns the top-of-book price weighted by the opposite side's
+// size, which leans towards the side more likely to be traded through
+func (b *Book) Microprice() (decimal.Decimal, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bid, okBid := top(b.bids)
+	ask, okAsk := top(b.asks)
+	if !okBid || !okAsk {
+		return decimal.Zero, false
+	}
+
+	total := bid.Size.Add(ask.Size)
+	if total.IsZero() {
+		return bid.Price.Add(ask.Price).Div(decimal.NewFromInt(2)), true
+	}
+
+	return bid.Price.Mul(ask.Size).Add(ask.Price.Mul(bid.Size)).Div(total), true
+}
+
+// DepthAt returns the size resting at exactly px on one side
+func (b *Book) DepthAt(side Side, px decimal.Decimal) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, level := range b.side(side) {
+		if level.Price.Equal(px) {
+			return level.Size
+		}
+	}
+	return decimal.Zero
+}
+
+// DepthWithin returns the cumulative size on one side from the touch up to
+// and including px
+func (b *Book) DepthWithin(side Side, px decimal.Decimal) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	total := decimal.Zero
+	for _, level := range b.side(side) {
+		if (side == Bid && level.Price.LessThan(px)) || (side == Ask && level.Price.GreaterThan(px)) {
+			break
+		}
+		total = total.Add(level.Size)
+	}
+	return total
+}
+
+// PriceForDepth returns the price level at which the cumulative size on one
+// side first reaches size
+func (b *Book) PriceForDepth(side Side, size decimal.Decimal) (decimal.Decimal, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	total := decimal.Zero
+	for _, level := range b.side(side) {
+		total = total.Add(level.Size)
+		if total.GreaterThanOrEqual(size) {
+			return level.Price, true
+		}
+	}
+	return decimal.Zero, false
+}
+
+// VWAP estimates the market impact of an aggressive order of the given size.
+// Buys walk the asks and sells walk the bids.
+func (b *Book) VWAP(isBuy bool, size decimal.Decimal) (Impact, error) {
+	if !size.IsPositive() {
+		return Impact{}, fmt.Errorf("size must be positive")
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	levels := b.bids
+	if isBuy {
+		levels = b.asks
+	}
+	if len(levels) == 0 {
+		return Impact{}, fmt.Errorf("no liquidity on %s book", b.coin)
+	}
+
+	var impact Impact
+	remaining := size
+	notional := decimal.Zero
+	for _, level := range levels {
+		take := decimal.Min(remaining, level.Size)
+		notional = notional.Add(take.Mul(level.Price))
+		impact.Size = impact.Size.Add(take)
+		impact.WorstPx = level.Price
+		impact.Levels++
+		remaining = remaining.Sub(take)
+		if !remaining.IsPositive() {
+			break
+		}
+	}
+
+	impact.Complete = !remaining.IsPositive()
+	if impact.Size.IsPositive() {
+		impact.VWAP = notional.Div(impact.Size)
+	}
+	if mid, ok := b.mid(); ok && mid.IsPositive() {
+		impact.Slippage = impact.VWAP.Sub(mid).Abs().Div(mid)
+	}
+
+	return impact, nil
+}
+
+// IsStale reports whether the snapshot's exchange timestamp is older than
+// maxAge relative to now. An empty book is always stale.
+func (b *Book) IsStale(maxAge time.Duration, now time.Time) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.time == 0 {
+		return true
+	}
+	return now.Sub(time.UnixMilli(b.time)) > maxAge
+}
+
+func (b *Book) side(side Side) []types.OrderBookLevel {
+	if side == Bid {
+		return b.bids
+	}
+	return b.asks
+}
+
+func (b *Book) mid() (decimal.Decimal, bool) {
+	bid, okBid := top(b.bids)
+	ask, okAsk := top(b.asks)
+	if !okBid || !okAsk {
+		return decimal.Zero, false
+	}
+	return bid.Price.Add(ask.Price).Div(decimal.NewFromInt(2)), true
+}
+
+func top(levels []types.OrderBookLevel) (types.OrderBookLevel, bool) {
+	if len(levels) == 0 {
+		return types.OrderBookLevel{}, false
+	}
+	return levels[0], true
+}
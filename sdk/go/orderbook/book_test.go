@@ -0,0 +1,207 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+func level(px, sz string) types.OrderBookLevel {
+	return types.OrderBookLevel{
+		Price:     decimal.RequireFromString(px),
+		Size:      decimal.RequireFromString(sz),
+		NumOrders: 1,
+	}
+}
+
+func testBook(t *testing.T) *Book {
+	book := NewBook("ETH")
+	_, err := book.Apply(types.OrderBook{
+		Coin: "ETH",
+		Time: 1000,
+		Bids: []types.OrderBookLevel{level("99", "1"), level("98", "2"), level("97", "5")},
+		Asks: []types.OrderBookLevel{level("101", "3"), level("102", "1"), level("103", "4")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply snapshot: %v", err)
+	}
+	return book
+}
+
+func TestApplyL2(t *testing.T) {
+	data := types.L2BookData{
+		Coin: "ETH",
+		Time: 1700000000000,
+		Levels: [][]interface{}{
+			{map[string]interface{}{"px": "99.5", "sz": "1.2", "n": 3}},
+			{map[string]interface{}{"px": "100.5", "sz": "0.8", "n": 1}},
+		},
+	}
+
+	book := NewBook("ETH")
+	applied, err := book.ApplyL2(data)
+	if err != nil || !applied {
+		t.Fatalf("Expected snapshot to apply, got applied=%v err=%v", applied, err)
+	}
+
+	bid, _ := book.BestBid()
+	if !bid.Price.Equal(decimal.RequireFromString("99.5")) || bid.NumOrders != 3 {
+		t.Errorf("Unexpected best bid: %+v", bid)
+	}
+
+	// Older snapshots must not overwrite newer state
+	data.Time--
+	applied, err = book.ApplyL2(data)
+	if err != nil || applied {
+		t.Errorf("Expected stale snapshot to be ignored, got applied=%v err=%v", applied, err)
+	}
+}
+
+func TestTopOfBook(t *testing.T) {
+	book := testBook(t)
+
+	spread, _ := book.Spread()
+	if !spread.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("Expected spread 2, got %s", spread)
+	}
+
+	mid, _ := book.Mid()
+	if !mid.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected mid 100, got %s", mid)
+	}
+
+	// (99*3 + 101*1) / 4
+	micro, _ := book.Microprice()
+	if !micro.Equal(decimal.RequireFromString("99.5")) {
+		t.Errorf("Expected microprice 99.5, got %s", micro)
+	}
+
+	if _, ok := NewBook("BTC").Mid(); ok {
+		t.Error("Expected empty book to have no mid")
+	}
+}
+
+func TestDepth(t *testing.T) {
+	book := testBook(t)
+
+	tests := []struct {
+		name string
+		got  decimal.Decimal
+		want string
+	}{
+		{"depth at bid level", book.DepthAt(Bid, decimal.NewFromInt(98)), "2"},
+		{"depth at missing level", book.DepthAt(Ask, decimal.NewFromInt(100)), "0"},
+		{"bids within 98", book.DepthWithin(Bid, decimal.NewFromInt(98)), "3"},
+		{"asks within 103", book.DepthWithin(Ask, decimal.NewFromInt(103)), "8"},
+	}
+
+	for _, tt := range tests {
+		if !tt.got.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, tt.got)
+		}
+	}
+
+	px, ok := book.PriceForDepth(Ask, decimal.NewFromInt(4))
+	if !ok || !px.Equal(decimal.NewFromInt(102)) {
+		t.Errorf("Expected 4 to be reached at 102, got %s (ok=%v)", px, ok)
+	}
+
+	if _, ok := book.PriceForDepth(Bid, decimal.NewFromInt(100)); ok {
+		t.Error("Expected insufficient depth for size 100")
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	book := testBook(t)
+
+	impact, err := book.VWAP(true, decimal.NewFromInt(4))
+	if err != nil {
+		t.Fatalf("VWAP failed: %v", err)
+	}
+
+	// (3*101 + 1*102) / 4 = 101.25
+	if !impact.VWAP.Equal(decimal.RequireFromString("101.25")) {
+		t.Errorf("Expected VWAP 101.25, got %s", impact.VWAP)
+	}
+	if !impact.Complete || impact.Levels != 2 || !impact.WorstPx.Equal(decimal.NewFromInt(102)) {
+		t.Errorf("Unexpected impact: %+v", impact)
+	}
+
+	impact, err = book.VWAP(false, decimal.NewFromInt(20))
+	if err != nil {
+		t.Fatalf("VWAP failed: %v", err)
+	}
+	if impact.Complete || !impact.Size.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("Expected partial impact of size 8, got %+v", impact)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	book := testBook(t)
+	snapshotTime := time.UnixMilli(1000)
+
+	if book.IsStale(time.Second, snapshotTime.Add(500*time.Millisecond)) {
+		t.Error("Expected book to be fresh")
+	}
+	if !book.IsStale(time.Second, snapshotTime.Add(2*time.Second)) {
+		t.Error("Expected book to be stale")
+	}
+	if !NewBook("BTC").IsStale(time.Hour, time.Now()) {
+		t.Error("Expected empty book to be stale")
+	}
+}
+
+func TestAggregatorDepth(t *testing.T) {
+	five, three := 5, 3
+	agg := NewAggregator("ETH", Tier{}, Tier{NSigFigs: &five}, Tier{NSigFigs: &three})
+
+	agg.Update(0, types.OrderBook{
+		Time: 1,
+		Bids: []types.OrderBookLevel{level("99.9", "1"), level("99.8", "1")},
+		Asks: []types.OrderBookLevel{level("100.1", "1")},
+	})
+	agg.Update(1, types.OrderBook{
+		Time: 2,
+		Bids: []types.OrderBookLevel{level("99.9", "1"), level("99.8", "1"), level("99", "3")},
+		Asks: []types.OrderBookLevel{level("100.1", "1"), level("101", "2")},
+	})
+	agg.Update(2, types.OrderBook{
+		Time: 3,
+		// The coarse 99 bucket overlaps the finer levels above it
+		Bids: []types.OrderBookLevel{level("99", "5"), level("98", "10")},
+		Asks: []types.OrderBookLevel{level("101", "3"), level("110", "7")},
+	})
+
+	depth := agg.Depth()
+	if depth.Time != 3 {
+		t.Errorf("Expected time 3, got %d", depth.Time)
+	}
+
+	wantBids := []types.OrderBookLevel{level("99.9", "1"), level("99.8", "1"), level("99", "3"), level("98", "10")}
+	wantAsks := []types.OrderBookLevel{level("100.1", "1"), level("101", "2"), level("110", "7")}
+
+	for name, got := range map[string][][]types.OrderBookLevel{"bids": {depth.Bids, wantBids}, "asks": {depth.Asks, wantAsks}} {
+		if len(got[0]) != len(got[1]) {
+			t.Fatalf("%s: expected %d levels, got %d", name, len(got[1]), len(got[0]))
+		}
+		for i := range got[0] {
+			if !got[0][i].Price.Equal(got[1][i].Price) || !got[0][i].Size.Equal(got[1][i].Size) {
+				t.Errorf("%s[%d]: expected %s@%s, got %s@%s", name, i, got[1][i].Size, got[1][i].Price, got[0][i].Size, got[0][i].Price)
+			}
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	levels := []types.OrderBookLevel{level("99.9", "1"), level("99.2", "2"), level("98.7", "4")}
+	buckets := Bucket(Bid, levels, decimal.NewFromInt(1))
+
+	if len(buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(buckets))
+	}
+	if !buckets[0].Price.Equal(decimal.NewFromInt(99)) || !buckets[0].Size.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("Unexpected first bucket: %+v", buckets[0])
+	}
+}
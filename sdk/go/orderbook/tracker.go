@@ -0,0 +1,173 @@
+package orderbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+)
+
+// Update is delivered to change handlers after a snapshot has been applied
+type Update struct {
+	Coin       string
+	Book       *Book
+	PrevBid    types.OrderBookLevel
+	PrevAsk    types.OrderBookLevel
+	TopChanged bool // Best bid or ask price/size changed
+}
+
+// UpdateHandler is called after each applied snapshot
+type UpdateHandler func(update Update)
+
+// Tracker maintains a Book per coin from l2Book WebSocket snapshots. It
+// accepts a single Manager or a Pool for following many coins.
+type Tracker struct {
+	ws       websocket.Subscriber
+	mu       sync.RWMutex
+	books    map[string]*Book
+	subIDs   map[string]string
+	handlers []UpdateHandler
+}
+
+// NewTracker creates a tracker that subscribes through ws
+func NewTracker(ws websocket.Subscriber) *Tracker {
+	return &Tracker{
+		ws:     ws,
+		books:  make(map[string]*Book),
+		subIDs: make(map[string]string),
+	}
+}
+
+// Track subscribes to l2Book for coin and returns its live book. Tracking an
+// already tracked coin returns the existing book.
+func (t *Tracker) Track(coin string) (*Book, error) {
+	t.mu.Lock()
+	if book, ok := t.books[coin]; ok {
+		t.mu.Unlock()
+		return book, nil
+	}
+	book := NewBook(coin)
+	t.books[coin] = book
+	t.mu.Unlock()
+
+	sub := types.WSSubscription{Type: "l2Book", Coin: coin}
+	subID, err := t.ws.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.L2BookData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return t.Handle(data)
+	})
+	if err != nil {
+		t.mu.Lock()
+		delete(t.books, coin)
+		t.mu.Unlock()
+		return nil, fmt.Errorf("failed to subscribe to %s book: %w", coin, err)
+	}
+
+	t.mu.Lock()
+	t.subIDs[coin] = subID
+	t.mu.Unlock()
+
+	return book, nil
+}
+
+// Untrack unsubscribes from coin and drops its book
+func (t *Tracker) Untrack(coin string) error {
+	t.mu.Lock()
+	subID, ok := t.subIDs[coin]
+	delete(t.subIDs, coin)
+	delete(t.books, coin)
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("coin not tracked: %s", coin)
+	}
+	return t.ws.Unsubscribe(subID)
+}
+
+// Book returns the live book for coin
+func (t *Tracker) Book(coin string) (*Book, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	book, ok := t.books[coin]
+	return book, ok
+}
+
+// Coins returns the tracked coins
+func (t *Tracker) Coins() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	coins := make([]string, 0, len(t.books))
+	for coin := range t.books {
+		coins = append(coins, coin)
+	}
+	return coins
+}
+
+// OnUpdate registers a handler called after every applied snapshot
+func (t *Tracker) OnUpdate(handler UpdateHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+// Stale returns the tracked coins whose snapshot is older than maxAge
+func (t *Tracker) Stale(maxAge time.Duration) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := time.Now()
+	var stale []string
+	for coin, book := range t.books {
+		if book.IsStale(maxAge, now) {
+			stale = append(stale, coin)
+		}
+	}
+	return stale
+}
+
+// Handle applies an l2Book payload to the matching book. It is used as the
+// subscription callback and can also be fed from other sources.
+func (t *Tracker) Handle(data types.L2BookData) error {
+	t.mu.RLock()
+	book, ok := t.books[data.Coin]
+	handlers := t.handlers
+	t.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	prevBid, _ := book.BestBid()
+	prevAsk, _ := book.BestAsk()
+
+	applied, err := book.ApplyL2(data)
+	if err != nil || !applied {
+		return err
+	}
+
+	bid, _ := book.BestBid()
+	ask, _ := book.BestAsk()
+	update := Update{
+		Coin:       data.Coin,
+		Book:       book,
+		PrevBid:    prevBid,
+		PrevAsk:    prevAsk,
+		TopChanged: !sameLevel(prevBid, bid) || !sameLevel(prevAsk, ask),
+	}
+
+	for _, handler := range handlers {
+		handler(update)
+	}
+
+	return nil
+}
+
+func sameLevel(a, b types.OrderBookLevel) bool {
+	return a.Price.Equal(b.Price) && a.Size.Equal(b.Size)
+}
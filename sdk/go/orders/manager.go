@@ -0,0 +1,772 @@
+// Package orders tracks orders through their lifecycle by combining order
+// placement results, orderUpdates and userFills into one state machine.
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// State is a stage in an order's lifecycle
+type State int
+
+const (
+	// StatePending is submitted but not yet acknowledged by the exchange
+	StatePending State = iota
+	// StateResting is on the book with nothing filled
+	StateResting
+	// StatePartiallyFilled is on the book with some size filled
+	StatePartiallyFilled
+	// StateFilled is completely filled
+	StateFilled
+	// StateCancelled was cancelled, possibly after partial fills
+	StateCancelled
+	// StateRejected was refused by the exchange
+	StateRejected
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateResting:
+		return "resting"
+	case StatePartiallyFilled:
+		return "partially-filled"
+	case StateFilled:
+		return "filled"
+	case StateCancelled:
+		return "cancelled"
+	case StateRejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Terminal reports whether no further transitions can happen
+func (s State) Terminal() bool {
+	return s >= StateFilled
+}
+
+// rank orders states so that transitions only move forward
+func (s State) rank() int {
+	if s.Terminal() {
+		return int(StateFilled)
+	}
+	return int(s)
+}
+
+// Order is a tracked order. The manager hands out copies.
+type Order struct {
+	Cloid    string // Empty for orders adopted from the feed without one
+	Oid      int64  // Zero until acknowledged
+	Coin     string
+	IsBuy    bool
+	LimitPx  decimal.Decimal
+	OrigSz   decimal.Decimal
+	FilledSz decimal.Decimal
+	AvgPx    decimal.Decimal // Volume weighted fill price
+	State    State
+	Status   string              // Last raw exchange status, e.g. "marginCanceled"
+	Error    string              // Rejection reason
+	Request  *types.OrderRequest // Nil for orders adopted from the feed
+	Created  time.Time
+	Updated  time.Time
+
+	fillSz       decimal.Decimal
+	fillNotional decimal.Decimal
+}
+
+// Remaining returns the unfilled size
+func (o Order) Remaining() decimal.Decimal {
+	return decimal.Max(o.OrigSz.Sub(o.FilledSz), decimal.Zero)
+}
+
+// Event reports a state change or a fill. New orders are reported once in
+// StatePending.
+type Event struct {
+	Order    Order
+	Previous State
+	Fill     *types.UserFillData // Set when the event was caused by a fill
+}
+
+// Handler receives lifecycle events
+type Handler func(event Event)
+
+// Exchange submits and cancels orders. *client.ExchangeClient satisfies it.
+type Exchange interface {
+	PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error)
+	CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error)
+}
+
+// StatusSource looks up a single order. *client.InfoClient satisfies it.
+type StatusSource interface {
+	GetOrderStatus(ctx context.Context, user string, oid *int64, cloid *string) (map[string]interface{}, error)
+}
+
+// Config configures an OrderManager
+type Config struct {
+	PendingTimeout  time.Duration // Age after which a pending order is looked up over REST (default 5s)
+	ResolveInterval time.Duration // How often Start looks for stuck orders (default 1s)
+	CacheSize       int           // Fills remembered for deduplication (default 10000)
+
+	// Fills backfills fills missed across reconnects once Subscribe is used.
+	// Defaults to the status source when it is also a websocket.FillSource.
+	Fills websocket.FillSource
+}
+
+// OrderManager assigns cloids to orders it places and tracks every order of
+// one user from REST results and WebSocket events
+type OrderManager struct {
+	exchange Exchange
+	status   StatusSource
+	user     string
+	config   Config
+
+	mu       sync.Mutex
+	orders   []*Order
+	byCloid  map[string]*Order
+	byOid    map[int64]*Order
+	fills    *websocket.DedupeCache
+	handlers []Handler
+	onError  func(error)
+	ws       websocket.Subscriber
+	subIDs   []string
+	stream   *websocket.FillStream
+	cancel   context.CancelFunc
+	done     chan struct{}
+	now      func() time.Time
+}
+
+// NewOrderManager creates a manager for user
+func NewOrderManager(exchange Exchange, status StatusSource, user string, config Config) *OrderManager {
+	if config.PendingTimeout <= 0 {
+		config.PendingTimeout = 5 * time.Second
+	}
+	if config.ResolveInterval <= 0 {
+		config.ResolveInterval = time.Second
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+	if config.Fills == nil {
+		config.Fills, _ = status.(websocket.FillSource)
+	}
+
+	return &OrderManager{
+		exchange: exchange,
+		status:   status,
+		user:     user,
+		config:   config,
+		byCloid:  make(map[string]*Order),
+		byOid:    make(map[int64]*Order),
+		fills:    websocket.NewDedupeCache(config.CacheSize),
+		now:      time.Now,
+	}
+}
+
+// OnEvent registers a lifecycle event handler. Handlers run synchronously,
+// outside the manager's lock.
+func (m *OrderManager) OnEvent(handler Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// OnError sets a callback for failures in the background resolve loop and
+// in fill backfills
+func (m *OrderManager) OnError(handler func(error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = handler
+}
+
+// Place submits a single order and returns its tracked state
+func (m *OrderManager) Place(ctx context.Context, order types.OrderRequest) (Order, error) {
+	placed, err := m.PlaceOrders(ctx, []types.OrderRequest{order}, types.GroupingNA)
+	if len(placed) == 0 {
+		return Order{}, err
+	}
+	return placed[0], err
+}
+
+// PlaceOrders submits orders in one action. Orders without a cloid are given
+// one. If the request fails the orders stay pending until resolved, since
+// they may still have reached the exchange.
+func (m *OrderManager) PlaceOrders(ctx context.Context, requests []types.OrderRequest, grouping types.Grouping) ([]Order, error) {
+	requests = append([]types.OrderRequest(nil), requests...)
+	tracked := make([]*Order, len(requests))
+
+	m.mu.Lock()
+	for i := range requests {
+		if requests[i].Cloid == nil {
+			cloid, err := utils.NewCloid()
+			if err != nil {
+				m.mu.Unlock()
+				return nil, fmt.Errorf("failed to generate cloid: %w", err)
+			}
+			requests[i].Cloid = &cloid
+		} else if err := utils.ValidateCloid(*requests[i].Cloid); err != nil {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("order %d: %w", i, err)
+		}
+		if _, ok := m.byCloid[*requests[i].Cloid]; ok {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("order %d: cloid %s is already tracked", i, *requests[i].Cloid)
+		}
+	}
+
+	var events []Event
+	now := m.now()
+	for i := range requests {
+		request := requests[i]
+		order := &Order{
+			Cloid:   *request.Cloid,
+			Coin:    request.Asset,
+			IsBuy:   request.IsBuy,
+			LimitPx: request.LimitPx,
+			OrigSz:  request.Sz,
+			State:   StatePending,
+			Request: &request,
+			Created: now,
+			Updated: now,
+		}
+		m.track(order)
+		tracked[i] = order
+		events = append(events, Event{Order: *order, Previous: StatePending})
+	}
+	m.mu.Unlock()
+	m.emit(events)
+
+	resp, err := m.exchange.PlaceOrders(ctx, requests, grouping)
+	if err != nil {
+		return m.snapshot(tracked), fmt.Errorf("failed to place orders: %w", err)
+	}
+
+	m.mu.Lock()
+	events = events[:0]
+	statuses := resp.Response.Data.Statuses
+	for i, order := range tracked {
+		if i >= len(statuses) {
+			break
+		}
+		if event, ok := m.applyStatus(order, statuses[i]); ok {
+			events = append(events, event)
+		}
+	}
+	m.mu.Unlock()
+	m.emit(events)
+
+	return m.snapshot(tracked), nil
+}
+
+// Cancel cancels a tracked order by cloid
+func (m *OrderManager) Cancel(ctx context.Context, cloid string) error {
+	m.mu.Lock()
+	order, ok := m.byCloid[cloid]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("unknown cloid %s", cloid)
+	}
+	if order.State.Terminal() {
+		state := order.State
+		m.mu.Unlock()
+		return fmt.Errorf("order %s is already %s", cloid, state)
+	}
+	coin := order.Coin
+	m.mu.Unlock()
+
+	statuses, err := m.exchange.CancelByCloid(ctx, coin, cloid)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if len(statuses) > 0 && statuses[0].Error != nil {
+		return fmt.Errorf("failed to cancel order: %s", *statuses[0].Error)
+	}
+
+	m.mu.Lock()
+	event, changed := m.transition(order, StateCancelled, "canceled")
+	m.mu.Unlock()
+	if changed {
+		m.emit([]Event{event})
+	}
+
+	return nil
+}
+
+// Get returns the order with the given cloid
+func (m *OrderManager) Get(cloid string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.byCloid[cloid]
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// GetByOid returns the order with the given exchange order ID
+func (m *OrderManager) GetByOid(oid int64) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.byOid[oid]
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// ByCoin returns every tracked order for coin, oldest first
+func (m *OrderManager) ByCoin(coin string) []Order {
+	return m.filter(func(o *Order) bool { return o.Coin == coin })
+}
+
+// Open returns every order that has not reached a terminal state
+func (m *OrderManager) Open() []Order {
+	return m.filter(func(o *Order) bool { return !o.State.Terminal() })
+}
+
+// Orders returns every tracked order, oldest first
+func (m *OrderManager) Orders() []Order {
+	return m.filter(func(*Order) bool { return true })
+}
+
+// Prune forgets terminal orders last updated more than age ago and returns
+// how many were dropped
+func (m *OrderManager) Prune(age time.Duration) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := m.now().Add(-age)
+	kept := m.orders[:0]
+	dropped := 0
+	for _, order := range m.orders {
+		if order.State.Terminal() && order.Updated.Before(cutoff) {
+			delete(m.byCloid, order.Cloid)
+			delete(m.byOid, order.Oid)
+			dropped++
+			continue
+		}
+		kept = append(kept, order)
+	}
+	for i := len(kept); i < len(m.orders); i++ {
+		m.orders[i] = nil
+	}
+	m.orders = kept
+
+	return dropped
+}
+
+// Subscribe feeds the manager from orderUpdates on ws and from a
+// websocket.FillStream for userFills, so fills missed while disconnected are
+// backfilled from Config.Fills
+func (m *OrderManager) Subscribe(ws websocket.Subscriber) error {
+	orderSub, err := ws.Subscribe(types.WSSubscription{Type: "orderUpdates", User: m.user}, func(raw json.RawMessage) error {
+		var updates []types.OrderUpdate
+		if err := json.Unmarshal(raw, &updates); err != nil {
+			return err
+		}
+		m.HandleOrderUpdates(updates)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to order updates: %w", err)
+	}
+
+	stream := websocket.NewFillStream(ws, m.user, websocket.FillStreamConfig{
+		CacheSize:       m.config.CacheSize,
+		Backfill:        m.config.Fills,
+		DeliverSnapshot: true,
+	}, func(event websocket.FillEvent) error {
+		m.HandleUserFills(types.UserFillsData{
+			User:       m.user,
+			IsSnapshot: event.Origin == websocket.FillSnapshot,
+			Fills:      []types.UserFillData{event.Fill},
+		})
+		return nil
+	})
+	stream.OnError(m.report)
+	if _, err := stream.Start(); err != nil {
+		ws.Unsubscribe(orderSub)
+		return fmt.Errorf("failed to subscribe to user fills: %w", err)
+	}
+
+	m.mu.Lock()
+	m.ws = ws
+	m.subIDs = append(m.subIDs, orderSub)
+	m.stream = stream
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Unsubscribe stops consuming WebSocket events
+func (m *OrderManager) Unsubscribe() error {
+	m.mu.Lock()
+	ws, subIDs, stream := m.ws, m.subIDs, m.stream
+	m.ws, m.subIDs, m.stream = nil, nil, nil
+	m.mu.Unlock()
+
+	var errs []error
+	for _, subID := range subIDs {
+		if err := ws.Unsubscribe(subID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if stream != nil {
+		if err := stream.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleOrderUpdates applies an orderUpdates message. Orders not placed
+// through the manager are adopted.
+func (m *OrderManager) HandleOrderUpdates(updates []types.OrderUpdate) {
+	m.mu.Lock()
+	var events []Event
+	for _, update := range updates {
+		if event, ok := m.applyUpdate(update); ok {
+			events = append(events, event)
+		}
+	}
+	m.mu.Unlock()
+
+	m.emit(events)
+}
+
+// HandleUserFills applies a userFills message. Fills are deduplicated by
+// websocket.FillKey over the last Config.CacheSize fills, so snapshots and
+// replays are safe; fills for untracked orders are ignored.
+func (m *OrderManager) HandleUserFills(data types.UserFillsData) {
+	m.mu.Lock()
+	var events []Event
+	for i := range data.Fills {
+		fill := data.Fills[i]
+		key := websocket.FillKey(fill)
+		if m.fills.Contains(key) {
+			continue
+		}
+
+		order := m.lookup(fill.Oid, fill.Cloid)
+		if order == nil {
+			continue
+		}
+		m.fills.Add(key)
+
+		previous := order.State
+		order.fillSz = order.fillSz.Add(fill.Sz)
+		order.fillNotional = order.fillNotional.Add(fill.Sz.Mul(fill.Px))
+		if order.fillSz.GreaterThan(order.FilledSz) {
+			order.FilledSz = order.fillSz
+			order.AvgPx = order.fillNotional.Div(order.fillSz)
+		}
+		if order.Oid == 0 {
+			order.Oid = fill.Oid
+			m.byOid[fill.Oid] = order
+		}
+
+		next := StatePartiallyFilled
+		if order.FilledSz.GreaterThanOrEqual(order.OrigSz) {
+			next = StateFilled
+		}
+		if next.rank() > order.State.rank() {
+			order.State = next
+		}
+		order.Updated = m.now()
+
+		events = append(events, Event{Order: *order, Previous: previous, Fill: &fill})
+	}
+	m.mu.Unlock()
+
+	m.emit(events)
+}
+
+// ResolvePending looks up orders pending for longer than the configured
+// timeout. Orders the exchange does not know are marked rejected.
+func (m *OrderManager) ResolvePending(ctx context.Context) error {
+	cutoff := m.now().Add(-m.config.PendingTimeout)
+	stuck := m.filter(func(o *Order) bool {
+		return o.State == StatePending && o.Cloid != "" && o.Created.Before(cutoff)
+	})
+
+	var errs []error
+	for _, order := range stuck {
+		cloid := order.Cloid
+		raw, err := m.status.GetOrderStatus(ctx, m.user, nil, &cloid)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve %s: %w", cloid, err))
+			continue
+		}
+
+		var status struct {
+			Status string             `json:"status"`
+			Order  *types.OrderUpdate `json:"order"`
+		}
+		if err := remarshal(raw, &status); err != nil {
+			errs = append(errs, fmt.Errorf("failed to decode status of %s: %w", cloid, err))
+			continue
+		}
+
+		m.mu.Lock()
+		var event Event
+		var changed bool
+		if status.Status == "order" && status.Order != nil {
+			event, changed = m.applyUpdate(*status.Order)
+		} else if tracked, ok := m.byCloid[cloid]; ok {
+			tracked.Error = "order not found on exchange"
+			event, changed = m.transition(tracked, StateRejected, status.Status)
+		}
+		m.mu.Unlock()
+
+		if changed {
+			m.emit([]Event{event})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Start resolves stuck pending orders in the background until Stop or ctx
+// is done
+func (m *OrderManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		return fmt.Errorf("order manager already started")
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.loop(loopCtx, m.done)
+
+	return nil
+}
+
+// Stop stops the background resolve loop
+func (m *OrderManager) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (m *OrderManager) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.config.ResolveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.ResolvePending(ctx); err != nil && ctx.Err() == nil {
+				m.report(err)
+			}
+		}
+	}
+}
+
+// report passes a background failure to the OnError handler
+func (m *OrderManager) report(err error) {
+	m.mu.Lock()
+	handler := m.onError
+	m.mu.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// applyStatus applies a placement result. Callers hold m.mu.
+func (m *OrderManager) applyStatus(order *Order, status types.OrderStatus) (Event, bool) {
+	switch {
+	case status.Error != nil:
+		order.Error = *status.Error
+		return m.transition(order, StateRejected, "rejected")
+
+	case status.Resting != nil:
+		m.setOid(order, status.Resting.Oid)
+		return m.transition(order, StateResting, "open")
+
+	case status.Filled != nil:
+		m.setOid(order, status.Filled.Oid)
+		if status.Filled.TotalSz.GreaterThan(order.FilledSz) {
+			order.FilledSz = status.Filled.TotalSz
+			order.AvgPx = status.Filled.AvgPx
+		}
+		if order.FilledSz.GreaterThanOrEqual(order.OrigSz) {
+			return m.transition(order, StateFilled, "filled")
+		}
+		// The unfilled remainder of an IOC order is cancelled immediately
+		if order.Request != nil && order.Request.OrderType.Limit != nil && order.Request.OrderType.Limit.Tif == types.TifIoc {
+			return m.transition(order, StateCancelled, "canceled")
+		}
+		return m.transition(order, StatePartiallyFilled, "open")
+	}
+
+	return Event{}, false
+}
+
+// applyUpdate applies one orderUpdates element. Callers hold m.mu.
+func (m *OrderManager) applyUpdate(update types.OrderUpdate) (Event, bool) {
+	basic := update.Order
+
+	order := m.lookup(basic.Oid, basic.Cloid)
+	if order == nil {
+		created := time.UnixMilli(basic.Timestamp)
+		order = &Order{
+			Coin:    basic.Coin,
+			IsBuy:   basic.Side == "B",
+			LimitPx: basic.LimitPx,
+			OrigSz:  basic.OrigSz,
+			State:   StatePending,
+			Created: created,
+			Updated: created,
+		}
+		if basic.Cloid != nil {
+			order.Cloid = *basic.Cloid
+		}
+		m.track(order)
+	}
+	m.setOid(order, basic.Oid)
+
+	// The remaining size bounds the filled size from below even before the
+	// fills themselves arrive
+	if filled := basic.OrigSz.Sub(basic.Sz); filled.GreaterThan(order.FilledSz) {
+		order.FilledSz = filled
+	}
+
+	return m.transition(order, stateForStatus(update.Status, order), update.Status)
+}
+
+// transition moves order to state if that is forward progress. Callers hold
+// m.mu.
+func (m *OrderManager) transition(order *Order, state State, status string) (Event, bool) {
+	if state.rank() <= order.State.rank() {
+		return Event{}, false
+	}
+
+	previous := order.State
+	order.State = state
+	order.Status = status
+	order.Updated = m.now()
+
+	return Event{Order: *order, Previous: previous}, true
+}
+
+// stateForStatus maps an exchange order status to a State
+func stateForStatus(status string, order *Order) State {
+	switch {
+	case status == "open" || status == "triggered":
+		if order.FilledSz.IsPositive() {
+			return StatePartiallyFilled
+		}
+		return StateResting
+	case status == "filled":
+		return StateFilled
+	case strings.HasSuffix(strings.ToLower(status), "rejected"):
+		return StateRejected
+	case strings.Contains(strings.ToLower(status), "cancel"):
+		return StateCancelled
+	default:
+		return order.State
+	}
+}
+
+// track registers a new order. Callers hold m.mu.
+func (m *OrderManager) track(order *Order) {
+	m.orders = append(m.orders, order)
+	if order.Cloid != "" {
+		m.byCloid[order.Cloid] = order
+	}
+}
+
+// setOid records the exchange order ID. Callers hold m.mu.
+func (m *OrderManager) setOid(order *Order, oid int64) {
+	if oid == 0 || order.Oid == oid {
+		return
+	}
+	order.Oid = oid
+	m.byOid[oid] = order
+}
+
+// lookup finds an order by cloid, then oid. Callers hold m.mu.
+func (m *OrderManager) lookup(oid int64, cloid *string) *Order {
+	if cloid != nil {
+		if order, ok := m.byCloid[*cloid]; ok {
+			return order
+		}
+	}
+	return m.byOid[oid]
+}
+
+func (m *OrderManager) filter(keep func(o *Order) bool) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	orders := make([]Order, 0)
+	for _, order := range m.orders {
+		if keep(order) {
+			orders = append(orders, *order)
+		}
+	}
+	return orders
+}
+
+func (m *OrderManager) snapshot(tracked []*Order) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	orders := make([]Order, len(tracked))
+	for i, order := range tracked {
+		orders[i] = *order
+	}
+	return orders
+}
+
+func (m *OrderManager) emit(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	handlers := append([]Handler(nil), m.handlers...)
+	m.mu.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+func remarshal(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,341 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const testUser = "0x1234567890123456789012345678901234567890"
+
+type fakeExchange struct {
+	statuses []types.OrderStatus
+	err      error
+	placed   []types.OrderRequest
+	canceled []string
+}
+
+func (f *fakeExchange) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error) {
+	f.placed = append(f.placed, orders...)
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := &types.OrderResponse{Status: "ok"}
+	resp.Response.Data.Statuses = f.statuses
+	return resp, nil
+}
+
+func (f *fakeExchange) CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error) {
+	f.canceled = append(f.canceled, cloids...)
+	statuses := make([]types.ActionStatus, len(cloids))
+	for i := range statuses {
+		statuses[i].Success = true
+	}
+	return statuses, nil
+}
+
+type fakeStatus map[string]map[string]interface{}
+
+func (f fakeStatus) GetOrderStatus(ctx context.Context, user string, oid *int64, cloid *string) (map[string]interface{}, error) {
+	if status, ok := f[*cloid]; ok {
+		return status, nil
+	}
+	return map[string]interface{}{"status": "unknownOid"}, nil
+}
+
+// backfillStatus is a status source that also backfills fills
+type backfillStatus struct {
+	fakeStatus
+	fills []types.Fill
+}
+
+func (b backfillStatus) GetUserFills(ctx context.Context, user string, startTime, endTime *int64) ([]types.Fill, error) {
+	return b.fills, nil
+}
+
+// fakeSubscriber captures the handlers the manager subscribes with
+type fakeSubscriber struct {
+	fills websocket.InfoHandler
+}
+
+func (f *fakeSubscriber) Subscribe(sub types.WSSubscription, handler websocket.MessageHandler) (string, error) {
+	return sub.Type, nil
+}
+
+func (f *fakeSubscriber) SubscribeWithInfo(sub types.WSSubscription, handler websocket.InfoHandler) (string, error) {
+	f.fills = handler
+	return sub.Type, nil
+}
+
+func (f *fakeSubscriber) Unsubscribe(subID string) error {
+	return nil
+}
+
+func order(isBuy bool, px, sz, tif string) types.OrderRequest {
+	return types.OrderRequest{
+		Asset:     "BTC",
+		IsBuy:     isBuy,
+		LimitPx:   decimal.RequireFromString(px),
+		Sz:        decimal.RequireFromString(sz),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: tif}},
+	}
+}
+
+func fillData(oid, tid int64, px, sz string) types.UserFillsData {
+	return types.UserFillsData{User: testUser, Fills: []types.UserFillData{{
+		Coin: "BTC",
+		Px:   decimal.RequireFromString(px),
+		Sz:   decimal.RequireFromString(sz),
+		Oid:  oid,
+		Tid:  tid,
+	}}}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	exchange := &fakeExchange{statuses: []types.OrderStatus{{Resting: &types.RestingOrder{Oid: 100}}}}
+	m := NewOrderManager(exchange, fakeStatus{}, testUser, Config{})
+
+	var events []Event
+	m.OnEvent(func(e Event) { events = append(events, e) })
+
+	placed, err := m.Place(context.Background(), order(true, "50000", "1", types.TifGtc))
+	if err != nil {
+		t.Fatalf("Failed to place order: %v", err)
+	}
+	if placed.Cloid == "" || exchange.placed[0].Cloid == nil || *exchange.placed[0].Cloid != placed.Cloid {
+		t.Fatalf("Expected a generated cloid to be sent, got %q", placed.Cloid)
+	}
+	if placed.State != StateResting || placed.Oid != 100 {
+		t.Errorf("Expected resting oid 100, got %s oid %d", placed.State, placed.Oid)
+	}
+
+	// Replayed fills are counted once
+	m.HandleUserFills(fillData(100, 1, "50000", "0.4"))
+	m.HandleUserFills(fillData(100, 1, "50000", "0.4"))
+	if o, _ := m.GetByOid(100); o.State != StatePartiallyFilled || !o.FilledSz.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("Expected 0.4 partially filled, got %s %s", o.State, o.FilledSz)
+	}
+
+	m.HandleUserFills(fillData(100, 2, "49990", "0.6"))
+	o, ok := m.Get(placed.Cloid)
+	if !ok || o.State != StateFilled {
+		t.Fatalf("Expected filled, got %s", o.State)
+	}
+	if !o.AvgPx.Equal(decimal.RequireFromString("49994")) {
+		t.Errorf("Expected average price 49994, got %s", o.AvgPx)
+	}
+
+	// Late updates cannot move a terminal order
+	m.HandleOrderUpdates([]types.OrderUpdate{{
+		Order:  types.BasicOrder{Coin: "BTC", Oid: 100, Cloid: &placed.Cloid, OrigSz: decimal.NewFromInt(1), Sz: decimal.NewFromInt(1)},
+		Status: "open",
+	}})
+	if o, _ := m.Get(placed.Cloid); o.State != StateFilled {
+		t.Errorf("Expected order to stay filled, got %s", o.State)
+	}
+
+	want := []State{StatePending, StateResting, StatePartiallyFilled, StateFilled}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(events))
+	}
+	for i, state := range want {
+		if events[i].Order.State != state {
+			t.Errorf("Event %d: expected %s, got %s", i, state, events[i].Order.State)
+		}
+	}
+	if events[2].Fill == nil || events[2].Fill.Tid != 1 {
+		t.Errorf("Expected fill event for tid 1")
+	}
+
+	if open := m.Open(); len(open) != 0 {
+		t.Errorf("Expected no open orders, got %d", len(open))
+	}
+	if n := m.Prune(0); n != 1 || len(m.Orders()) != 0 {
+		t.Errorf("Expected to prune 1 order, pruned %d", n)
+	}
+}
+
+func TestPlacementOutcomes(t *testing.T) {
+	rejected := "Insufficient margin to place order."
+	exchange := &fakeExchange{statuses: []types.OrderStatus{
+		{Error: &rejected},
+		{Filled: &types.FilledOrder{TotalSz: decimal.RequireFromString("0.5"), AvgPx: decimal.NewFromInt(50000), Oid: 7}},
+		{Resting: &types.RestingOrder{Oid: 8}},
+	}}
+	m := NewOrderManager(exchange, fakeStatus{}, testUser, Config{})
+	ctx := context.Background()
+
+	placed, err := m.PlaceOrders(ctx, []types.OrderRequest{
+		order(true, "50000", "1", types.TifGtc),
+		order(true, "50000", "1", types.TifIoc),
+		order(false, "51000", "1", types.TifAlo),
+	}, types.GroupingNA)
+	if err != nil {
+		t.Fatalf("Failed to place orders: %v", err)
+	}
+
+	if placed[0].State != StateRejected || placed[0].Error != rejected {
+		t.Errorf("Expected rejection, got %s %q", placed[0].State, placed[0].Error)
+	}
+	if placed[1].State != StateCancelled || !placed[1].FilledSz.Equal(decimal.RequireFromString("0.5")) {
+		t.Errorf("Expected IOC remainder cancelled after 0.5 filled, got %s %s", placed[1].State, placed[1].FilledSz)
+	}
+
+	if err := m.Cancel(ctx, placed[2].Cloid); err != nil {
+		t.Fatalf("Failed to cancel: %v", err)
+	}
+	if o, _ := m.Get(placed[2].Cloid); o.State != StateCancelled {
+		t.Errorf("Expected cancelled, got %s", o.State)
+	}
+	if err := m.Cancel(ctx, placed[2].Cloid); err == nil {
+		t.Errorf("Expected cancelling a cancelled order to fail")
+	}
+
+	// Orders placed elsewhere are adopted from the feed
+	other := "0x00000000000000000000000000000abc"
+	m.HandleOrderUpdates([]types.OrderUpdate{{
+		Order:  types.BasicOrder{Coin: "ETH", Side: "A", Oid: 9, Cloid: &other, LimitPx: decimal.NewFromInt(3000), OrigSz: decimal.NewFromInt(2), Sz: decimal.NewFromInt(1)},
+		Status: "open",
+	}})
+	if o, ok := m.Get(other); !ok || o.State != StatePartiallyFilled || o.IsBuy {
+		t.Errorf("Expected adopted partially filled sell, got %+v", o)
+	}
+	if eth := m.ByCoin("ETH"); len(eth) != 1 {
+		t.Errorf("Expected 1 ETH order, got %d", len(eth))
+	}
+}
+
+func TestResolvePending(t *testing.T) {
+	exchange := &fakeExchange{err: errors.New("connection reset")}
+	lost, _ := utils.NewCloid()
+	landed, _ := utils.NewCloid()
+	status := fakeStatus{landed: {
+		"status": "order",
+		"order": map[string]interface{}{
+			"order":  map[string]interface{}{"coin": "BTC", "side": "B", "limitPx": "50000", "sz": "0", "oid": 42, "origSz": "1", "cloid": landed},
+			"status": "filled",
+		},
+	}}
+	m := NewOrderManager(exchange, status, testUser, Config{PendingTimeout: time.Second})
+	ctx := context.Background()
+
+	a, b := order(true, "50000", "1", types.TifGtc), order(true, "50000", "1", types.TifGtc)
+	a.Cloid, b.Cloid = &lost, &landed
+	if _, err := m.PlaceOrders(ctx, []types.OrderRequest{a, b}, types.GroupingNA); err == nil {
+		t.Fatalf("Expected placement error")
+	}
+	if len(m.Open()) != 2 {
+		t.Fatalf("Expected both orders to stay pending")
+	}
+
+	if err := m.ResolvePending(ctx); err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if len(m.Open()) != 2 {
+		t.Errorf("Expected orders younger than the timeout to be left alone")
+	}
+
+	m.now = func() time.Time { return time.Now().Add(2 * time.Second) }
+	if err := m.ResolvePending(ctx); err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if o, _ := m.Get(lost); o.State != StateRejected {
+		t.Errorf("Expected unknown order rejected, got %s", o.State)
+	}
+	if o, _ := m.GetByOid(42); o.State != StateFilled || o.Cloid != landed {
+		t.Errorf("Expected landed order filled, got %s", o.State)
+	}
+}
+
+func TestSubscribeBackfillsMissedFills(t *testing.T) {
+	exchange := &fakeExchange{statuses: []types.OrderStatus{{Resting: &types.RestingOrder{Oid: 100}}}}
+	status := backfillStatus{fills: []types.Fill{
+		{Coin: "BTC", Px: decimal.RequireFromString("50000"), Sz: decimal.RequireFromString("0.4"), Oid: 100, Tid: 1, Time: 1000},
+		{Coin: "BTC", Px: decimal.RequireFromString("50000"), Sz: decimal.RequireFromString("0.6"), Oid: 100, Tid: 2, Time: 2000},
+	}}
+	m := NewOrderManager(exchange, status, testUser, Config{})
+	ws := &fakeSubscriber{}
+	if err := m.Subscribe(ws); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	placed, err := m.Place(context.Background(), order(true, "50000", "1", types.TifGtc))
+	if err != nil {
+		t.Fatalf("Failed to place order: %v", err)
+	}
+
+	push := func(epoch uint64, data types.UserFillsData) {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("Failed to marshal fills: %v", err)
+		}
+		if err := ws.fills(raw, websocket.MessageInfo{Epoch: epoch, IsSnapshot: data.IsSnapshot}); err != nil {
+			t.Fatalf("Failed to handle fills: %v", err)
+		}
+	}
+	first := fillData(100, 1, "50000", "0.4")
+	first.IsSnapshot = true
+	first.Fills[0].Time = 1000
+	push(1, first)
+
+	// Tid 2 landed while disconnected and is missing from the new snapshot
+	push(2, first)
+
+	hltest.WaitFor(t, []string{"filled 1"}, func() []string {
+		o, _ := m.Get(placed.Cloid)
+		return []string{o.State.String() + " " + o.FilledSz.String()}
+	})
+}
+
+func TestOrderManagerWithFakeExchange(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+
+	key := "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	address, _ := utils.GetAddressFromPrivateKey(key)
+	c := client.NewClient(srv.URL, srv.WSURL, key)
+	c.SetAddress(address)
+
+	ws := websocket.NewManager(srv.WSURL)
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Disconnect()
+
+	m := NewOrderManager(c.Exchange(), c.Info(), address, Config{})
+	filled := make(chan Order, 1)
+	m.OnEvent(func(e Event) {
+		if e.Order.State == StateFilled {
+			filled <- e.Order
+		}
+	})
+	if err := m.Subscribe(ws); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer m.Unsubscribe()
+
+	placed, err := m.Place(context.Background(), order(false, "51000", "1", types.TifGtc))
+	if err != nil || placed.State != StateResting {
+		t.Fatalf("Expected resting order, got %s: %v", placed.State, err)
+	}
+
+	srv.Engine().Place("0xtaker", order(true, "51000", "1", types.TifIoc))
+
+	select {
+	case o := <-filled:
+		if o.Cloid != placed.Cloid || !o.FilledSz.Equal(decimal.NewFromInt(1)) {
+			t.Errorf("Expected %s fully filled, got %+v", placed.Cloid, o)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for fill")
+	}
+}
@@ -0,0 +1,1091 @@
+package paper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orderbook"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// Order status values reported in order updates
+const (
+	StatusOpen     = "open"
+	StatusFilled   = "filled"
+	StatusCanceled = "canceled"
+
+	// StatusMarginCanceled is reported for orders cancelled by Liquidate
+	StatusMarginCanceled = "marginCanceled"
+
+	// StatusReduceOnlyCanceled is reported for resting reduce-only orders
+	// left with no position to reduce
+	StatusReduceOnlyCanceled = "reduceOnlyCanceled"
+)
+
+// Error messages returned in order statuses, matching the exchange's wording
+const (
+	ErrPostOnlyMatch      = "Post only order would have immediately matched"
+	ErrIocNoMatch         = "Order could not immediately match against any resting orders."
+	ErrReduceOnly         = "Reduce only order would increase position."
+	ErrInsufficientMargin = "Insufficient margin to place order."
+	ErrOrderNotFound      = "Order was never placed, already canceled, or filled."
+	ErrInvalidSize        = "Order has zero size."
+	ErrInvalidPrice       = "Order has invalid price."
+	ErrUnknownTimeInForce = "Invalid time in force."
+	ErrTriggerUnsupported = "Trigger orders are not supported in paper trading."
+)
+
+// feeWindow is the volume window fee tiers are assessed over
+const feeWindow = 14 * 24 * time.Hour
+
+// FeeTier is the fee rate that applies once 14-day volume reaches MinVolume.
+// Rates are fractions of notional; a negative maker rate is a rebate.
+type FeeTier struct {
+	MinVolume decimal.Decimal
+	Taker     decimal.Decimal
+	Maker     decimal.Decimal
+}
+
+// DefaultFeeTiers is the exchange's perp fee schedule
+var DefaultFeeTiers = []FeeTier{
+	{MinVolume: decimal.Zero, Taker: decimal.RequireFromString("0.00045"), Maker: decimal.RequireFromString("0.00015")},
+	{MinVolume: decimal.NewFromInt(5_000_000), Taker: decimal.RequireFromString("0.0004"), Maker: decimal.RequireFromString("0.00012")},
+	{MinVolume: decimal.NewFromInt(25_000_000), Taker: decimal.RequireFromString("0.00035"), Maker: decimal.RequireFromString("0.00008")},
+	{MinVolume: decimal.NewFromInt(100_000_000), Taker: decimal.RequireFromString("0.0003"), Maker: decimal.RequireFromString("0.00004")},
+	{MinVolume: decimal.NewFromInt(500_000_000), Taker: decimal.RequireFromString("0.00028"), Maker: decimal.Zero},
+	{MinVolume: decimal.NewFromInt(2_000_000_000), Taker: decimal.RequireFromString("0.00026"), Maker: decimal.Zero},
+	{MinVolume: decimal.NewFromInt(7_000_000_000), Taker: decimal.RequireFromString("0.00024"), Maker: decimal.Zero},
+}
+
+// Config configures a PaperExchange
+type Config struct {
+	Balance  decimal.Decimal // Starting USDC balance
+	Leverage int             // Cross leverage used for margin, default 20
+	Latency  time.Duration   // Delay before each action reaches the book
+	FeeTiers []FeeTier       // Fee schedule ordered by MinVolume, default DefaultFeeTiers
+	Volume   decimal.Decimal // 14-day volume already traded, for fee tier selection
+}
+
+// order is a resting paper order
+type order struct {
+	oid        int64
+	cloid      *string
+	coin       string
+	isBuy      bool
+	limitPx    decimal.Decimal
+	sz         decimal.Decimal // Remaining size
+	origSz     decimal.Decimal
+	timestamp  int64
+	reduceOnly bool
+	tif        string
+	queue      decimal.Decimal // Visible size ahead of the order at its price
+}
+
+func (o *order) side() string {
+	if o.isBuy {
+		return "B"
+	}
+	return "A"
+}
+
+func (o *order) basic() types.BasicOrder {
+	return types.BasicOrder{
+		Coin:      o.coin,
+		Side:      o.side(),
+		LimitPx:   o.limitPx,
+		Sz:        o.sz,
+		Oid:       o.oid,
+		Timestamp: o.timestamp,
+		OrigSz:    o.origSz,
+		Cloid:     o.cloid,
+	}
+}
+
+func (o *order) open() types.OpenOrder {
+	return types.OpenOrder{
+		Coin:       o.coin,
+		LimitPx:    o.limitPx,
+		Oid:        o.oid,
+		Side:       o.side(),
+		Sz:         o.sz,
+		Timestamp:  o.timestamp,
+		OrigSz:     o.origSz,
+		Cloid:      o.cloid,
+		ReduceOnly: o.reduceOnly,
+		OrderType:  "Limit",
+	}
+}
+
+// position is a simulated net position in one coin
+type position struct {
+	szi     decimal.Decimal
+	entryPx decimal.Decimal
+}
+
+// market is the live book of one coin and the liquidity taken from it
+// since the last snapshot
+type market struct {
+	book     *orderbook.Book
+	consumed map[string]decimal.Decimal
+}
+
+type volume struct {
+	time     time.Time
+	notional decimal.Decimal
+}
+
+// batch collects the events of one operation for delivery outside the lock
+type batch struct {
+	updates []types.OrderUpdate
+	fills   []types.UserFillData
+}
+
+// PaperExchange simulates order entry against live market data. Taker
+// orders walk the current L2 book; resting orders join the back of the
+// visible queue at their price and fill as trades print through them.
+// Margin, positions and fees are tracked for a single cross-margin account.
+// Only perp limit orders are supported.
+//
+// It implements client.Trader, so strategies run unchanged against it, and
+// GetUserState and GetOrderStatus so PositionTracker and OrderManager can
+// follow it through OnFills and OnOrderUpdates.
+type PaperExchange struct {
+	mu            sync.Mutex
+	ws            websocket.Subscriber
+	user          string
+	config        Config
+	markets       map[string]*market
+	orders        map[int64]*order
+	history       map[int64]types.OrderUpdate
+	positions     map[string]*position
+	balance       decimal.Decimal
+	volumes       []volume
+	fills         []types.UserFillData
+	nextOid       int64
+	nextTid       int64
+	now           func() time.Time
+	subIDs        []string
+	orderHandlers []func([]types.OrderUpdate)
+	fillHandlers  []func(types.UserFillsData)
+}
+
+var _ client.Trader = (*PaperExchange)(nil)
+
+// NewPaperExchange creates a paper account for user that follows books
+// through ws. With a nil ws, market data is fed with HandleBook and
+// HandleTrades instead.
+func NewPaperExchange(ws websocket.Subscriber, user string, config Config) *PaperExchange {
+	if config.Leverage <= 0 {
+		config.Leverage = 20
+	}
+	if len(config.FeeTiers) == 0 {
+		config.FeeTiers = DefaultFeeTiers
+	}
+
+	return &PaperExchange{
+		ws:        ws,
+		user:      user,
+		config:    config,
+		markets:   make(map[string]*market),
+		orders:    make(map[int64]*order),
+		history:   make(map[int64]types.OrderUpdate),
+		positions: make(map[string]*position),
+		balance:   config.Balance,
+		nextOid:   1,
+		nextTid:   1,
+		now:       time.Now,
+	}
+}
+
+// SetClock replaces the exchange's time source
+func (p *PaperExchange) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+// OnOrderUpdates registers a handler for order status changes, shaped like
+// the orderUpdates stream
+func (p *PaperExchange) OnOrderUpdates(handler func([]types.OrderUpdate)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.orderHandlers = append(p.orderHandlers, handler)
+}
+
+// OnFills registers a handler for fills, shaped like the userFills stream
+func (p *PaperExchange) OnFills(handler func(types.UserFillsData)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fillHandlers = append(p.fillHandlers, handler)
+}
+
+// Track follows coin's l2Book and trades. Orders track their coin
+// automatically.
+func (p *PaperExchange) Track(coin string) error {
+	p.mu.Lock()
+	if _, ok := p.markets[coin]; ok {
+		p.mu.Unlock()
+		return nil
+	}
+	p.markets[coin] = &market{book: orderbook.NewBook(coin), consumed: make(map[string]decimal.Decimal)}
+	ws := p.ws
+	p.mu.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+
+	subs := []struct {
+		sub    types.WSSubscription
+		handle func(raw json.RawMessage) error
+	}{
+		{types.WSSubscription{Type: "l2Book", Coin: coin}, func(raw json.RawMessage) error {
+			var data types.L2BookData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			return p.HandleBook(data)
+		}},
+		{types.WSSubscription{Type: "trades", Coin: coin}, func(raw json.RawMessage) error {
+			var data []types.TradeData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			p.HandleTrades(data)
+			return nil
+		}},
+	}
+
+	var subIDs []string
+	for _, s := range subs {
+		subID, err := ws.Subscribe(s.sub, s.handle)
+		if err != nil {
+			for _, id := range subIDs {
+				ws.Unsubscribe(id)
+			}
+			p.mu.Lock()
+			delete(p.markets, coin)
+			p.mu.Unlock()
+			return fmt.Errorf("failed to subscribe to %s %s: %w", coin, s.sub.Type, err)
+		}
+		subIDs = append(subIDs, subID)
+	}
+
+	p.mu.Lock()
+	p.subIDs = append(p.subIDs, subIDs...)
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Unsubscribe stops following market data
+func (p *PaperExchange) Unsubscribe() error {
+	p.mu.Lock()
+	ws, subIDs := p.ws, p.subIDs
+	p.subIDs = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, subID := range subIDs {
+		if err := ws.Unsubscribe(subID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleBook applies an l2Book snapshot. Liquidity taken by paper orders is
+// restored, queues shrink to the size now visible at their price, and
+// resting orders the book has moved through are filled.
+func (p *PaperExchange) HandleBook(data types.L2BookData) error {
+	p.mu.Lock()
+	m, ok := p.markets[data.Coin]
+	if !ok {
+		p.mu.Unlock()
+		return nil
+	}
+
+	applied, err := m.book.ApplyL2(data)
+	if err != nil || !applied {
+		p.mu.Unlock()
+		return err
+	}
+	m.consumed = make(map[string]decimal.Decimal)
+
+	b := &batch{}
+	for _, isBuy := range []bool{true, false} {
+		own, opposite := orderbook.Bid, orderbook.Ask
+		if !isBuy {
+			own, opposite = orderbook.Ask, orderbook.Bid
+		}
+		levels := m.book.Levels(opposite, 0)
+
+		for _, o := range p.resting(data.Coin, isBuy) {
+			// Canceled by an earlier fill shrinking the position
+			if _, ok := p.orders[o.oid]; !ok {
+				continue
+			}
+			o.queue = decimal.Min(o.queue, m.book.DepthAt(own, o.limitPx))
+
+			for _, level := range levels {
+				if !o.sz.IsPositive() || !crosses(o, level.Price) {
+					break
+				}
+				sz := decimal.Min(o.sz, m.available(level))
+				if sz.IsPositive() {
+					m.consume(level.Price, sz)
+					p.fill(o, o.limitPx, sz, false, b)
+				}
+			}
+			if o.sz.IsZero() {
+				p.remove(o, StatusFilled, b)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+	return nil
+}
+
+// HandleTrades fills resting orders from public trades. A trade at an
+// order's price first works through the queue ahead of it; a trade through
+// its price fills it directly. Each trade's size is shared out best price
+// first.
+func (p *PaperExchange) HandleTrades(trades []types.TradeData) {
+	p.mu.Lock()
+	b := &batch{}
+	for _, trade := range trades {
+		// A buy aggressor lifts asks, a sell hits bids
+		remaining := trade.Sz
+		for _, o := range p.resting(trade.Coin, trade.Side != "B") {
+			if !remaining.IsPositive() || !crosses(o, trade.Px) {
+				break
+			}
+			if _, ok := p.orders[o.oid]; !ok {
+				continue
+			}
+
+			if o.limitPx.Equal(trade.Px) {
+				ahead := decimal.Min(o.queue, remaining)
+				o.queue = o.queue.Sub(ahead)
+				remaining = remaining.Sub(ahead)
+			}
+
+			sz := decimal.Min(o.sz, remaining)
+			if sz.IsPositive() {
+				remaining = remaining.Sub(sz)
+				p.fill(o, o.limitPx, sz, false, b)
+			}
+			if o.sz.IsZero() {
+				p.remove(o, StatusFilled, b)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+}
+
+// PlaceOrder places a single order
+func (p *PaperExchange) PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error) {
+	return p.PlaceOrders(ctx, []types.OrderRequest{order}, types.GroupingNA)
+}
+
+// PlaceOrders places orders in sequence after the configured latency.
+// Grouping is ignored since trigger orders are not supported.
+func (p *PaperExchange) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error) {
+	for _, order := range orders {
+		if err := p.Track(order.Asset); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	b := &batch{}
+	statuses := make([]types.OrderStatus, 0, len(orders))
+	for _, order := range orders {
+		statuses = append(statuses, p.place(order, b))
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+	return orderResponse(statuses), nil
+}
+
+// BatchModify replaces resting orders, found by oid or cloid. Replacements
+// get a new oid and lose their queue position. A rejected replacement
+// leaves the original resting where it was.
+func (p *PaperExchange) BatchModify(ctx context.Context, modifies []types.ModifyRequest) (*types.OrderResponse, error) {
+	if len(modifies) == 0 {
+		return nil, fmt.Errorf("no modifies given")
+	}
+	for i, modify := range modifies {
+		if (modify.Oid == nil) == (modify.Cloid == nil) {
+			return nil, fmt.Errorf("modify %d must set exactly one of oid or cloid", i)
+		}
+		if err := p.Track(modify.Order.Asset); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	b := &batch{}
+	statuses := make([]types.OrderStatus, 0, len(modifies))
+	for _, modify := range modifies {
+		var target *order
+		if modify.Oid != nil {
+			target = p.orders[*modify.Oid]
+		} else {
+			target = p.findByCloid(*modify.Cloid)
+		}
+		if target == nil {
+			statuses = append(statuses, errorStatus(ErrOrderNotFound))
+			continue
+		}
+
+		// Take the target off so the replacement can use its margin, and
+		// put it back if the replacement is rejected
+		delete(p.orders, target.oid)
+		replaced := &batch{}
+		status := p.place(modify.Order, replaced)
+		if status.Error != nil {
+			p.orders[target.oid] = target
+			statuses = append(statuses, status)
+			continue
+		}
+
+		p.record(target, StatusCanceled, b)
+		b.updates = append(b.updates, replaced.updates...)
+		b.fills = append(b.fills, replaced.fills...)
+		statuses = append(statuses, status)
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+	return orderResponse(statuses), nil
+}
+
+// CancelByCloid cancels orders on coin by client order ID and returns one
+// status per cloid
+func (p *PaperExchange) CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	b := &batch{}
+	statuses := make([]types.ActionStatus, 0, len(cloids))
+	for _, cloid := range cloids {
+		o := p.findByCloid(cloid)
+		if o == nil || o.coin != coin {
+			message := ErrOrderNotFound
+			statuses = append(statuses, types.ActionStatus{Error: &message})
+			continue
+		}
+		p.remove(o, StatusCanceled, b)
+		statuses = append(statuses, types.ActionStatus{Success: true})
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+	return statuses, nil
+}
+
+// CancelAll cancels every open order matching filter
+func (p *PaperExchange) CancelAll(ctx context.Context, filter types.CancelFilter) ([]types.CancelResult, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	b := &batch{}
+	now := p.now()
+	results := make([]types.CancelResult, 0)
+	for _, o := range p.sortedOrders() {
+		open := o.open()
+		if !filter.Matches(open, now) {
+			continue
+		}
+		p.remove(o, StatusCanceled, b)
+		results = append(results, types.CancelResult{Order: open, Status: types.ActionStatus{Success: true}})
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+	return results, nil
+}
+
+// ApplyFunding settles one funding payment on coin at rate, charged on the
+// position's value at the mark. Longs pay positive rates. It reports false
+// when there is no position.
+func (p *PaperExchange) ApplyFunding(coin string, rate decimal.Decimal) (types.FundingData, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pos, ok := p.positions[coin]
+	if !ok || pos.szi.IsZero() {
+		return types.FundingData{}, false
+	}
+
+	usdc := pos.szi.Mul(p.mark(coin)).Mul(rate).Neg()
+	p.balance = p.balance.Add(usdc)
+
+	return types.FundingData{
+		User:        p.user,
+		Coin:        coin,
+		FundingRate: rate,
+		Szi:         pos.szi,
+		Type:        "funding",
+		Time:        p.now().UnixMilli(),
+		Usdc:        usdc,
+	}, true
+}
+
+// Liquidate cancels every order and closes every position at the mark,
+// charging taker fees, and returns the closing fills
+func (p *PaperExchange) Liquidate() []types.UserFillData {
+	p.mu.Lock()
+	b := &batch{}
+	for _, o := range p.sortedOrders() {
+		p.remove(o, StatusMarginCanceled, b)
+	}
+
+	for _, coin := range p.coins() {
+		pos := p.positions[coin]
+		if pos.szi.IsZero() {
+			continue
+		}
+
+		mark := p.mark(coin)
+		o := &order{
+			oid:       p.nextOid,
+			coin:      coin,
+			isBuy:     pos.szi.IsNegative(),
+			limitPx:   mark,
+			sz:        pos.szi.Abs(),
+			origSz:    pos.szi.Abs(),
+			timestamp: p.now().UnixMilli(),
+			tif:       types.TifIoc,
+		}
+		p.nextOid++
+		p.fill(o, mark, o.sz, true, b)
+	}
+	p.mu.Unlock()
+
+	p.emit(b)
+	return b.fills
+}
+
+// OpenOrders returns the resting orders, oldest first
+func (p *PaperExchange) OpenOrders() []types.OpenOrder {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	orders := make([]types.OpenOrder, 0, len(p.orders))
+	for _, o := range p.sortedOrders() {
+		orders = append(orders, o.open())
+	}
+	return orders
+}
+
+// Fills returns every simulated fill, oldest first
+func (p *PaperExchange) Fills() []types.UserFillData {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]types.UserFillData{}, p.fills...)
+}
+
+// GetUserState reports the simulated account in clearinghouseState form.
+// Positions are marked at the book mid.
+func (p *PaperExchange) GetUserState(ctx context.Context, user string) (*types.UserState, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	leverage := decimal.NewFromInt(int64(p.config.Leverage))
+	rawUsd := p.balance
+	accountValue := p.balance
+	notional := decimal.Zero
+	marginUsed := decimal.Zero
+
+	state := &types.UserState{AssetPositions: make([]types.AssetPosition, 0)}
+	for _, coin := range p.coins() {
+		pos := p.positions[coin]
+		if pos.szi.IsZero() {
+			continue
+		}
+		mark := p.mark(coin)
+		value := pos.szi.Abs().Mul(mark)
+		unrealized := mark.Sub(pos.entryPx).Mul(pos.szi)
+		margin := value.Div(leverage)
+
+		rawUsd = rawUsd.Sub(pos.szi.Mul(pos.entryPx))
+		accountValue = accountValue.Add(unrealized)
+		notional = notional.Add(value)
+		marginUsed = marginUsed.Add(margin)
+
+		state.AssetPositions = append(state.AssetPositions, types.AssetPosition{
+			Type: "oneWay",
+			Position: types.Position{
+				Coin:          coin,
+				EntryPx:       pos.entryPx,
+				Szi:           pos.szi,
+				Leverage:      types.Leverage{Type: "cross", Value: leverage},
+				UnrealizedPnl: unrealized,
+				PositionValue: value,
+				MarginUsed:    margin,
+			},
+		})
+	}
+
+	state.MarginSummary = types.MarginSummary{
+		AccountValue:    accountValue,
+		TotalMarginUsed: marginUsed,
+		TotalNtlPos:     notional,
+		TotalRawUsd:     rawUsd,
+		WithdrawableUsd: decimal.Max(accountValue.Sub(marginUsed), decimal.Zero),
+	}
+	state.CrossMarginSummary = types.CrossMarginSummary{
+		AccountValue:    accountValue,
+		TotalMarginUsed: marginUsed,
+		TotalNtlPos:     notional,
+		TotalRawUsd:     rawUsd,
+	}
+
+	return state, nil
+}
+
+// GetOrderStatus reports an order's latest status in orderStatus form
+func (p *PaperExchange) GetOrderStatus(ctx context.Context, user string, oid *int64, cloid *string) (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, update := range p.history {
+		if (oid != nil && id == *oid) || (cloid != nil && update.Order.Cloid != nil && *update.Order.Cloid == *cloid) {
+			return map[string]interface{}{"status": "order", "order": update}, nil
+		}
+	}
+	return map[string]interface{}{"status": "unknownOid"}, nil
+}
+
+func (p *PaperExchange) place(req types.OrderRequest, b *batch) types.OrderStatus {
+	if !req.Sz.IsPositive() {
+		return errorStatus(ErrInvalidSize)
+	}
+	if !req.LimitPx.IsPositive() {
+		return errorStatus(ErrInvalidPrice)
+	}
+	if req.OrderType.Trigger != nil {
+		return errorStatus(ErrTriggerUnsupported)
+	}
+
+	tif := types.TifGtc
+	if req.OrderType.Limit != nil && req.OrderType.Limit.Tif != "" {
+		tif = req.OrderType.Limit.Tif
+	}
+	if tif != types.TifGtc && tif != types.TifIoc && tif != types.TifAlo {
+		return errorStatus(ErrUnknownTimeInForce)
+	}
+
+	o := &order{
+		cloid:      req.Cloid,
+		coin:       req.Asset,
+		isBuy:      req.IsBuy,
+		limitPx:    req.LimitPx,
+		sz:         req.Sz,
+		origSz:     req.Sz,
+		timestamp:  p.now().UnixMilli(),
+		reduceOnly: req.ReduceOnly,
+		tif:        tif,
+	}
+
+	szi := p.position(o.coin).szi
+	if o.reduceOnly {
+		if szi.IsZero() || szi.IsPositive() == o.isBuy {
+			return errorStatus(ErrReduceOnly)
+		}
+		o.sz = decimal.Min(o.sz, szi.Abs())
+		o.origSz = o.sz
+	}
+
+	if required := p.initialMargin(o, szi); required.GreaterThan(p.freeMargin()) {
+		return errorStatus(ErrInsufficientMargin)
+	}
+
+	m := p.markets[o.coin]
+	opposite := orderbook.Ask
+	if !o.isBuy {
+		opposite = orderbook.Bid
+	}
+	levels := m.book.Levels(opposite, 0)
+
+	if tif == types.TifAlo {
+		for _, level := range levels {
+			if !crosses(o, level.Price) {
+				break
+			}
+			if m.available(level).IsPositive() {
+				return errorStatus(fmt.Sprintf("%s, bbo was %s", ErrPostOnlyMatch, level.Price))
+			}
+		}
+	}
+
+	o.oid = p.nextOid
+	p.nextOid++
+
+	filled := decimal.Zero
+	notional := decimal.Zero
+	for _, level := range levels {
+		if !o.sz.IsPositive() || !crosses(o, level.Price) {
+			break
+		}
+		sz := decimal.Min(o.sz, m.available(level))
+		if !sz.IsPositive() {
+			continue
+		}
+		m.consume(level.Price, sz)
+		p.fill(o, level.Price, sz, true, b)
+		filled = filled.Add(sz)
+		notional = notional.Add(sz.Mul(level.Price))
+	}
+
+	if o.sz.IsPositive() && tif != types.TifIoc {
+		own := orderbook.Bid
+		if !o.isBuy {
+			own = orderbook.Ask
+		}
+		o.queue = m.book.DepthAt(own, o.limitPx)
+		p.orders[o.oid] = o
+		p.record(o, StatusOpen, b)
+
+		resting := &types.RestingOrder{Oid: o.oid}
+		if o.cloid != nil {
+			resting.Cloid = *o.cloid
+		}
+		return types.OrderStatus{Resting: resting}
+	}
+
+	if filled.IsZero() {
+		return errorStatus(ErrIocNoMatch)
+	}
+	if o.sz.IsZero() {
+		p.record(o, StatusFilled, b)
+	}
+
+	return types.OrderStatus{Filled: &types.FilledOrder{
+		TotalSz: filled,
+		AvgPx:   notional.Div(filled),
+		Oid:     o.oid,
+	}}
+}
+
+// fill executes sz of o at px, updating the position, balance and volume
+func (p *PaperExchange) fill(o *order, px, sz decimal.Decimal, crossed bool, b *batch) {
+	pos := p.position(o.coin)
+	start := pos.szi
+
+	signed := sz
+	if !o.isBuy {
+		signed = sz.Neg()
+	}
+
+	closedPnl := decimal.Zero
+	if start.IsZero() || start.IsPositive() == o.isBuy {
+		total := start.Abs().Add(sz)
+		pos.entryPx = start.Abs().Mul(pos.entryPx).Add(sz.Mul(px)).Div(total)
+	} else {
+		closing := decimal.Min(start.Abs(), sz)
+		closedPnl = px.Sub(pos.entryPx).Mul(closing)
+		if start.IsNegative() {
+			closedPnl = closedPnl.Neg()
+		}
+
+		if sz.GreaterThan(start.Abs()) {
+			pos.entryPx = px
+		} else if sz.Equal(start.Abs()) {
+			pos.entryPx = decimal.Zero
+		}
+	}
+	pos.szi = start.Add(signed)
+	o.sz = o.sz.Sub(sz)
+
+	now := p.now()
+	notional := px.Mul(sz)
+	fee := notional.Mul(p.feeRate(crossed, now))
+	p.balance = p.balance.Add(closedPnl).Sub(fee)
+	p.volumes = append(p.volumes, volume{time: now, notional: notional})
+
+	tid := p.nextTid
+	p.nextTid++
+
+	fill := types.UserFillData{
+		User:          p.user,
+		Coin:          o.coin,
+		Px:            px,
+		Sz:            sz,
+		Side:          o.side(),
+		Time:          now.UnixMilli(),
+		StartPosition: start,
+		Dir:           direction(start, o.isBuy, pos.szi),
+		ClosedPnl:     closedPnl,
+		Hash:          fmt.Sprintf("0x%064x", tid),
+		Oid:           o.oid,
+		Crossed:       crossed,
+		Fee:           fee,
+		Tid:           tid,
+		FeeToken:      "USDC",
+		Cloid:         o.cloid,
+	}
+	p.fills = append(p.fills, fill)
+	b.fills = append(b.fills, fill)
+
+	p.reduceOnly(o.coin, b)
+}
+
+// reduceOnly keeps coin's resting reduce-only orders within the position:
+// they shrink to its size, and are canceled once it is flat or has flipped
+// to their side
+func (p *PaperExchange) reduceOnly(coin string, b *batch) {
+	szi := p.position(coin).szi
+	for _, o := range p.sortedOrders() {
+		// Filled orders are removed by the caller
+		if o.coin != coin || !o.reduceOnly || !o.sz.IsPositive() {
+			continue
+		}
+		if szi.IsZero() || szi.IsPositive() == o.isBuy {
+			p.remove(o, StatusReduceOnlyCanceled, b)
+			continue
+		}
+		if o.sz.GreaterThan(szi.Abs()) {
+			o.sz = szi.Abs()
+			p.record(o, StatusOpen, b)
+		}
+	}
+}
+
+// feeRate returns the taker or maker rate of the tier reached by 14-day
+// volume, dropping volume that has left the window
+func (p *PaperExchange) feeRate(crossed bool, now time.Time) decimal.Decimal {
+	cutoff := now.Add(-feeWindow)
+	for len(p.volumes) > 0 && p.volumes[0].time.Before(cutoff) {
+		p.volumes = p.volumes[1:]
+	}
+
+	traded := p.config.Volume
+	for _, v := range p.volumes {
+		traded = traded.Add(v.notional)
+	}
+
+	tier := p.config.FeeTiers[0]
+	for _, t := range p.config.FeeTiers {
+		if traded.GreaterThanOrEqual(t.MinVolume) {
+			tier = t
+		}
+	}
+
+	if crossed {
+		return tier.Taker
+	}
+	return tier.Maker
+}
+
+// initialMargin is the margin o needs for the part that adds exposure
+// beyond the current position szi
+func (p *PaperExchange) initialMargin(o *order, szi decimal.Decimal) decimal.Decimal {
+	adding := o.sz
+	if !szi.IsZero() && szi.IsPositive() != o.isBuy {
+		adding = decimal.Max(o.sz.Sub(szi.Abs()), decimal.Zero)
+	}
+	return adding.Mul(o.limitPx).Div(decimal.NewFromInt(int64(p.config.Leverage)))
+}
+
+// freeMargin is account value less the margin held by positions and
+// resting orders
+func (p *PaperExchange) freeMargin() decimal.Decimal {
+	leverage := decimal.NewFromInt(int64(p.config.Leverage))
+	free := p.balance
+	for coin, pos := range p.positions {
+		mark := p.mark(coin)
+		free = free.Add(mark.Sub(pos.entryPx).Mul(pos.szi)).Sub(pos.szi.Abs().Mul(mark).Div(leverage))
+	}
+	for _, o := range p.orders {
+		if !o.reduceOnly {
+			free = free.Sub(o.sz.Mul(o.limitPx).Div(leverage))
+		}
+	}
+	return free
+}
+
+// mark values coin at the book mid, falling back to the entry price
+func (p *PaperExchange) mark(coin string) decimal.Decimal {
+	if m, ok := p.markets[coin]; ok {
+		if mid, ok := m.book.Mid(); ok {
+			return mid
+		}
+	}
+	return p.position(coin).entryPx
+}
+
+// record reports a status change of o
+func (p *PaperExchange) record(o *order, status string, b *batch) {
+	update := types.OrderUpdate{Order: o.basic(), Status: status, StatusTimestamp: p.now().UnixMilli()}
+	p.history[o.oid] = update
+	b.updates = append(b.updates, update)
+}
+
+// remove takes a resting order off the book
+func (p *PaperExchange) remove(o *order, status string, b *batch) {
+	delete(p.orders, o.oid)
+	p.record(o, status, b)
+}
+
+// resting returns coin's resting orders on one side, best price first
+func (p *PaperExchange) resting(coin string, isBuy bool) []*order {
+	var orders []*order
+	for _, o := range p.orders {
+		if o.coin == coin && o.isBuy == isBuy {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].limitPx.Equal(orders[j].limitPx) {
+			return orders[i].limitPx.GreaterThan(orders[j].limitPx) == isBuy
+		}
+		return orders[i].oid < orders[j].oid
+	})
+	return orders
+}
+
+func (p *PaperExchange) sortedOrders() []*order {
+	orders := make([]*order, 0, len(p.orders))
+	for _, o := range p.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].oid < orders[j].oid })
+	return orders
+}
+
+func (p *PaperExchange) findByCloid(cloid string) *order {
+	for _, o := range p.orders {
+		if o.cloid != nil && *o.cloid == cloid {
+			return o
+		}
+	}
+	return nil
+}
+
+func (p *PaperExchange) position(coin string) *position {
+	pos, ok := p.positions[coin]
+	if !ok {
+		pos = &position{}
+		p.positions[coin] = pos
+	}
+	return pos
+}
+
+func (p *PaperExchange) coins() []string {
+	coins := make([]string, 0, len(p.positions))
+	for coin := range p.positions {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+	return coins
+}
+
+// wait delays an action by the configured latency
+func (p *PaperExchange) wait(ctx context.Context) error {
+	if p.config.Latency <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(p.config.Latency)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// emit delivers a batch's events, fills first
+func (p *PaperExchange) emit(b *batch) {
+	p.mu.Lock()
+	fillHandlers, orderHandlers := p.fillHandlers, p.orderHandlers
+	p.mu.Unlock()
+
+	if len(b.fills) > 0 {
+		data := types.UserFillsData{User: p.user, Fills: b.fills}
+		for _, handler := range fillHandlers {
+			handler(data)
+		}
+	}
+	if len(b.updates) > 0 {
+		for _, handler := range orderHandlers {
+			handler(b.updates)
+		}
+	}
+}
+
+// available is the size left at level after paper fills since the snapshot
+func (m *market) available(level types.OrderBookLevel) decimal.Decimal {
+	return level.Size.Sub(m.consumed[level.Price.String()])
+}
+
+func (m *market) consume(px, sz decimal.Decimal) {
+	key := px.String()
+	m.consumed[key] = m.consumed[key].Add(sz)
+}
+
+func crosses(o *order, px decimal.Decimal) bool {
+	if o.isBuy {
+		return px.LessThanOrEqual(o.limitPx)
+	}
+	return px.GreaterThanOrEqual(o.limitPx)
+}
+
+// direction describes a fill the way the exchange labels it
+func direction(start decimal.Decimal, isBuy bool, end decimal.Decimal) string {
+	switch {
+	case start.IsZero() || start.IsPositive() == isBuy:
+		if isBuy {
+			return "Open Long"
+		}
+		return "Open Short"
+	case !end.IsZero() && end.IsPositive() != start.IsPositive():
+		if isBuy {
+			return "Short > Long"
+		}
+		return "Long > Short"
+	case isBuy:
+		return "Close Short"
+	default:
+		return "Close Long"
+	}
+}
+
+func orderResponse(statuses []types.OrderStatus) *types.OrderResponse {
+	resp := &types.OrderResponse{Status: "ok"}
+	resp.Response.Type = "order"
+	resp.Response.Data.Statuses = statuses
+	return resp
+}
+
+func errorStatus(message string) types.OrderStatus {
+	return types.OrderStatus{Error: &message}
+}
@@ -0,0 +1,238 @@
+package paper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+const testUser = "0x1234567890123456789012345678901234567890"
+
+func levels(pairs ...string) []interface{} {
+	out := make([]interface{}, 0)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out = append(out, map[string]interface{}{"px": pairs[i], "sz": pairs[i+1], "n": 1})
+	}
+	return out
+}
+
+func book(time int64, bids, asks []interface{}) types.L2BookData {
+	return types.L2BookData{Coin: "BTC", Time: time, Levels: [][]interface{}{bids, asks}}
+}
+
+func place(t *testing.T, p *PaperExchange, order types.OrderRequest) types.OrderStatus {
+	t.Helper()
+	resp, err := p.PlaceOrder(context.Background(), order)
+	if err != nil {
+		t.Fatalf("Failed to place order: %v", err)
+	}
+	return resp.Response.Data.Statuses[0]
+}
+
+func TestTakerFills(t *testing.T) {
+	p := NewPaperExchange(nil, testUser, Config{Balance: hltest.Dec("100000")})
+	var fills []types.UserFillData
+	p.OnFills(func(data types.UserFillsData) { fills = append(fills, data.Fills...) })
+
+	p.Track("BTC")
+	p.HandleBook(book(1, levels("99", "1"), levels("100", "1", "101", "2")))
+
+	status := place(t, p, hltest.Limit("BTC", true, "101", "2", types.TifIoc))
+	if status.Filled == nil || !status.Filled.TotalSz.Equal(hltest.Dec("2")) || !status.Filled.AvgPx.Equal(hltest.Dec("100.5")) {
+		t.Fatalf("Expected 2 filled at 100.5, got %+v", status)
+	}
+	if len(fills) != 2 || !fills[0].Crossed || !fills[0].Fee.Equal(hltest.Dec("0.045")) || fills[1].Dir != "Open Long" {
+		t.Errorf("Unexpected fills: %+v", fills)
+	}
+
+	// Liquidity taken stays gone until the next snapshot
+	status = place(t, p, hltest.Limit("BTC", true, "101", "2", types.TifIoc))
+	if status.Filled == nil || !status.Filled.TotalSz.Equal(hltest.Dec("1")) {
+		t.Errorf("Expected a partial fill of 1, got %+v", status)
+	}
+	if status = place(t, p, hltest.Limit("BTC", true, "101", "1", types.TifIoc)); status.Error == nil || *status.Error != ErrIocNoMatch {
+		t.Errorf("Expected no match, got %+v", status)
+	}
+
+	p.HandleBook(book(2, levels("99", "1"), levels("100", "1")))
+	if status = place(t, p, hltest.Limit("BTC", true, "100", "1", types.TifAlo)); status.Error == nil {
+		t.Errorf("Expected post only rejection")
+	}
+	if status = place(t, p, hltest.Limit("BTC", true, "100", "1", types.TifIoc)); status.Filled == nil {
+		t.Errorf("Expected fill after the book refreshed, got %+v", status)
+	}
+
+	// 4 long from 402 notional, marked at 99.5, less 0.1809 taker fees
+	state, _ := p.GetUserState(context.Background(), testUser)
+	position := state.AssetPositions[0].Position
+	if !position.Szi.Equal(hltest.Dec("4")) || !position.EntryPx.Equal(hltest.Dec("100.5")) || !position.UnrealizedPnl.Equal(hltest.Dec("-4")) {
+		t.Errorf("Expected 4 @ 100.5 down 4, got %s @ %s, %s", position.Szi, position.EntryPx, position.UnrealizedPnl)
+	}
+	if !state.MarginSummary.AccountValue.Equal(hltest.Dec("99995.8191")) {
+		t.Errorf("Expected account value 99995.8191, got %s", state.MarginSummary.AccountValue)
+	}
+}
+
+func TestQueuePosition(t *testing.T) {
+	p := NewPaperExchange(nil, testUser, Config{Balance: hltest.Dec("100000")})
+	var updates []types.OrderUpdate
+	p.OnOrderUpdates(func(u []types.OrderUpdate) { updates = append(updates, u...) })
+
+	p.Track("BTC")
+	p.HandleBook(book(1, levels("99", "5"), levels("100", "5")))
+
+	status := place(t, p, hltest.Limit("BTC", true, "99", "1", types.TifGtc))
+	if status.Resting == nil || len(updates) != 1 || updates[0].Status != StatusOpen {
+		t.Fatalf("Expected resting order, got %+v", status)
+	}
+
+	// 5 ahead: 3 trade, then the book shows cancels leaving 1 ahead
+	p.HandleTrades([]types.TradeData{{Coin: "BTC", Side: "A", Px: hltest.Dec("99"), Sz: hltest.Dec("3")}})
+	if len(p.Fills()) != 0 {
+		t.Fatalf("Expected no fill while queued")
+	}
+	p.HandleBook(book(2, levels("99", "1"), levels("100", "5")))
+	p.HandleTrades([]types.TradeData{{Coin: "BTC", Side: "A", Px: hltest.Dec("99"), Sz: hltest.Dec("1.5")}})
+
+	fills := p.Fills()
+	if len(fills) != 1 || !fills[0].Sz.Equal(hltest.Dec("0.5")) || fills[0].Crossed || !fills[0].Fee.Equal(hltest.Dec("0.007425")) {
+		t.Fatalf("Expected a 0.5 maker fill, got %+v", fills)
+	}
+
+	// Buy aggressors do not touch bids; a trade through the price does
+	p.HandleTrades([]types.TradeData{{Coin: "BTC", Side: "B", Px: hltest.Dec("99"), Sz: hltest.Dec("10")}})
+	p.HandleTrades([]types.TradeData{{Coin: "BTC", Side: "A", Px: hltest.Dec("98"), Sz: hltest.Dec("2")}})
+	if len(p.Fills()) != 2 || len(p.OpenOrders()) != 0 {
+		t.Errorf("Expected order filled through the price")
+	}
+	if last := updates[len(updates)-1]; last.Status != StatusFilled || !last.Order.Sz.IsZero() {
+		t.Errorf("Expected filled update, got %+v", last)
+	}
+
+	// A book that moves through a resting order fills it up to the crossing size
+	place(t, p, hltest.Limit("BTC", false, "101", "1", types.TifGtc))
+	p.HandleBook(book(3, levels("102", "0.4"), levels("103", "5")))
+	open := p.OpenOrders()
+	if len(open) != 1 || !open[0].Sz.Equal(hltest.Dec("0.6")) {
+		t.Errorf("Expected 0.6 left resting, got %+v", open)
+	}
+	if last := p.Fills()[2]; !last.Px.Equal(hltest.Dec("101")) || last.Dir != "Close Long" {
+		t.Errorf("Expected close at the order price, got %+v", last)
+	}
+}
+
+func TestMarginAndCancels(t *testing.T) {
+	p := NewPaperExchange(nil, testUser, Config{Balance: hltest.Dec("1000"), Leverage: 2})
+	ctx := context.Background()
+	p.Track("BTC")
+	p.HandleBook(book(1, levels("99", "10"), levels("101", "10")))
+
+	if status := place(t, p, hltest.Limit("BTC", true, "100", "15", types.TifGtc)); status.Resting == nil {
+		t.Fatalf("Expected resting order, got %+v", status)
+	}
+	if status := place(t, p, hltest.Limit("BTC", true, "100", "6", types.TifGtc)); status.Error == nil || *status.Error != ErrInsufficientMargin {
+		t.Errorf("Expected insufficient margin, got %+v", status)
+	}
+	reduce := hltest.Limit("BTC", false, "100", "1", types.TifGtc)
+	reduce.ReduceOnly = true
+	if status := place(t, p, reduce); status.Error == nil || *status.Error != ErrReduceOnly {
+		t.Errorf("Expected reduce only rejection, got %+v", status)
+	}
+
+	cloid := "0x00000000000000000000000000000001"
+	order := hltest.Limit("BTC", false, "110", "1", types.TifGtc)
+	order.Cloid = &cloid
+	place(t, p, order)
+
+	statuses, err := p.CancelByCloid(ctx, "BTC", cloid, "0x00000000000000000000000000000002")
+	if err != nil || len(statuses) != 2 || !statuses[0].Success || statuses[1].Error == nil {
+		t.Errorf("Expected one cancel and one failure, got %+v: %v", statuses, err)
+	}
+	status, _ := p.GetOrderStatus(ctx, testUser, nil, &cloid)
+	if update, ok := status["order"].(types.OrderUpdate); !ok || update.Status != StatusCanceled {
+		t.Errorf("Expected canceled status, got %+v", status)
+	}
+
+	isBuy := true
+	results, err := p.CancelAll(ctx, types.CancelFilter{IsBuy: &isBuy})
+	if err != nil || len(results) != 1 || len(p.OpenOrders()) != 0 {
+		t.Errorf("Expected the bid cancelled, got %+v: %v", results, err)
+	}
+
+	slow := NewPaperExchange(nil, testUser, Config{Balance: hltest.Dec("1000"), Latency: time.Second})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := slow.PlaceOrder(cancelled, hltest.Limit("BTC", true, "100", "1", types.TifGtc)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context cancelled during latency, got %v", err)
+	}
+}
+
+func TestModifyKeepsOrderOnRejection(t *testing.T) {
+	p := NewPaperExchange(nil, testUser, Config{Balance: hltest.Dec("1000"), Leverage: 2})
+	ctx := context.Background()
+	p.Track("BTC")
+	p.HandleBook(book(1, levels("99", "10"), levels("101", "10")))
+
+	status := place(t, p, hltest.Limit("BTC", true, "100", "15", types.TifGtc))
+	if status.Resting == nil {
+		t.Fatalf("Expected resting order, got %+v", status)
+	}
+	oid := status.Resting.Oid
+
+	// 25 at 100 needs 1250 of margin even with the original's released
+	resp, err := p.BatchModify(ctx, []types.ModifyRequest{{Oid: &oid, Order: hltest.Limit("BTC", true, "100", "25", types.TifGtc)}})
+	if err != nil {
+		t.Fatalf("BatchModify failed: %v", err)
+	}
+	if status := resp.Response.Data.Statuses[0]; status.Error == nil || *status.Error != ErrInsufficientMargin {
+		t.Errorf("Expected insufficient margin, got %+v", status)
+	}
+	if open := p.OpenOrders(); len(open) != 1 || open[0].Oid != oid || !open[0].Sz.Equal(hltest.Dec("15")) {
+		t.Errorf("Expected the original left resting, got %+v", open)
+	}
+
+	resp, err = p.BatchModify(ctx, []types.ModifyRequest{{Oid: &oid, Order: hltest.Limit("BTC", true, "100", "5", types.TifGtc)}})
+	if err != nil {
+		t.Fatalf("BatchModify failed: %v", err)
+	}
+	if open := p.OpenOrders(); len(open) != 1 || open[0].Oid == oid || !open[0].Sz.Equal(hltest.Dec("5")) {
+		t.Errorf("Expected the replacement resting alone, got %+v", open)
+	}
+}
+
+func TestReduceOnlyFollowsPosition(t *testing.T) {
+	p := NewPaperExchange(nil, testUser, Config{Balance: hltest.Dec("100000")})
+	var updates []types.OrderUpdate
+	p.OnOrderUpdates(func(u []types.OrderUpdate) { updates = append(updates, u...) })
+	p.Track("BTC")
+	p.HandleBook(book(1, levels("99", "10"), levels("101", "10")))
+
+	place(t, p, hltest.Limit("BTC", true, "101", "2", types.TifIoc))
+	reduce := hltest.Limit("BTC", false, "105", "2", types.TifGtc)
+	reduce.ReduceOnly = true
+	if status := place(t, p, reduce); status.Resting == nil {
+		t.Fatalf("Expected resting reduce only order, got %+v", status)
+	}
+
+	// Closing part of the position elsewhere shrinks the order to match
+	place(t, p, hltest.Limit("BTC", false, "99", "1.5", types.TifIoc))
+	if open := p.OpenOrders(); len(open) != 1 || !open[0].Sz.Equal(hltest.Dec("0.5")) {
+		t.Errorf("Expected the order capped at 0.5, got %+v", open)
+	}
+
+	place(t, p, hltest.Limit("BTC", false, "99", "0.5", types.TifIoc))
+	if open := p.OpenOrders(); len(open) != 0 {
+		t.Errorf("Expected the order canceled once flat, got %+v", open)
+	}
+	canceled := false
+	for _, update := range updates {
+		canceled = canceled || (update.Order.Side == "A" && update.Order.LimitPx.Equal(hltest.Dec("105")) && update.Status == StatusReduceOnlyCanceled)
+	}
+	if !canceled {
+		t.Errorf("Expected a reduce only cancel, got %+v", updates)
+	}
+}
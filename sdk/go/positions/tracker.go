@@ -0,0 +1,572 @@
+// Package positions maintains live perp positions and PnL for one user from
+// account state, fills, funding payments and mid prices.
+package positions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// StateSource fetches account state. *client.InfoClient satisfies it.
+type StateSource interface {
+	GetUserState(ctx context.Context, user string) (*types.UserState, error)
+}
+
+// Position is a live perp position. RealizedPnl, Fees and Funding
+// accumulate from the moment the tracker starts.
+type Position struct {
+	Coin          string
+	Szi           decimal.Decimal // Signed size, positive when long
+	EntryPx       decimal.Decimal
+	MarkPx        decimal.Decimal
+	UnrealizedPnl decimal.Decimal
+	RealizedPnl   decimal.Decimal
+	Fees          decimal.Decimal
+	Funding       decimal.Decimal // Net funding, negative when paid
+	Leverage      types.Leverage
+	Updated       time.Time
+}
+
+// Notional returns the absolute position value at the mark price
+func (p Position) Notional() decimal.Decimal {
+	return p.Szi.Abs().Mul(p.MarkPx)
+}
+
+// NetPnl returns realized plus unrealized PnL, after fees and funding
+func (p Position) NetPnl() decimal.Decimal {
+	return p.RealizedPnl.Add(p.UnrealizedPnl).Sub(p.Fees).Add(p.Funding)
+}
+
+// Summary aggregates all positions
+type Summary struct {
+	AccountValue  decimal.Decimal // Cash plus positions marked to market
+	Notional      decimal.Decimal
+	RealizedPnl   decimal.Decimal
+	UnrealizedPnl decimal.Decimal
+	Fees          decimal.Decimal
+	Funding       decimal.Decimal
+}
+
+// Drift is a difference between tracked state and clearinghouseState
+type Drift struct {
+	Coin          string
+	LocalSzi      decimal.Decimal
+	RemoteSzi     decimal.Decimal
+	LocalEntryPx  decimal.Decimal
+	RemoteEntryPx decimal.Decimal
+}
+
+// Config configures a PositionTracker
+type Config struct {
+	ReconcileInterval time.Duration // How often Start reconciles (default 30s)
+	EntryTolerance    float64       // Fractional entry price difference reported as drift (default 0.0001)
+	Resync            bool          // Adopt the exchange's state when drift is found
+	CacheSize         int           // Fills and fundings remembered for deduplication (default 10000)
+}
+
+// PositionTracker seeds positions from clearinghouseState, keeps them current
+// from userFills, userFundings and allMids, and periodically reconciles
+// against the exchange. Spot fills are ignored.
+type PositionTracker struct {
+	state  StateSource
+	user   string
+	config Config
+
+	mu        sync.Mutex
+	positions map[string]*Position
+	rawUsd    decimal.Decimal
+	seen      *websocket.DedupeCache
+	fundings  *websocket.DedupeCache
+	applied   uint64 // Fills and fundings applied, to detect ones racing a reconcile
+	updates   []func(Position)
+	drifts    []func([]Drift)
+	onError   func(error)
+	ws        websocket.Subscriber
+	subIDs    []string
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewPositionTracker creates a tracker for user
+func NewPositionTracker(state StateSource, user string, config Config) *PositionTracker {
+	if config.ReconcileInterval <= 0 {
+		config.ReconcileInterval = 30 * time.Second
+	}
+	if config.EntryTolerance <= 0 {
+		config.EntryTolerance = 0.0001
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+
+	return &PositionTracker{
+		state:     state,
+		user:      user,
+		config:    config,
+		positions: make(map[string]*Position),
+		seen:      websocket.NewDedupeCache(config.CacheSize),
+		fundings:  websocket.NewDedupeCache(config.CacheSize),
+	}
+}
+
+// OnUpdate registers a handler called with a position after each change
+func (t *PositionTracker) OnUpdate(handler func(Position)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.updates = append(t.updates, handler)
+}
+
+// OnDrift registers a handler called when reconciliation finds drift
+func (t *PositionTracker) OnDrift(handler func([]Drift)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.drifts = append(t.drifts, handler)
+}
+
+// OnError sets a callback for failures in the background reconcile loop
+func (t *PositionTracker) OnError(handler func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onError = handler
+}
+
+// Seed replaces tracked positions with the exchange's current state
+func (t *PositionTracker) Seed(ctx context.Context) error {
+	state, err := t.state.GetUserState(ctx, t.user)
+	if err != nil {
+		return fmt.Errorf("failed to get user state: %w", err)
+	}
+
+	t.mu.Lock()
+	t.rawUsd = state.MarginSummary.TotalRawUsd
+	remote := remotePositions(state)
+	for coin, p := range t.positions {
+		if _, ok := remote[coin]; !ok {
+			p.Szi, p.EntryPx, p.UnrealizedPnl = decimal.Zero, decimal.Zero, decimal.Zero
+		}
+	}
+	var changed []Position
+	for coin, r := range remote {
+		p := t.position(coin)
+		t.adopt(p, r)
+		changed = append(changed, *p)
+	}
+	t.mu.Unlock()
+
+	t.notify(changed)
+	return nil
+}
+
+// Subscribe feeds the tracker from userFills, userFundings and allMids on
+// ws. Fill and funding snapshots are only recorded, since the seeded state
+// already reflects them.
+func (t *PositionTracker) Subscribe(ws websocket.Subscriber) error {
+	subs := []struct {
+		sub    types.WSSubscription
+		handle func(raw json.RawMessage) error
+	}{
+		{types.WSSubscription{Type: "userFills", User: t.user}, func(raw json.RawMessage) error {
+			var data types.UserFillsData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			t.HandleFills(data)
+			return nil
+		}},
+		{types.WSSubscription{Type: "userFundings", User: t.user}, func(raw json.RawMessage) error {
+			var data types.UserFundingsData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			t.HandleFundings(data)
+			return nil
+		}},
+		{types.WSSubscription{Type: "allMids"}, func(raw json.RawMessage) error {
+			var data types.AllMidsData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			t.HandleMids(data.Mids)
+			return nil
+		}},
+	}
+
+	var subIDs []string
+	for _, s := range subs {
+		subID, err := ws.Subscribe(s.sub, s.handle)
+		if err != nil {
+			for _, id := range subIDs {
+				ws.Unsubscribe(id)
+			}
+			return fmt.Errorf("failed to subscribe to %s: %w", s.sub.Type, err)
+		}
+		subIDs = append(subIDs, subID)
+	}
+
+	t.mu.Lock()
+	t.ws = ws
+	t.subIDs = append(t.subIDs, subIDs...)
+	t.mu.Unlock()
+
+	return nil
+}
+
+// Unsubscribe stops consuming WebSocket streams
+func (t *PositionTracker) Unsubscribe() error {
+	t.mu.Lock()
+	ws, subIDs := t.ws, t.subIDs
+	t.ws, t.subIDs = nil, nil
+	t.mu.Unlock()
+
+	var errs []error
+	for _, subID := range subIDs {
+		if err := ws.Unsubscribe(subID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleFills applies a userFills message. Fills are deduplicated by trade
+// ID over the last Config.CacheSize fills.
+func (t *PositionTracker) HandleFills(data types.UserFillsData) {
+	t.mu.Lock()
+	var changed []Position
+	for _, fill := range data.Fills {
+		if !t.seen.Add(websocket.FillKey(fill)) {
+			continue
+		}
+		if data.IsSnapshot || isSpot(fill.Coin) {
+			continue
+		}
+
+		p := t.position(fill.Coin)
+		signed := fill.Sz
+		if fill.Side != "B" {
+			signed = signed.Neg()
+		}
+
+		// The fill's start position is authoritative, so a missed fill is
+		// corrected here rather than compounding
+		start := fill.StartPosition
+		end := start.Add(signed)
+		switch {
+		case start.IsZero() || start.IsPositive() == signed.IsPositive():
+			p.EntryPx = start.Abs().Mul(p.EntryPx).Add(fill.Sz.Mul(fill.Px)).Div(end.Abs())
+		case end.IsZero():
+			p.EntryPx = decimal.Zero
+		case end.IsPositive() != start.IsPositive():
+			p.EntryPx = fill.Px
+		}
+		p.Szi = end
+		p.RealizedPnl = p.RealizedPnl.Add(fill.ClosedPnl)
+		p.Fees = p.Fees.Add(fill.Fee)
+		t.rawUsd = t.rawUsd.Sub(signed.Mul(fill.Px)).Sub(fill.Fee)
+		if p.MarkPx.IsZero() {
+			p.MarkPx = fill.Px
+		}
+		t.mark(p, time.UnixMilli(fill.Time))
+		t.applied++
+		changed = append(changed, *p)
+	}
+	t.mu.Unlock()
+
+	t.notify(changed)
+}
+
+// HandleFundings applies a userFundings message
+func (t *PositionTracker) HandleFundings(data types.UserFundingsData) {
+	t.mu.Lock()
+	var changed []Position
+	for _, funding := range data.Fundings {
+		key := fmt.Sprintf("%s:%d", funding.Coin, funding.Time)
+		if !t.fundings.Add(key) {
+			continue
+		}
+		if data.IsSnapshot {
+			continue
+		}
+
+		p := t.position(funding.Coin)
+		p.Funding = p.Funding.Add(funding.Usdc)
+		p.Updated = time.UnixMilli(funding.Time)
+		t.rawUsd = t.rawUsd.Add(funding.Usdc)
+		t.applied++
+		changed = append(changed, *p)
+	}
+	t.mu.Unlock()
+
+	t.notify(changed)
+}
+
+// HandleMids marks tracked positions to the given mid prices
+func (t *PositionTracker) HandleMids(mids map[string]string) {
+	t.mu.Lock()
+	var changed []Position
+	now := time.Now()
+	for coin, p := range t.positions {
+		mid, ok := mids[coin]
+		if !ok {
+			continue
+		}
+		px, err := decimal.NewFromString(mid)
+		if err != nil || px.Equal(p.MarkPx) {
+			continue
+		}
+		p.MarkPx = px
+		t.mark(p, now)
+		if !p.Szi.IsZero() {
+			changed = append(changed, *p)
+		}
+	}
+	t.mu.Unlock()
+
+	t.notify(changed)
+}
+
+// Position returns the tracked position for coin
+func (t *PositionTracker) Position(coin string) (Position, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.positions[coin]
+	if !ok {
+		return Position{Coin: coin}, false
+	}
+	return *p, true
+}
+
+// Positions returns every open position, sorted by coin
+func (t *PositionTracker) Positions() []Position {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	positions := make([]Position, 0, len(t.positions))
+	for _, p := range t.positions {
+		if !p.Szi.IsZero() {
+			positions = append(positions, *p)
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Coin < positions[j].Coin })
+	return positions
+}
+
+// Summary aggregates PnL across every coin traded since the tracker started
+func (t *PositionTracker) Summary() Summary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := Summary{AccountValue: t.rawUsd}
+	for _, p := range t.positions {
+		summary.AccountValue = summary.AccountValue.Add(p.Szi.Mul(p.MarkPx))
+		summary.Notional = summary.Notional.Add(p.Notional())
+		summary.RealizedPnl = summary.RealizedPnl.Add(p.RealizedPnl)
+		summary.UnrealizedPnl = summary.UnrealizedPnl.Add(p.UnrealizedPnl)
+		summary.Fees = summary.Fees.Add(p.Fees)
+		summary.Funding = summary.Funding.Add(p.Funding)
+	}
+	return summary
+}
+
+// Reconcile compares tracked positions with clearinghouseState and returns
+// any drift. With Resync set the exchange's state is adopted. A fill or
+// funding applied while the state is fetched may be missing from it, so
+// such a round is skipped and reports no drift; the next one checks again.
+func (t *PositionTracker) Reconcile(ctx context.Context) ([]Drift, error) {
+	t.mu.Lock()
+	applied := t.applied
+	t.mu.Unlock()
+
+	state, err := t.state.GetUserState(ctx, t.user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user state: %w", err)
+	}
+	remote := remotePositions(state)
+	tolerance := decimal.NewFromFloat(t.config.EntryTolerance)
+
+	t.mu.Lock()
+	if t.applied != applied {
+		t.mu.Unlock()
+		return nil, nil
+	}
+	coins := make(map[string]struct{})
+	for coin := range t.positions {
+		coins[coin] = struct{}{}
+	}
+	for coin := range remote {
+		coins[coin] = struct{}{}
+	}
+
+	var drifts []Drift
+	var changed []Position
+	for coin := range coins {
+		local, ok := t.positions[coin]
+		if !ok {
+			local = &Position{Coin: coin}
+		}
+		r, ok := remote[coin]
+		if !ok {
+			r = types.Position{Coin: coin}
+		}
+
+		entryDrift := false
+		if !r.EntryPx.IsZero() {
+			diff := local.EntryPx.Sub(r.EntryPx).Abs().Div(r.EntryPx)
+			entryDrift = diff.GreaterThan(tolerance)
+		}
+		if local.Szi.Equal(r.Szi) && !entryDrift {
+			continue
+		}
+
+		drifts = append(drifts, Drift{
+			Coin:          coin,
+			LocalSzi:      local.Szi,
+			RemoteSzi:     r.Szi,
+			LocalEntryPx:  local.EntryPx,
+			RemoteEntryPx: r.EntryPx,
+		})
+		if t.config.Resync {
+			p := t.position(coin)
+			t.adopt(p, r)
+			changed = append(changed, *p)
+		}
+	}
+	if t.config.Resync && len(drifts) > 0 {
+		t.rawUsd = state.MarginSummary.TotalRawUsd
+	}
+	handlers := append([]func([]Drift){}, t.drifts...)
+	t.mu.Unlock()
+
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Coin < drifts[j].Coin })
+	if len(drifts) > 0 {
+		for _, handler := range handlers {
+			handler(drifts)
+		}
+	}
+	t.notify(changed)
+
+	return drifts, nil
+}
+
+// Start reconciles in the background until Stop or ctx is done
+func (t *PositionTracker) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cancel != nil {
+		return fmt.Errorf("position tracker already started")
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	go t.loop(loopCtx, t.done)
+
+	return nil
+}
+
+// Stop stops the background reconcile loop
+func (t *PositionTracker) Stop() {
+	t.mu.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (t *PositionTracker) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(t.config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := t.Reconcile(ctx); err != nil && ctx.Err() == nil {
+				t.mu.Lock()
+				handler := t.onError
+				t.mu.Unlock()
+				if handler != nil {
+					handler(err)
+				}
+			}
+		}
+	}
+}
+
+// position returns the tracked position for coin, creating it if needed.
+// Callers hold t.mu.
+func (t *PositionTracker) position(coin string) *Position {
+	p, ok := t.positions[coin]
+	if !ok {
+		p = &Position{Coin: coin}
+		t.positions[coin] = p
+	}
+	return p
+}
+
+// adopt copies the exchange's view of a position. Callers hold t.mu.
+func (t *PositionTracker) adopt(p *Position, r types.Position) {
+	p.Szi = r.Szi
+	p.EntryPx = r.EntryPx
+	p.Leverage = r.Leverage
+	if !r.Szi.IsZero() && r.PositionValue.IsPositive() {
+		p.MarkPx = r.PositionValue.Div(r.Szi.Abs())
+	}
+	t.mark(p, time.Now())
+}
+
+// mark recomputes unrealized PnL. Callers hold t.mu.
+func (t *PositionTracker) mark(p *Position, at time.Time) {
+	p.UnrealizedPnl = p.MarkPx.Sub(p.EntryPx).Mul(p.Szi)
+	if p.Szi.IsZero() {
+		p.UnrealizedPnl = decimal.Zero
+	}
+	p.Updated = at
+}
+
+func (t *PositionTracker) notify(changed []Position) {
+	if len(changed) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	handlers := append([]func(Position){}, t.updates...)
+	t.mu.Unlock()
+
+	for _, p := range changed {
+		for _, handler := range handlers {
+			handler(p)
+		}
+	}
+}
+
+func remotePositions(state *types.UserState) map[string]types.Position {
+	positions := make(map[string]types.Position, len(state.AssetPositions))
+	for _, ap := range state.AssetPositions {
+		positions[ap.Position.Coin] = ap.Position
+	}
+	return positions
+}
+
+// isSpot reports whether coin names a spot pair ("PURR/USDC" or "@107")
+func isSpot(coin string) bool {
+	return strings.HasPrefix(coin, "@") || strings.Contains(coin, "/")
+}
@@ -0,0 +1,243 @@
+package positions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const testUser = "0x1234567890123456789012345678901234567890"
+
+type fakeState struct {
+	state   types.UserState
+	fetched func() // Called after the state is read, when set
+}
+
+func (f *fakeState) GetUserState(ctx context.Context, user string) (*types.UserState, error) {
+	state := f.state
+	if f.fetched != nil {
+		f.fetched()
+	}
+	return &state, nil
+}
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func userState(rawUsd string, positions ...types.Position) types.UserState {
+	state := types.UserState{MarginSummary: types.MarginSummary{TotalRawUsd: dec(rawUsd)}}
+	for _, p := range positions {
+		state.AssetPositions = append(state.AssetPositions, types.AssetPosition{Type: "oneWay", Position: p})
+	}
+	return state
+}
+
+func fill(tid int64, side, px, sz, start, closedPnl, fee string) types.UserFillData {
+	return types.UserFillData{
+		Coin:          "BTC",
+		Side:          side,
+		Px:            dec(px),
+		Sz:            dec(sz),
+		StartPosition: dec(start),
+		ClosedPnl:     dec(closedPnl),
+		Fee:           dec(fee),
+		Tid:           tid,
+		Time:          time.Now().UnixMilli(),
+	}
+}
+
+func TestPositionTracking(t *testing.T) {
+	source := &fakeState{state: userState("-40000", types.Position{
+		Coin: "BTC", Szi: dec("1"), EntryPx: dec("50000"), PositionValue: dec("51000"),
+		Leverage: types.Leverage{Type: "cross", Value: dec("10")},
+	})}
+	tracker := NewPositionTracker(source, testUser, Config{})
+	ctx := context.Background()
+
+	if err := tracker.Seed(ctx); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	if s := tracker.Summary(); !s.AccountValue.Equal(dec("11000")) || !s.UnrealizedPnl.Equal(dec("1000")) {
+		t.Errorf("Expected account value 11000 with 1000 unrealized, got %s and %s", s.AccountValue, s.UnrealizedPnl)
+	}
+
+	// Snapshot and replayed fills do not move the position
+	tracker.HandleFills(types.UserFillsData{IsSnapshot: true, Fills: []types.UserFillData{fill(1, "B", "50000", "1", "0", "0", "0")}})
+	reduce := fill(2, "A", "52000", "0.5", "1", "1000", "5")
+	tracker.HandleFills(types.UserFillsData{Fills: []types.UserFillData{reduce}})
+	tracker.HandleFills(types.UserFillsData{Fills: []types.UserFillData{reduce}})
+	tracker.HandleMids(map[string]string{"BTC": "52000"})
+
+	p, _ := tracker.Position("BTC")
+	if !p.Szi.Equal(dec("0.5")) || !p.EntryPx.Equal(dec("50000")) {
+		t.Errorf("Expected 0.5 @ 50000, got %s @ %s", p.Szi, p.EntryPx)
+	}
+	if !p.RealizedPnl.Equal(dec("1000")) || !p.Fees.Equal(dec("5")) || !p.UnrealizedPnl.Equal(dec("1000")) {
+		t.Errorf("Unexpected PnL: realized %s fees %s unrealized %s", p.RealizedPnl, p.Fees, p.UnrealizedPnl)
+	}
+	if s := tracker.Summary(); !s.AccountValue.Equal(dec("11995")) {
+		t.Errorf("Expected account value 11995, got %s", s.AccountValue)
+	}
+
+	// Flipping through zero re-bases the entry price
+	tracker.HandleFills(types.UserFillsData{Fills: []types.UserFillData{fill(3, "A", "52000", "1", "0.5", "1000", "0")}})
+	tracker.HandleFundings(types.UserFundingsData{Fundings: []types.FundingData{{Coin: "BTC", Usdc: dec("-2"), Time: 1}}})
+	p, _ = tracker.Position("BTC")
+	if !p.Szi.Equal(dec("-0.5")) || !p.EntryPx.Equal(dec("52000")) || !p.Funding.Equal(dec("-2")) {
+		t.Errorf("Expected -0.5 @ 52000 with -2 funding, got %s @ %s, %s", p.Szi, p.EntryPx, p.Funding)
+	}
+	if !p.NetPnl().Equal(dec("1993")) {
+		t.Errorf("Expected net PnL 1993, got %s", p.NetPnl())
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	source := &fakeState{state: userState("0", types.Position{Coin: "ETH", Szi: dec("2"), EntryPx: dec("3000")})}
+	tracker := NewPositionTracker(source, testUser, Config{})
+	ctx := context.Background()
+
+	if err := tracker.Seed(ctx); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	if drifts, err := tracker.Reconcile(ctx); err != nil || len(drifts) != 0 {
+		t.Fatalf("Expected no drift, got %v: %v", drifts, err)
+	}
+
+	var reported []Drift
+	tracker.OnDrift(func(d []Drift) { reported = d })
+	source.state = userState("0",
+		types.Position{Coin: "ETH", Szi: dec("3"), EntryPx: dec("3100")},
+		types.Position{Coin: "SOL", Szi: dec("-10"), EntryPx: dec("150")},
+	)
+
+	drifts, err := tracker.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(drifts) != 2 || drifts[0].Coin != "ETH" || !drifts[0].RemoteSzi.Equal(dec("3")) || drifts[1].Coin != "SOL" {
+		t.Errorf("Expected ETH and SOL drift, got %+v", drifts)
+	}
+	if len(reported) != 2 {
+		t.Errorf("Expected drift handler to be called")
+	}
+	if p, _ := tracker.Position("ETH"); !p.Szi.Equal(dec("2")) {
+		t.Errorf("Expected local state kept without Resync, got %s", p.Szi)
+	}
+
+	tracker.config.Resync = true
+	tracker.Reconcile(ctx)
+	if p, _ := tracker.Position("ETH"); !p.Szi.Equal(dec("3")) || !p.EntryPx.Equal(dec("3100")) {
+		t.Errorf("Expected resync to 3 @ 3100, got %s @ %s", p.Szi, p.EntryPx)
+	}
+	if positions := tracker.Positions(); len(positions) != 2 {
+		t.Errorf("Expected 2 positions after resync, got %d", len(positions))
+	}
+}
+
+func TestReconcileSkipsRacingFill(t *testing.T) {
+	source := &fakeState{state: userState("0", types.Position{Coin: "BTC", Szi: dec("1"), EntryPx: dec("50000")})}
+	tracker := NewPositionTracker(source, testUser, Config{Resync: true})
+	ctx := context.Background()
+	if err := tracker.Seed(ctx); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	// A fill lands after the exchange's state was read
+	source.fetched = func() {
+		source.fetched = nil
+		tracker.HandleFills(types.UserFillsData{Fills: []types.UserFillData{fill(1, "B", "50000", "0.5", "1", "0", "0")}})
+	}
+	drifts, err := tracker.Reconcile(ctx)
+	if err != nil || len(drifts) != 0 {
+		t.Errorf("Expected the racing round skipped, got %+v: %v", drifts, err)
+	}
+	if p, _ := tracker.Position("BTC"); !p.Szi.Equal(dec("1.5")) {
+		t.Errorf("Expected the fill kept, got %s", p.Szi)
+	}
+
+	// The next round sees the fill on the exchange too
+	source.state = userState("0", types.Position{Coin: "BTC", Szi: dec("1.5"), EntryPx: dec("50000")})
+	if drifts, err := tracker.Reconcile(ctx); err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift, got %+v: %v", drifts, err)
+	}
+}
+
+func TestTrackerMatchesClearinghouse(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+
+	key := "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	address, _ := utils.GetAddressFromPrivateKey(key)
+	c := client.NewClient(srv.URL, srv.WSURL, key)
+	c.SetAddress(address)
+	srv.SetBalance(address, dec("10000"))
+
+	ws := websocket.NewManager(srv.WSURL)
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Disconnect()
+
+	tracker := NewPositionTracker(c.Info(), address, Config{})
+	updates := make(chan Position, 16)
+	tracker.OnUpdate(func(p Position) { updates <- p })
+	ctx := context.Background()
+	if err := tracker.Seed(ctx); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+	if err := tracker.Subscribe(ws); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer tracker.Unsubscribe()
+
+	// Subscriptions are handled in order, so this snapshot arriving means the
+	// tracker's subscriptions are live
+	ready := make(chan struct{}, 1)
+	ws.SubscribeToL2Book("BTC", func(types.L2BookData) error {
+		select {
+		case ready <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	select {
+	case <-ready:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for subscriptions")
+	}
+
+	limit := func(isBuy bool, px, sz string) types.OrderRequest {
+		return types.OrderRequest{Asset: "BTC", IsBuy: isBuy, LimitPx: dec(px), Sz: dec(sz),
+			OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: types.TifGtc}}}
+	}
+	engine := srv.Engine()
+	engine.Place("0xmaker", limit(false, "50000", "2"))
+	engine.Place(address, limit(true, "50000", "1"))
+	engine.Place("0xmaker", limit(true, "49000", "1"))
+
+	select {
+	case <-updates:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for position update")
+	}
+	tracker.HandleMids(engine.Mids())
+
+	state, err := c.Info().GetUserState(ctx, address)
+	if err != nil {
+		t.Fatalf("Failed to get user state: %v", err)
+	}
+	if s := tracker.Summary(); !s.AccountValue.Equal(state.MarginSummary.AccountValue) {
+		t.Errorf("Expected account value %s, got %s", state.MarginSummary.AccountValue, s.AccountValue)
+	}
+	if drifts, err := tracker.Reconcile(ctx); err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift, got %+v: %v", drifts, err)
+	}
+}
@@ -0,0 +1,205 @@
+package recording
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+)
+
+// Frame is one recorded WebSocket text frame
+type Frame struct {
+	Time time.Time       `json:"time"`
+	Dir  string          `json:"dir"`            // "in" or "out"
+	Data json.RawMessage `json:"data,omitempty"` // The frame, when it is valid JSON
+	Text string          `json:"text,omitempty"` // The frame otherwise
+}
+
+// Inbound reports whether the frame was received from the server
+func (f Frame) Inbound() bool {
+	return f.Dir == websocket.Inbound.String()
+}
+
+// Payload returns the frame as it appeared on the wire
+func (f Frame) Payload() []byte {
+	if f.Data != nil {
+		return f.Data
+	}
+	return []byte(f.Text)
+}
+
+// Recorder writes frames as gzip-compressed JSON lines. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	gz     *gzip.Writer
+	enc    *json.Encoder
+	file   io.Closer
+	now    func() time.Time
+	frames int
+	err    error
+}
+
+// NewRecorder creates a recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	gz := gzip.NewWriter(w)
+	return &Recorder{gz: gz, enc: json.NewEncoder(gz), now: time.Now}
+}
+
+// Create creates a recorder writing to a new file at path
+func Create(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	r := NewRecorder(file)
+	r.file = file
+	return r, nil
+}
+
+// Attach records every frame m sends or receives from now on
+func (r *Recorder) Attach(m *websocket.Manager) {
+	m.Tap(func(dir websocket.Direction, data []byte) {
+		r.Record(dir, data)
+	})
+}
+
+// Record writes one frame stamped with the current time. After the first
+// write error further frames are dropped and Close reports the error.
+func (r *Recorder) Record(dir websocket.Direction, data []byte) error {
+	frame := Frame{Dir: dir.String()}
+	if json.Valid(data) {
+		frame.Data = append(json.RawMessage(nil), data...)
+	} else {
+		frame.Text = string(data)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	frame.Time = r.now()
+	if err := r.enc.Encode(frame); err != nil {
+		r.err = fmt.Errorf("failed to write frame: %w", err)
+		return r.err
+	}
+	r.frames++
+	return nil
+}
+
+// Frames returns the number of frames written
+func (r *Recorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames
+}
+
+// Flush pushes buffered frames to the underlying writer. The compressed
+// stream stays open, so a crash after Flush loses only later frames.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	return r.gz.Flush()
+}
+
+// Close finishes the compressed stream and closes the file opened by Create
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := []error{r.err, r.gz.Close()}
+	if r.file != nil {
+		errs = append(errs, r.file.Close())
+	}
+	if r.err == nil {
+		r.err = errors.New("recorder closed")
+	}
+	return errors.Join(errs...)
+}
+
+// Reader reads frames written by a Recorder
+type Reader struct {
+	gz   *gzip.Reader
+	dec  *json.Decoder
+	file io.Closer
+}
+
+// NewReader creates a reader over a recording
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	return &Reader{gz: gz, dec: json.NewDecoder(gz)}, nil
+}
+
+// Open opens the recording at path
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	r, err := NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.file = file
+	return r, nil
+}
+
+// Next returns the next frame, or io.EOF at the end of the recording. A
+// recording cut off mid-write ends with io.ErrUnexpectedEOF.
+func (r *Reader) Next() (Frame, error) {
+	var frame Frame
+	if err := r.dec.Decode(&frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Frame{}, io.EOF
+		}
+		return Frame{}, fmt.Errorf("failed to read frame: %w", err)
+	}
+	return frame, nil
+}
+
+// Close closes the reader and the file opened by Open
+func (r *Reader) Close() error {
+	err := r.gz.Close()
+	if r.file != nil {
+		err = errors.Join(err, r.file.Close())
+	}
+	return err
+}
+
+// Load reads every frame of the recording at path. Frames read before an
+// error are returned with it.
+func Load(path string) ([]Frame, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var frames []Frame
+	for {
+		frame, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return frames, nil
+		}
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
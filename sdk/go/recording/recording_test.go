@@ -0,0 +1,185 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const testUser = "0x1111111111111111111111111111111111111111"
+
+func ask(px string) types.OrderRequest {
+	return types.OrderRequest{
+		Asset:     "BTC",
+		LimitPx:   decimal.RequireFromString(px),
+		Sz:        decimal.RequireFromString("1"),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: types.TifGtc}},
+	}
+}
+
+// subscribeBook connects a Manager to url and forwards raw l2Book payloads,
+// recording the session when recorder is set
+func subscribeBook(t *testing.T, url string, recorder *Recorder) (*websocket.Manager, <-chan string) {
+	t.Helper()
+
+	ws := websocket.NewManager(url)
+	if recorder != nil {
+		recorder.Attach(ws)
+	}
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	books := make(chan string, 16)
+	if _, err := ws.Subscribe(types.WSSubscription{Type: "l2Book", Coin: "BTC"}, func(data json.RawMessage) error {
+		books <- string(data)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	return ws, books
+}
+
+func receive(t *testing.T, ch <-chan string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for message")
+	}
+	return ""
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+	recorder, err := Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create recording: %v", err)
+	}
+
+	ws, books := subscribeBook(t, srv.WSURL, recorder)
+	var live []string
+	live = append(live, receive(t, books))
+	for _, px := range []string{"51000", "51500"} {
+		srv.Engine().Place(testUser, ask(px))
+		live = append(live, receive(t, books))
+	}
+	ws.Disconnect()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Failed to close recording: %v", err)
+	}
+
+	frames, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load recording: %v", err)
+	}
+	if len(frames) != recorder.Frames() || frames[0].Inbound() {
+		t.Fatalf("Expected %d frames starting with the subscribe, got %+v", recorder.Frames(), frames)
+	}
+
+	replayer := NewReplayer(frames, ReplayConfig{})
+	defer replayer.Close()
+
+	replay, books := subscribeBook(t, replayer.WSURL, nil)
+	defer replay.Disconnect()
+	for i, want := range live {
+		if got := receive(t, books); got != want {
+			t.Errorf("Book %d: expected %s, got %s", i, want, got)
+		}
+	}
+	select {
+	case <-replayer.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected Done after the last frame")
+	}
+}
+
+func TestReplayPacing(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	frames := []Frame{
+		{Time: start, Dir: "out", Data: json.RawMessage(`{"method":"subscribe","subscription":{"type":"trades","coin":"BTC"}}`)},
+		{Time: start.Add(10 * time.Millisecond), Dir: "in", Data: json.RawMessage(`{"channel":"subscriptionResponse","data":{}}`)},
+		{Time: start.Add(20 * time.Millisecond), Dir: "out", Data: json.RawMessage(`{"method":"ping"}`)},
+		{Time: start.Add(30 * time.Millisecond), Dir: "in", Data: json.RawMessage(`{"channel":"pong"}`)},
+		{Time: start.Add(210 * time.Millisecond), Dir: "in", Data: json.RawMessage(`{"channel":"trades","data":[]}`)},
+	}
+	replayer := NewReplayer(frames, ReplayConfig{Speed: 2})
+	defer replayer.Close()
+
+	conn, _, err := gorilla.DefaultDialer.Dial(replayer.WSURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	type arrival struct {
+		at   time.Time
+		data string
+	}
+	arrivals := make(chan arrival, 8)
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			arrivals <- arrival{time.Now(), string(data)}
+		}
+	}()
+
+	// Nothing is sent until the client asks for it
+	time.Sleep(50 * time.Millisecond)
+	subscribed := time.Now()
+	if err := conn.WriteMessage(gorilla.TextMessage, frames[0].Payload()); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	wait := func() arrival {
+		t.Helper()
+		select {
+		case a := <-arrivals:
+			return a
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for frame")
+		}
+		return arrival{}
+	}
+
+	response := wait()
+	if response.data != string(frames[1].Data) || response.at.Before(subscribed) {
+		t.Errorf("Expected the subscription response after subscribing, got %q", response.data)
+	}
+	// The recorded pong is skipped and the 200ms gap halved
+	trades := wait()
+	if trades.data != string(frames[4].Data) {
+		t.Errorf("Expected trades, got %q", trades.data)
+	}
+	if gap := trades.at.Sub(response.at); gap < 90*time.Millisecond || gap > time.Second {
+		t.Errorf("Expected a gap of about 100ms, got %v", gap)
+	}
+
+	select {
+	case <-replayer.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Done after the last frame")
+	}
+
+	// Pings are still answered live
+	if err := conn.WriteMessage(gorilla.TextMessage, []byte(`{"method":"ping"}`)); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+	if pong := wait(); pong.data != `{"channel":"pong"}` {
+		t.Errorf("Expected pong, got %q", pong.data)
+	}
+}
@@ -0,0 +1,180 @@
+package recording
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ReplayConfig configures a Replayer
+type ReplayConfig struct {
+	// Speed scales the recorded gaps between inbound frames: 1 replays at
+	// the original pace, 10 ten times faster. Zero sends frames back to back.
+	Speed float64
+}
+
+// Replayer serves a recording over a local WebSocket server. Each
+// connection is sent the recorded inbound frames in order. Before sending a
+// frame, it waits until the client has sent as many requests as had been
+// sent when the frame was recorded, so data never arrives ahead of the
+// subscription it answers. Pings are answered live and recorded pings and
+// pongs are skipped, since their timing is not reproducible.
+type Replayer struct {
+	*httptest.Server
+	WSURL string
+
+	frames   []Frame
+	config   ReplayConfig
+	upgrader websocket.Upgrader
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// NewReplayer starts serving frames
+func NewReplayer(frames []Frame, config ReplayConfig) *Replayer {
+	r := &Replayer{
+		frames:   frames,
+		config:   config,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		done:     make(chan struct{}),
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	r.WSURL = "ws" + strings.TrimPrefix(r.URL, "http")
+	return r
+}
+
+// Done is closed once a connection has been sent the whole recording
+func (r *Replayer) Done() <-chan struct{} {
+	return r.done
+}
+
+// replayConn is the state of one client connection
+type replayConn struct {
+	conn     *websocket.Conn
+	writeMu  sync.Mutex
+	mu       sync.Mutex
+	requests int
+	notify   chan struct{}
+	closed   chan struct{}
+}
+
+func (r *Replayer) serve(w http.ResponseWriter, req *http.Request) {
+	conn, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	c := &replayConn{conn: conn, notify: make(chan struct{}, 1), closed: make(chan struct{})}
+	go c.readLoop()
+
+	if r.replay(c) {
+		r.doneOnce.Do(func() { close(r.done) })
+	}
+	<-c.closed
+}
+
+// replay sends the recording, reporting whether it got to the end
+func (r *Replayer) replay(c *replayConn) bool {
+	requests := 0
+	var last time.Time
+	for _, frame := range r.frames {
+		payload := frame.Payload()
+		if !frame.Inbound() {
+			if !isPing(payload) {
+				requests++
+			}
+			continue
+		}
+		if isPong(payload) {
+			continue
+		}
+
+		if !c.waitFor(requests) {
+			return false
+		}
+		if r.config.Speed > 0 && !last.IsZero() {
+			if gap := time.Duration(float64(frame.Time.Sub(last)) / r.config.Speed); gap > 0 {
+				select {
+				case <-time.After(gap):
+				case <-c.closed:
+					return false
+				}
+			}
+		}
+		last = frame.Time
+
+		if err := c.write(payload); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// readLoop counts client requests and answers pings
+func (c *replayConn) readLoop() {
+	defer close(c.closed)
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if isPing(data) {
+			c.write([]byte(`{"channel":"pong"}`))
+			continue
+		}
+
+		c.mu.Lock()
+		c.requests++
+		c.mu.Unlock()
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// waitFor blocks until the client has sent n requests or disconnected
+func (c *replayConn) waitFor(n int) bool {
+	for {
+		c.mu.Lock()
+		sent := c.requests
+		c.mu.Unlock()
+		if sent >= n {
+			return true
+		}
+
+		select {
+		case <-c.notify:
+		case <-c.closed:
+			return false
+		}
+	}
+}
+
+func (c *replayConn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func isPing(data []byte) bool {
+	var msg struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(data, &msg) == nil && msg.Method == "ping"
+}
+
+func isPong(data []byte) bool {
+	var msg struct {
+		Channel string `json:"channel"`
+	}
+	return json.Unmarshal(data, &msg) == nil && msg.Channel == "pong"
+}
@@ -0,0 +1,544 @@
+// Package risk puts pre-trade checks and a kill switch between strategies
+// and the exchange.
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/positions"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+	"golang.org/x/time/rate"
+)
+
+// Names of the checks reported in a Violation
+const (
+	CheckOrderNotional = "order_notional"
+	CheckPosition      = "position"
+	CheckLeverage      = "leverage"
+	CheckPriceBand     = "price_band"
+	CheckOrderRate     = "order_rate"
+	CheckDailyLoss     = "daily_loss"
+	CheckAsset         = "asset"
+)
+
+// killTimeout bounds the cancel-all sent when the daily loss limit is
+// breached between orders
+const killTimeout = 10 * time.Second
+
+// ErrKilled is returned for every order while the kill switch is engaged
+var ErrKilled = errors.New("kill switch engaged")
+
+// Violation is a rejected order
+type Violation struct {
+	Check  string // One of the Check constants
+	Coin   string
+	Index  int // Position of the order in its batch, or -1 for account-wide checks
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	if v.Index < 0 {
+		return fmt.Sprintf("risk check %s failed: %s", v.Check, v.Reason)
+	}
+	return fmt.Sprintf("risk check %s failed for order %d (%s): %s", v.Check, v.Index, v.Coin, v.Reason)
+}
+
+// Limits configures the checks. Zero values disable a check.
+type Limits struct {
+	MaxOrderNotional    decimal.Decimal            // Per-order notional at the limit price
+	MaxPositionNotional decimal.Decimal            // Per-coin position notional after the order and same-side open orders fill
+	MaxPositionSize     map[string]decimal.Decimal // Per-coin absolute position size after the order and same-side open orders fill
+	MaxLeverage         decimal.Decimal            // Gross notional over account value after the order
+	PriceBand           float64                    // Maximum fractional distance of the limit price from the mid
+	MaxOrdersPerSecond  float64                    // Sustained order rate; batches count each order
+	OrderBurst          int                        // Orders allowed at once above the rate (default 1 second's worth)
+	MaxDailyLoss        decimal.Decimal            // Drop in account value since the first check of the UTC day that engages the kill switch
+}
+
+// AssetSource resolves asset metadata. *client.InfoClient satisfies it.
+type AssetSource interface {
+	GetAssetInfo(ctx context.Context, coin string) (*types.AssetInfo, error)
+}
+
+// OrderSource lists orders that may still fill. *orders.OrderManager
+// satisfies it.
+type OrderSource interface {
+	Open() []orders.Order
+}
+
+// Guard wraps a Trader and checks every order against Limits before it is
+// sent. Cancels always pass through. Guard is itself a client.Trader.
+type Guard struct {
+	trader  client.Trader
+	assets  AssetSource
+	tracker *positions.PositionTracker
+	limits  Limits
+	limiter *rate.Limiter
+	open    OrderSource
+
+	mu         sync.Mutex
+	mids       map[string]decimal.Decimal
+	killed     bool
+	killReason string
+	day        time.Time
+	dayStart   decimal.Decimal
+	onKill     []func(reason string)
+	onError    []func(error)
+	ws         websocket.Subscriber
+	subID      string
+	now        func() time.Time
+}
+
+// NewGuard wraps trader. tracker supplies positions and account value and
+// should be seeded and subscribed by the caller.
+func NewGuard(trader client.Trader, assets AssetSource, tracker *positions.PositionTracker, limits Limits) *Guard {
+	g := &Guard{
+		trader:  trader,
+		assets:  assets,
+		tracker: tracker,
+		limits:  limits,
+		mids:    make(map[string]decimal.Decimal),
+		now:     time.Now,
+	}
+
+	if limits.MaxOrdersPerSecond > 0 {
+		burst := limits.OrderBurst
+		if burst <= 0 {
+			burst = int(limits.MaxOrdersPerSecond)
+			if burst < 1 {
+				burst = 1
+			}
+		}
+		g.limiter = rate.NewLimiter(rate.Limit(limits.MaxOrdersPerSecond), burst)
+	}
+
+	// Losses can mount without any new orders, so watch every update
+	if limits.MaxDailyLoss.IsPositive() {
+		tracker.OnUpdate(func(positions.Position) {
+			if reason, breached := g.dailyLoss(); breached {
+				go func() {
+					ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+					defer cancel()
+					g.kill(ctx, reason)
+				}()
+			}
+		})
+	}
+
+	return g
+}
+
+var _ client.Trader = (*Guard)(nil)
+
+// SetOpenOrders makes the position limits count open orders from source.
+// Without it only filled exposure is limited, so many resting orders that
+// each fit can together exceed MaxPositionSize or MaxPositionNotional.
+// Call it before placing orders.
+func (g *Guard) SetOpenOrders(source OrderSource) {
+	g.open = source
+}
+
+// OnKill registers a handler called when the kill switch engages
+func (g *Guard) OnKill(handler func(reason string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onKill = append(g.onKill, handler)
+}
+
+// OnError registers a handler for failures the guard hits on its own, such
+// as a kill switch that could not cancel orders
+func (g *Guard) OnError(handler func(error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onError = append(g.onError, handler)
+}
+
+// Subscribe keeps reference mids current from allMids on ws
+func (g *Guard) Subscribe(ws websocket.Subscriber) error {
+	subID, err := ws.Subscribe(types.WSSubscription{Type: "allMids"}, func(raw json.RawMessage) error {
+		var data types.AllMidsData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		g.HandleMids(data.Mids)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to mids: %w", err)
+	}
+
+	g.mu.Lock()
+	g.ws, g.subID = ws, subID
+	g.mu.Unlock()
+
+	return nil
+}
+
+// Unsubscribe stops consuming mids
+func (g *Guard) Unsubscribe() error {
+	g.mu.Lock()
+	ws, subID := g.ws, g.subID
+	g.ws, g.subID = nil, ""
+	g.mu.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+	return ws.Unsubscribe(subID)
+}
+
+// HandleMids updates the reference prices used for price bands
+func (g *Guard) HandleMids(mids map[string]string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for coin, mid := range mids {
+		if px, err := decimal.NewFromString(mid); err == nil {
+			g.mids[coin] = px
+		}
+	}
+}
+
+// Kill cancels every open order and blocks further orders until Reset. It
+// is safe to call repeatedly; only the first call cancels.
+func (g *Guard) Kill(ctx context.Context, reason string) error {
+	g.mu.Lock()
+	if g.killed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.killed = true
+	g.killReason = reason
+	handlers := append([]func(string){}, g.onKill...)
+	g.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(reason)
+	}
+
+	if _, err := g.trader.CancelAll(ctx, types.CancelFilter{}); err != nil {
+		return fmt.Errorf("failed to cancel orders: %w", err)
+	}
+	return nil
+}
+
+// kill engages the kill switch for a breach found outside a caller's
+// request, reporting a failed cancel to the OnError handlers
+func (g *Guard) kill(ctx context.Context, reason string) {
+	err := g.Kill(ctx, reason)
+	if err == nil {
+		return
+	}
+
+	g.mu.Lock()
+	handlers := append([]func(error){}, g.onError...)
+	g.mu.Unlock()
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
+
+// Reset disengages the kill switch. The day's starting account value is
+// kept, so a daily loss still over the limit engages it again at the next
+// check; use ResetDailyLoss to accept the loss so far.
+func (g *Guard) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.killed = false
+	g.killReason = ""
+}
+
+// ResetDailyLoss restarts the daily loss baseline from the current account
+// value
+func (g *Guard) ResetDailyLoss() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.day = time.Time{}
+}
+
+// Killed reports whether the kill switch is engaged, and why
+func (g *Guard) Killed() (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.killed, g.killReason
+}
+
+// Check runs every check against orders without sending them
+func (g *Guard) Check(ctx context.Context, orders []types.OrderRequest) error {
+	if killed, reason := g.Killed(); killed {
+		return fmt.Errorf("%w: %s", ErrKilled, reason)
+	}
+
+	if reason, breached := g.dailyLoss(); breached {
+		g.kill(ctx, reason)
+		return &Violation{Check: CheckDailyLoss, Index: -1, Reason: reason}
+	}
+
+	summary := g.tracker.Summary()
+	gross := summary.Notional
+	projected := make(map[string]decimal.Decimal)
+
+	// An order manager tracks its orders before sending them, so the batch
+	// itself is not counted as open
+	batch := make(map[string]bool)
+	for _, order := range orders {
+		if order.Cloid != nil {
+			batch[*order.Cloid] = true
+		}
+	}
+
+	for i, order := range orders {
+		asset, err := g.assets.GetAssetInfo(ctx, order.Asset)
+		if err != nil {
+			return &Violation{Check: CheckAsset, Coin: order.Asset, Index: i, Reason: err.Error()}
+		}
+
+		notional := order.Sz.Mul(order.LimitPx)
+		if g.limits.MaxOrderNotional.IsPositive() && notional.GreaterThan(g.limits.MaxOrderNotional) {
+			return &Violation{Check: CheckOrderNotional, Coin: order.Asset, Index: i,
+				Reason: fmt.Sprintf("notional %s exceeds %s", notional.StringFixed(2), g.limits.MaxOrderNotional)}
+		}
+
+		if err := g.checkPriceBand(order, i); err != nil {
+			return err
+		}
+
+		if asset.IsSpot {
+			continue
+		}
+
+		current, ok := projected[order.Asset]
+		if !ok {
+			p, _ := g.tracker.Position(order.Asset)
+			current = p.Szi
+		}
+		signed := order.Sz
+		if !order.IsBuy {
+			signed = signed.Neg()
+		}
+		next := current.Add(signed)
+		projected[order.Asset] = next
+		px := g.referencePrice(order)
+
+		// Open orders on the same side may fill too, so the position limits
+		// hold for the position once all of them have
+		worst := next.Add(g.resting(order.Asset, order.IsBuy, batch))
+		if !reduces(current, worst) {
+			if max, ok := g.limits.MaxPositionSize[order.Asset]; ok && worst.Abs().GreaterThan(max) {
+				return &Violation{Check: CheckPosition, Coin: order.Asset, Index: i,
+					Reason: fmt.Sprintf("position %s would exceed %s", worst, max)}
+			}
+			if g.limits.MaxPositionNotional.IsPositive() && worst.Abs().Mul(px).GreaterThan(g.limits.MaxPositionNotional) {
+				return &Violation{Check: CheckPosition, Coin: order.Asset, Index: i,
+					Reason: fmt.Sprintf("position notional %s would exceed %s", worst.Abs().Mul(px).StringFixed(2), g.limits.MaxPositionNotional)}
+			}
+		}
+
+		// Orders that only shrink the position never add leverage
+		if reduces(current, next) {
+			gross = gross.Sub(current.Abs().Sub(next.Abs()).Mul(px))
+			continue
+		}
+
+		gross = gross.Add(next.Abs().Sub(current.Abs()).Mul(px))
+		if err := g.checkLeverage(asset, next.Abs().Mul(px), gross, summary.AccountValue, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resting returns the signed unfilled size of open orders on one side of
+// coin, other than those in batch
+func (g *Guard) resting(coin string, isBuy bool, batch map[string]bool) decimal.Decimal {
+	total := decimal.Zero
+	if g.open == nil {
+		return total
+	}
+	for _, o := range g.open.Open() {
+		if o.Coin != coin || o.IsBuy != isBuy || batch[o.Cloid] {
+			continue
+		}
+		if isBuy {
+			total = total.Add(o.Remaining())
+		} else {
+			total = total.Sub(o.Remaining())
+		}
+	}
+	return total
+}
+
+// reduces reports whether moving a position from current to next only
+// shrinks it, without flipping sides
+func reduces(current, next decimal.Decimal) bool {
+	return next.Abs().LessThanOrEqual(current.Abs()) && (next.IsZero() || next.IsPositive() == current.IsPositive())
+}
+
+// PlaceOrder checks and places a single order
+func (g *Guard) PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error) {
+	return g.PlaceOrders(ctx, []types.OrderRequest{order}, types.GroupingNA)
+}
+
+// PlaceOrders checks every order and places the batch only if all pass
+func (g *Guard) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error) {
+	if err := g.admit(ctx, orders); err != nil {
+		return nil, err
+	}
+	return g.trader.PlaceOrders(ctx, orders, grouping)
+}
+
+// BatchModify checks each replacement order as if it were new
+func (g *Guard) BatchModify(ctx context.Context, modifies []types.ModifyRequest) (*types.OrderResponse, error) {
+	orders := make([]types.OrderRequest, len(modifies))
+	for i, modify := range modifies {
+		orders[i] = modify.Order
+	}
+	if err := g.admit(ctx, orders); err != nil {
+		return nil, err
+	}
+	return g.trader.BatchModify(ctx, modifies)
+}
+
+// CancelByCloid passes through unchecked
+func (g *Guard) CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error) {
+	return g.trader.CancelByCloid(ctx, coin, cloids...)
+}
+
+// CancelAll passes through unchecked
+func (g *Guard) CancelAll(ctx context.Context, filter types.CancelFilter) ([]types.CancelResult, error) {
+	return g.trader.CancelAll(ctx, filter)
+}
+
+// admit runs the checks and then takes the orders from the rate budget
+func (g *Guard) admit(ctx context.Context, orders []types.OrderRequest) error {
+	if err := g.Check(ctx, orders); err != nil {
+		return err
+	}
+
+	if g.limiter != nil && !g.limiter.AllowN(g.now(), len(orders)) {
+		coin := ""
+		if len(orders) > 0 {
+			coin = orders[0].Asset
+		}
+		return &Violation{Check: CheckOrderRate, Coin: coin, Index: 0,
+			Reason: fmt.Sprintf("more than %g orders per second", g.limits.MaxOrdersPerSecond)}
+	}
+
+	return nil
+}
+
+func (g *Guard) checkPriceBand(order types.OrderRequest, index int) error {
+	if g.limits.PriceBand <= 0 {
+		return nil
+	}
+
+	// Market-style triggers carry a deliberately loose limit price
+	px := order.LimitPx
+	if trigger := order.OrderType.Trigger; trigger != nil {
+		if trigger.IsMarket {
+			return nil
+		}
+		px = trigger.TriggerPx
+	}
+
+	mid, ok := g.mid(order.Asset)
+	if !ok {
+		return &Violation{Check: CheckPriceBand, Coin: order.Asset, Index: index, Reason: "no reference mid price"}
+	}
+
+	distance := px.Sub(mid).Abs().Div(mid)
+	if distance.GreaterThan(decimal.NewFromFloat(g.limits.PriceBand)) {
+		return &Violation{Check: CheckPriceBand, Coin: order.Asset, Index: index,
+			Reason: fmt.Sprintf("price %s is %s%% from mid %s", px, distance.Mul(decimal.NewFromInt(100)).StringFixed(2), mid)}
+	}
+
+	return nil
+}
+
+func (g *Guard) checkLeverage(asset *types.AssetInfo, positionNotional, gross, accountValue decimal.Decimal, index int) error {
+	if !accountValue.IsPositive() {
+		if g.limits.MaxLeverage.IsPositive() || asset.MaxLeverage > 0 {
+			return &Violation{Check: CheckLeverage, Coin: asset.Coin, Index: index, Reason: "account value is not positive"}
+		}
+		return nil
+	}
+
+	if asset.MaxLeverage > 0 {
+		max := decimal.NewFromInt(int64(asset.MaxLeverage))
+		if leverage := positionNotional.Div(accountValue); leverage.GreaterThan(max) {
+			return &Violation{Check: CheckLeverage, Coin: asset.Coin, Index: index,
+				Reason: fmt.Sprintf("position leverage %sx exceeds the asset maximum %sx", leverage.StringFixed(2), max)}
+		}
+	}
+
+	if g.limits.MaxLeverage.IsPositive() {
+		if leverage := gross.Div(accountValue); leverage.GreaterThan(g.limits.MaxLeverage) {
+			return &Violation{Check: CheckLeverage, Coin: asset.Coin, Index: index,
+				Reason: fmt.Sprintf("account leverage %sx would exceed %sx", leverage.StringFixed(2), g.limits.MaxLeverage)}
+		}
+	}
+
+	return nil
+}
+
+// dailyLoss reports whether the account has lost more than MaxDailyLoss
+// since the first check of the UTC day. That check records the baseline and
+// always passes; losses taken earlier in the day are not counted.
+func (g *Guard) dailyLoss() (string, bool) {
+	if !g.limits.MaxDailyLoss.IsPositive() {
+		return "", false
+	}
+
+	value := g.tracker.Summary().AccountValue
+	day := g.now().UTC().Truncate(24 * time.Hour)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.day.Equal(day) {
+		g.day = day
+		g.dayStart = value
+		return "", false
+	}
+
+	loss := g.dayStart.Sub(value)
+	if loss.GreaterThanOrEqual(g.limits.MaxDailyLoss) {
+		return fmt.Sprintf("daily loss %s reached limit %s", loss.StringFixed(2), g.limits.MaxDailyLoss), true
+	}
+	return "", false
+}
+
+// mid returns the reference price for coin, falling back to the tracked
+// mark price
+func (g *Guard) mid(coin string) (decimal.Decimal, bool) {
+	g.mu.Lock()
+	mid, ok := g.mids[coin]
+	g.mu.Unlock()
+	if ok && mid.IsPositive() {
+		return mid, true
+	}
+
+	if p, ok := g.tracker.Position(coin); ok && p.MarkPx.IsPositive() {
+		return p.MarkPx, true
+	}
+	return decimal.Zero, false
+}
+
+// referencePrice values an order at the mid when known, else its limit
+func (g *Guard) referencePrice(order types.OrderRequest) decimal.Decimal {
+	if mid, ok := g.mid(order.Asset); ok {
+		return mid
+	}
+	return order.LimitPx
+}
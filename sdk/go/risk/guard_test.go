@@ -0,0 +1,279 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/positions"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+type fakeTrader struct {
+	placed    int
+	canceled  chan struct{}
+	cancelErr error
+	deadline  bool // Last cancel-all carried a deadline
+}
+
+func (f *fakeTrader) PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error) {
+	return f.PlaceOrders(ctx, []types.OrderRequest{order}, types.GroupingNA)
+}
+
+func (f *fakeTrader) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error) {
+	f.placed += len(orders)
+	return &types.OrderResponse{Status: "ok"}, nil
+}
+
+func (f *fakeTrader) BatchModify(ctx context.Context, modifies []types.ModifyRequest) (*types.OrderResponse, error) {
+	return &types.OrderResponse{Status: "ok"}, nil
+}
+
+func (f *fakeTrader) CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error) {
+	return nil, nil
+}
+
+func (f *fakeTrader) CancelAll(ctx context.Context, filter types.CancelFilter) ([]types.CancelResult, error) {
+	_, f.deadline = ctx.Deadline()
+	if f.canceled != nil {
+		f.canceled <- struct{}{}
+	}
+	return nil, f.cancelErr
+}
+
+type fakeAssets struct{}
+
+func (fakeAssets) GetAssetInfo(ctx context.Context, coin string) (*types.AssetInfo, error) {
+	switch coin {
+	case "BTC":
+		return &types.AssetInfo{ID: 0, Coin: "BTC", SzDecimals: 5, MaxLeverage: 50}, nil
+	case "PURR/USDC":
+		return &types.AssetInfo{ID: 10000, Coin: "PURR/USDC", IsSpot: true}, nil
+	}
+	return nil, fmt.Errorf("unknown asset %s", coin)
+}
+
+type fakeState struct{}
+
+// GetUserState reports 10000 account value holding 0.4 BTC entered at 50000
+func (fakeState) GetUserState(ctx context.Context, user string) (*types.UserState, error) {
+	return &types.UserState{
+		MarginSummary: types.MarginSummary{TotalRawUsd: dec("-10000")},
+		AssetPositions: []types.AssetPosition{{Position: types.Position{
+			Coin: "BTC", Szi: dec("0.4"), EntryPx: dec("50000"), PositionValue: dec("20000"),
+		}}},
+	}, nil
+}
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func newGuard(t *testing.T, trader *fakeTrader, limits Limits) (*Guard, *positions.PositionTracker) {
+	tracker := positions.NewPositionTracker(fakeState{}, "0x1234567890123456789012345678901234567890", positions.Config{})
+	if err := tracker.Seed(context.Background()); err != nil {
+		t.Fatalf("Failed to seed tracker: %v", err)
+	}
+	guard := NewGuard(trader, fakeAssets{}, tracker, limits)
+	guard.HandleMids(map[string]string{"BTC": "50000"})
+	return guard, tracker
+}
+
+func limit(coin string, isBuy bool, px, sz string) types.OrderRequest {
+	return types.OrderRequest{
+		Asset:     coin,
+		IsBuy:     isBuy,
+		LimitPx:   dec(px),
+		Sz:        dec(sz),
+		OrderType: types.OrderType{Limit: &types.LimitOrderType{Tif: types.TifGtc}},
+	}
+}
+
+func TestGuardChecks(t *testing.T) {
+	trader := &fakeTrader{}
+	guard, _ := newGuard(t, trader, Limits{
+		MaxOrderNotional: dec("30000"),
+		MaxPositionSize:  map[string]decimal.Decimal{"BTC": dec("0.5")},
+		MaxLeverage:      dec("2.5"),
+		PriceBand:        0.05,
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		orders []types.OrderRequest
+		check  string
+		index  int
+	}{
+		{"order notional", []types.OrderRequest{limit("BTC", true, "50000", "0.7")}, CheckOrderNotional, 0},
+		{"fat finger", []types.OrderRequest{limit("BTC", true, "56000", "0.01")}, CheckPriceBand, 0},
+		{"position across batch", []types.OrderRequest{limit("BTC", true, "50000", "0.05"), limit("BTC", true, "50000", "0.1")}, CheckPosition, 1},
+		{"unknown asset", []types.OrderRequest{limit("DOGE", true, "1", "1")}, CheckAsset, 0},
+		{"reduce", []types.OrderRequest{limit("BTC", false, "50000", "0.4")}, "", 0},
+		{"within limits", []types.OrderRequest{limit("BTC", true, "50000", "0.05")}, "", 0},
+		{"spot skips position checks", []types.OrderRequest{limit("PURR/USDC", true, "0.2", "100")}, CheckPriceBand, 0},
+	}
+
+	for _, tt := range tests {
+		_, err := guard.PlaceOrders(ctx, tt.orders, types.GroupingNA)
+		if tt.check == "" {
+			if err != nil {
+				t.Errorf("%s: expected order to pass, got %v", tt.name, err)
+			}
+			continue
+		}
+
+		var violation *Violation
+		if !errors.As(err, &violation) {
+			t.Errorf("%s: expected a violation, got %v", tt.name, err)
+			continue
+		}
+		if violation.Check != tt.check || violation.Index != tt.index {
+			t.Errorf("%s: expected %s on order %d, got %s on order %d", tt.name, tt.check, tt.index, violation.Check, violation.Index)
+		}
+	}
+
+	if trader.placed != 2 {
+		t.Errorf("Expected 2 orders to reach the exchange, got %d", trader.placed)
+	}
+
+	// 0.4 held plus 0.1 is 25000 notional on 10000 equity: exactly 2.5x
+	tight, _ := newGuard(t, &fakeTrader{}, Limits{MaxLeverage: dec("2.4")})
+	var violation *Violation
+	if err := tight.Check(ctx, []types.OrderRequest{limit("BTC", true, "50000", "0.1")}); !errors.As(err, &violation) || violation.Check != CheckLeverage {
+		t.Errorf("Expected leverage violation, got %v", err)
+	}
+}
+
+// openOrders is an OrderSource with fixed orders
+type openOrders []orders.Order
+
+func (o openOrders) Open() []orders.Order {
+	return o
+}
+
+func TestPositionLimitCountsOpenOrders(t *testing.T) {
+	guard, _ := newGuard(t, &fakeTrader{}, Limits{MaxPositionSize: map[string]decimal.Decimal{"BTC": dec("0.5")}})
+	ctx := context.Background()
+
+	// 0.4 held plus 0.08 fits on its own
+	buy := limit("BTC", true, "50000", "0.08")
+	if err := guard.Check(ctx, []types.OrderRequest{buy}); err != nil {
+		t.Fatalf("Expected the order to fit without open orders, got %v", err)
+	}
+
+	cloid := "0x00000000000000000000000000000001"
+	guard.SetOpenOrders(openOrders{
+		{Cloid: "0x00000000000000000000000000000002", Coin: "BTC", IsBuy: true, OrigSz: dec("0.08"), FilledSz: dec("0.03")},
+		{Coin: "BTC", IsBuy: false, OrigSz: dec("0.3")},
+		{Cloid: cloid, Coin: "BTC", IsBuy: true, OrigSz: dec("0.08")},
+	})
+
+	// A resting buy with 0.05 left could fill first
+	var violation *Violation
+	if err := guard.Check(ctx, []types.OrderRequest{buy}); !errors.As(err, &violation) || violation.Check != CheckPosition {
+		t.Errorf("Expected a position violation counting the resting buy, got %v", err)
+	}
+
+	// The batch's own order, tracked before it is sent, is not counted twice
+	buy.Sz = dec("0.05")
+	buy.Cloid = &cloid
+	if err := guard.Check(ctx, []types.OrderRequest{buy}); err != nil {
+		t.Errorf("Expected the order not counted against itself, got %v", err)
+	}
+
+	// Sells only reduce the long, even with the resting sell filled
+	if err := guard.Check(ctx, []types.OrderRequest{limit("BTC", false, "50000", "0.1")}); err != nil {
+		t.Errorf("Expected a reducing sell to pass, got %v", err)
+	}
+}
+
+func TestOrderRate(t *testing.T) {
+	guard, _ := newGuard(t, &fakeTrader{}, Limits{MaxOrdersPerSecond: 2})
+	ctx := context.Background()
+
+	order := limit("BTC", true, "50000", "0.01")
+	for i := 0; i < 2; i++ {
+		if _, err := guard.PlaceOrder(ctx, order); err != nil {
+			t.Fatalf("Order %d: expected to pass, got %v", i, err)
+		}
+	}
+
+	var violation *Violation
+	if _, err := guard.PlaceOrder(ctx, order); !errors.As(err, &violation) || violation.Check != CheckOrderRate {
+		t.Errorf("Expected order rate violation, got %v", err)
+	}
+}
+
+func TestKillSwitch(t *testing.T) {
+	trader := &fakeTrader{canceled: make(chan struct{}, 1)}
+	guard, tracker := newGuard(t, trader, Limits{MaxDailyLoss: dec("500")})
+	ctx := context.Background()
+
+	order := limit("BTC", true, "50000", "0.01")
+	if _, err := guard.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to pass, got %v", err)
+	}
+
+	// 0.4 BTC losing 2000 each is 800 down on the day
+	tracker.HandleMids(map[string]string{"BTC": "48000"})
+	select {
+	case <-trader.canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for cancel all")
+	}
+
+	if killed, reason := guard.Killed(); !killed || reason == "" {
+		t.Errorf("Expected kill switch engaged with a reason")
+	}
+	if _, err := guard.PlaceOrder(ctx, order); !errors.Is(err, ErrKilled) {
+		t.Errorf("Expected ErrKilled, got %v", err)
+	}
+	if _, err := guard.CancelAll(ctx, types.CancelFilter{}); err != nil {
+		t.Errorf("Expected cancels to pass while killed, got %v", err)
+	}
+	<-trader.canceled
+
+	// The day's losses outlive Reset until they are accepted
+	guard.Reset()
+	var violation *Violation
+	if _, err := guard.PlaceOrder(ctx, order); !errors.As(err, &violation) || violation.Check != CheckDailyLoss {
+		t.Errorf("Expected daily loss violation after reset, got %v", err)
+	}
+	<-trader.canceled
+
+	guard.ResetDailyLoss()
+	guard.Reset()
+	if _, err := guard.PlaceOrder(ctx, order); err != nil {
+		t.Errorf("Expected order to pass after reset, got %v", err)
+	}
+}
+
+func TestKillSwitchReportsCancelFailure(t *testing.T) {
+	trader := &fakeTrader{canceled: make(chan struct{}, 1), cancelErr: errors.New("exchange down")}
+	guard, tracker := newGuard(t, trader, Limits{MaxDailyLoss: dec("500")})
+	errs := make(chan error, 1)
+	guard.OnError(func(err error) { errs <- err })
+
+	// Take the day's baseline at 50000
+	if err := guard.Check(context.Background(), nil); err != nil {
+		t.Fatalf("Expected the check to pass, got %v", err)
+	}
+	tracker.HandleMids(map[string]string{"BTC": "48000"})
+	select {
+	case err := <-errs:
+		if !errors.Is(err, trader.cancelErr) {
+			t.Errorf("Expected the cancel failure, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for the error")
+	}
+	<-trader.canceled
+	if !trader.deadline {
+		t.Errorf("Expected the cancel-all bounded by a deadline")
+	}
+}
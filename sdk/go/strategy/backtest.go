@@ -0,0 +1,100 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/backtest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// backtester runs a Strategy under backtest.Engine
+type backtester struct {
+	strategy Strategy
+	config   Config
+	rt       *Runtime
+}
+
+// Backtest replays feed through s on engine with the same order manager,
+// position tracker and risk guard as live. Timers fire on the simulated
+// clock as events arrive, and candles reach a CandleHandler. Coins and
+// CandleInterval are ignored; the feed decides what is replayed.
+func Backtest(ctx context.Context, engine *backtest.Engine, feed backtest.Feed, s Strategy, config Config) (*backtest.Report, error) {
+	return engine.Run(ctx, feed, &backtester{strategy: s, config: config})
+}
+
+func (b *backtester) OnStart(ctx context.Context, trader client.Trader) error {
+	backend, ok := trader.(Backend)
+	if !ok {
+		return fmt.Errorf("backtest trader %T does not answer account queries", trader)
+	}
+
+	b.rt = NewRuntime(backend, nil, b.config)
+	b.rt.simulate = true
+	if err := b.rt.begin(b.strategy); err != nil {
+		return err
+	}
+	if err := b.rt.tracker.Seed(ctx); err != nil {
+		return fmt.Errorf("failed to seed positions: %w", err)
+	}
+
+	b.rt.enqueueStart()
+	return b.rt.drain(ctx)
+}
+
+func (b *backtester) OnCandle(ctx context.Context, candle types.CandleData) error {
+	b.advance(candle.T)
+	b.rt.handleMids(map[string]string{candle.Coin: candle.C.String()})
+	b.rt.HandleCandle(candle)
+	return b.rt.drain(ctx)
+}
+
+func (b *backtester) OnBook(ctx context.Context, book types.L2BookData) error {
+	b.advance(book.Time)
+	if bids, asks, err := types.ParseL2Levels(book.Levels); err == nil && len(bids) > 0 && len(asks) > 0 {
+		mid := bids[0].Price.Add(asks[0].Price).Div(decimal.NewFromInt(2))
+		b.rt.handleMids(map[string]string{book.Coin: mid.String()})
+	}
+	b.rt.queueBook(book)
+	return b.rt.drain(ctx)
+}
+
+func (b *backtester) OnTrades(ctx context.Context, trades []types.TradeData) error {
+	last := trades[len(trades)-1]
+	b.advance(last.Time)
+	b.rt.handleMids(map[string]string{last.Coin: last.Px.String()})
+	b.rt.queueTrades(trades)
+	return b.rt.drain(ctx)
+}
+
+func (b *backtester) OnFills(ctx context.Context, fills types.UserFillsData) error {
+	b.rt.HandleFills(fills)
+	return b.rt.drain(ctx)
+}
+
+func (b *backtester) OnOrderUpdates(ctx context.Context, updates []types.OrderUpdate) error {
+	b.rt.orders.HandleOrderUpdates(updates)
+	return b.rt.drain(ctx)
+}
+
+// OnStop stops the strategy and cancels its open orders
+func (b *backtester) OnStop(ctx context.Context) error {
+	if err := b.rt.drain(ctx); err != nil {
+		return err
+	}
+	return b.rt.shutdown()
+}
+
+// advance moves the simulated clock to ms and queues due timers
+func (b *backtester) advance(ms int64) {
+	b.rt.mu.Lock()
+	if at := time.UnixMilli(ms); at.After(b.rt.clock) {
+		b.rt.clock = at
+	}
+	b.rt.mu.Unlock()
+
+	b.rt.fireTimers()
+}
@@ -0,0 +1,635 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/positions"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/risk"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+)
+
+// Config configures a Runtime
+type Config struct {
+	User            string           // Account the strategy trades
+	Coins           []string         // Coins whose books and trades are delivered
+	CandleInterval  string           // Candles delivered to a CandleHandler, e.g. "1m"; none when empty
+	Limits          *risk.Limits     // Pre-trade limits; orders are not checked when nil
+	Assets          risk.AssetSource // Asset metadata for Limits, default the backend's if it has any
+	Orders          orders.Config
+	Positions       positions.Config
+	ShutdownTimeout time.Duration // Bound on OnStop and the final cancel-all (default 10s)
+}
+
+// call is one queued strategy callback
+type call func(ctx context.Context, s Strategy) error
+
+// timer is a repeating strategy timer. A zero next is scheduled from the
+// first time the clock is known.
+type timer struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// Runtime drives one Strategy. It is the only consumer of its WebSocket
+// streams: market data and account events update the order manager,
+// position tracker and risk guard, and are then queued for the strategy,
+// which one goroutine calls in order. On shutdown it calls OnStop and
+// cancels every open order.
+type Runtime struct {
+	backend Backend
+	ws      websocket.Subscriber
+	config  Config
+	trader  client.Trader
+	orders  *orders.OrderManager
+	tracker *positions.PositionTracker
+	guard   *risk.Guard
+
+	mu       sync.Mutex
+	strategy Strategy
+	queue    []call
+	notify   chan struct{}
+	timers   map[string]*timer
+	clock    time.Time // Simulated time, used when simulate is set
+	simulate bool
+	started  bool
+	stopping bool
+	stopped  bool
+	stop     chan struct{}
+	onError  func(error)
+	subIDs   []string
+}
+
+// NewRuntime creates a runtime trading through backend and receiving
+// market data and account events from ws. With a Simulator backend, account
+// events come from the simulator, which is fed the books and trades the
+// runtime receives; create it without a WebSocket of its own.
+func NewRuntime(backend Backend, ws websocket.Subscriber, config Config) *Runtime {
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 10 * time.Second
+	}
+	if config.Assets == nil {
+		config.Assets, _ = backend.(risk.AssetSource)
+	}
+
+	r := &Runtime{
+		backend: backend,
+		ws:      ws,
+		config:  config,
+		trader:  backend,
+		tracker: positions.NewPositionTracker(backend, config.User, config.Positions),
+		notify:  make(chan struct{}, 1),
+		timers:  make(map[string]*timer),
+		stop:    make(chan struct{}),
+	}
+	if config.Limits != nil {
+		r.guard = risk.NewGuard(backend, config.Assets, r.tracker, *config.Limits)
+		r.trader = r.guard
+	}
+	r.orders = orders.NewOrderManager(r.trader, backend, config.User, config.Orders)
+	if r.guard != nil {
+		r.guard.SetOpenOrders(r.orders)
+	}
+
+	r.orders.OnEvent(func(event orders.Event) {
+		r.enqueue(func(ctx context.Context, s Strategy) error {
+			if err := s.OnOrderUpdate(ctx, event); err != nil {
+				return fmt.Errorf("strategy failed on order update: %w", err)
+			}
+			return nil
+		})
+	})
+	r.orders.OnError(r.report)
+	r.tracker.OnError(r.report)
+
+	return r
+}
+
+// User returns the account the strategy trades
+func (r *Runtime) User() string {
+	return r.config.User
+}
+
+// Trader returns the order path, checked against Limits when they are set
+func (r *Runtime) Trader() client.Trader {
+	return r.trader
+}
+
+// Orders returns the order manager. Orders placed through it are tracked
+// from placement; others are adopted from the order stream.
+func (r *Runtime) Orders() *orders.OrderManager {
+	return r.orders
+}
+
+// Positions returns the position tracker
+func (r *Runtime) Positions() *positions.PositionTracker {
+	return r.tracker
+}
+
+// Guard returns the risk guard, or nil without Limits
+func (r *Runtime) Guard() *risk.Guard {
+	return r.guard
+}
+
+// Now returns the current time: the wall clock, or the simulated clock in
+// a backtest
+func (r *Runtime) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.now()
+}
+
+// now returns the current time. Callers hold r.mu.
+func (r *Runtime) now() time.Time {
+	if r.simulate {
+		return r.clock
+	}
+	return time.Now()
+}
+
+// SetTimer calls OnTimer with name every interval until StopTimer,
+// replacing any timer of the same name. Ticks missed while the strategy is
+// busy are dropped, as with time.Ticker.
+func (r *Runtime) SetTimer(name string, interval time.Duration) {
+	r.mu.Lock()
+	t := &timer{interval: interval}
+	if now := r.now(); !now.IsZero() {
+		t.next = now.Add(interval)
+	}
+	r.timers[name] = t
+	r.mu.Unlock()
+
+	r.wake()
+}
+
+// StopTimer cancels the named timer
+func (r *Runtime) StopTimer(name string) {
+	r.mu.Lock()
+	delete(r.timers, name)
+	r.mu.Unlock()
+}
+
+// Stop asks Run to shut down once the current callback returns
+func (r *Runtime) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stopping {
+		r.stopping = true
+		close(r.stop)
+	}
+}
+
+// OnError sets a callback for failures outside strategy callbacks, such as
+// order resolution, reconciliation and malformed stream messages
+func (r *Runtime) OnError(handler func(error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onError = handler
+}
+
+// Run seeds positions, subscribes, calls OnStart and then delivers events
+// until ctx is done, Stop is called or a callback fails. It then calls
+// OnStop, cancels every open order and unsubscribes. Cancelling ctx stops
+// the runtime cleanly like Stop, so Run returns nil after either; it returns
+// ctx.Err() when ctx's deadline passes. A Runtime runs once.
+func (r *Runtime) Run(ctx context.Context, s Strategy) error {
+	if r.ws == nil {
+		return fmt.Errorf("runtime needs a WebSocket subscriber")
+	}
+	if err := r.begin(s); err != nil {
+		return err
+	}
+
+	if err := r.tracker.Seed(ctx); err != nil {
+		return fmt.Errorf("failed to seed positions: %w", err)
+	}
+	r.enqueueStart()
+
+	if err := r.subscribe(); err != nil {
+		return errors.Join(err, r.unsubscribe())
+	}
+	if err := r.orders.Start(ctx); err != nil {
+		return errors.Join(err, r.unsubscribe())
+	}
+	if err := r.tracker.Start(ctx); err != nil {
+		r.orders.Stop()
+		return errors.Join(err, r.unsubscribe())
+	}
+
+	err := r.loop(ctx)
+	// Including a callback cut short by the cancellation
+	if errors.Is(err, context.Canceled) && errors.Is(ctx.Err(), context.Canceled) {
+		err = nil
+	}
+
+	r.orders.Stop()
+	r.tracker.Stop()
+	return errors.Join(err, r.unsubscribe(), r.shutdown())
+}
+
+// begin binds the strategy, failing if the runtime has already run
+func (r *Runtime) begin(s Strategy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.strategy != nil {
+		return fmt.Errorf("runtime already ran")
+	}
+	r.strategy = s
+	return nil
+}
+
+func (r *Runtime) enqueueStart() {
+	r.enqueue(func(ctx context.Context, s Strategy) error {
+		r.mu.Lock()
+		r.started = true
+		r.mu.Unlock()
+
+		if err := s.OnStart(ctx, r); err != nil {
+			return fmt.Errorf("strategy failed to start: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Runtime) loop(ctx context.Context) error {
+	var wait *time.Timer
+	defer func() {
+		if wait != nil {
+			wait.Stop()
+		}
+	}()
+
+	for {
+		if err := r.drain(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-r.stop:
+			return nil
+		default:
+		}
+
+		var fire <-chan time.Time
+		if next, ok := r.nextTimer(); ok {
+			if wait == nil {
+				wait = time.NewTimer(time.Until(next))
+			} else {
+				wait.Reset(time.Until(next))
+			}
+			fire = wait.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.stop:
+			return nil
+		case <-r.notify:
+		case <-fire:
+			r.fireTimers()
+		}
+
+		if wait != nil && !wait.Stop() {
+			select {
+			case <-wait.C:
+			default:
+			}
+		}
+	}
+}
+
+// drain calls queued callbacks in order until the queue is empty or Stop
+// is called
+func (r *Runtime) drain(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		if len(r.queue) == 0 || r.stopping {
+			r.mu.Unlock()
+			return nil
+		}
+		next := r.queue[0]
+		r.queue[0] = nil
+		r.queue = r.queue[1:]
+		s := r.strategy
+		r.mu.Unlock()
+
+		if err := next(ctx, s); err != nil {
+			return err
+		}
+	}
+}
+
+// shutdown calls OnStop if the strategy started and cancels every open
+// order, within the shutdown timeout
+func (r *Runtime) shutdown() error {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return nil
+	}
+	r.stopped = true
+	r.queue = nil
+	s, started := r.strategy, r.started
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if started {
+		if err := s.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("strategy failed to stop: %w", err))
+		}
+	}
+	if _, err := r.backend.CancelAll(ctx, types.CancelFilter{}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to cancel open orders: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// enqueue queues a callback for the strategy. Events arriving after
+// shutdown are dropped.
+func (r *Runtime) enqueue(c call) {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+	r.queue = append(r.queue, c)
+	r.mu.Unlock()
+
+	r.wake()
+}
+
+func (r *Runtime) wake() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Runtime) report(err error) {
+	r.mu.Lock()
+	handler := r.onError
+	r.mu.Unlock()
+
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// nextTimer returns when the earliest timer is due
+func (r *Runtime) nextTimer() (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var next time.Time
+	for _, t := range r.timers {
+		if !t.next.IsZero() && (next.IsZero() || t.next.Before(next)) {
+			next = t.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// fireTimers queues OnTimer for every due timer, earliest first
+func (r *Runtime) fireTimers() {
+	r.mu.Lock()
+	now := r.now()
+	var due []string
+	for name, t := range r.timers {
+		if t.next.IsZero() {
+			t.next = now.Add(t.interval)
+		} else if !t.next.After(now) {
+			due = append(due, name)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		a, b := r.timers[due[i]].next, r.timers[due[j]].next
+		return a.Before(b) || a.Equal(b) && due[i] < due[j]
+	})
+	for _, name := range due {
+		t := r.timers[name]
+		t.next = t.next.Add(t.interval)
+		if !t.next.After(now) {
+			t.next = now.Add(t.interval)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, name := range due {
+		name := name
+		r.enqueue(func(ctx context.Context, s Strategy) error {
+			if err := s.OnTimer(ctx, name, now); err != nil {
+				return fmt.Errorf("strategy failed on timer %s: %w", name, err)
+			}
+			return nil
+		})
+	}
+}
+
+// subscribe follows market data for the configured coins and, unless the
+// backend is a Simulator, the account's order, fill and funding streams
+func (r *Runtime) subscribe() error {
+	type subscription struct {
+		sub    types.WSSubscription
+		handle func(raw json.RawMessage) error
+	}
+	var subs []subscription
+
+	_, candles := r.strategy.(CandleHandler)
+	for _, coin := range r.config.Coins {
+		subs = append(subs,
+			subscription{types.WSSubscription{Type: "l2Book", Coin: coin}, func(raw json.RawMessage) error {
+				var data types.L2BookData
+				if err := json.Unmarshal(raw, &data); err != nil {
+					return err
+				}
+				r.HandleBook(data)
+				return nil
+			}},
+			subscription{types.WSSubscription{Type: "trades", Coin: coin}, func(raw json.RawMessage) error {
+				var data []types.TradeData
+				if err := json.Unmarshal(raw, &data); err != nil {
+					return err
+				}
+				r.HandleTrades(data)
+				return nil
+			}},
+		)
+		if candles && r.config.CandleInterval != "" {
+			subs = append(subs, subscription{types.WSSubscription{Type: "candle", Coin: coin, Interval: r.config.CandleInterval}, func(raw json.RawMessage) error {
+				var data types.CandleData
+				if err := json.Unmarshal(raw, &data); err != nil {
+					return err
+				}
+				r.HandleCandle(data)
+				return nil
+			}})
+		}
+	}
+
+	subs = append(subs, subscription{types.WSSubscription{Type: "allMids"}, func(raw json.RawMessage) error {
+		var data types.AllMidsData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		r.handleMids(data.Mids)
+		return nil
+	}})
+
+	if sim, ok := r.backend.(Simulator); ok {
+		for _, coin := range r.config.Coins {
+			if err := sim.Track(coin); err != nil {
+				return fmt.Errorf("failed to track %s: %w", coin, err)
+			}
+		}
+		sim.OnFills(r.HandleFills)
+		sim.OnOrderUpdates(r.orders.HandleOrderUpdates)
+	} else {
+		subs = append(subs,
+			subscription{types.WSSubscription{Type: "orderUpdates", User: r.config.User}, func(raw json.RawMessage) error {
+				var updates []types.OrderUpdate
+				if err := json.Unmarshal(raw, &updates); err != nil {
+					return err
+				}
+				r.orders.HandleOrderUpdates(updates)
+				return nil
+			}},
+			subscription{types.WSSubscription{Type: "userFills", User: r.config.User}, func(raw json.RawMessage) error {
+				var data types.UserFillsData
+				if err := json.Unmarshal(raw, &data); err != nil {
+					return err
+				}
+				r.HandleFills(data)
+				return nil
+			}},
+			subscription{types.WSSubscription{Type: "userFundings", User: r.config.User}, func(raw json.RawMessage) error {
+				var data types.UserFundingsData
+				if err := json.Unmarshal(raw, &data); err != nil {
+					return err
+				}
+				r.tracker.HandleFundings(data)
+				return nil
+			}},
+		)
+	}
+
+	for _, s := range subs {
+		subID, err := r.ws.Subscribe(s.sub, s.handle)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", s.sub.Type, err)
+		}
+		r.mu.Lock()
+		r.subIDs = append(r.subIDs, subID)
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// unsubscribe stops consuming WebSocket streams
+func (r *Runtime) unsubscribe() error {
+	r.mu.Lock()
+	subIDs := r.subIDs
+	r.subIDs = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for _, subID := range subIDs {
+		if err := r.ws.Unsubscribe(subID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleBook feeds an l2Book snapshot to a Simulator backend and queues it
+// for the strategy
+func (r *Runtime) HandleBook(book types.L2BookData) {
+	if sim, ok := r.backend.(Simulator); ok {
+		if err := sim.HandleBook(book); err != nil {
+			r.report(fmt.Errorf("failed to apply %s book: %w", book.Coin, err))
+		}
+	}
+
+	r.queueBook(book)
+}
+
+func (r *Runtime) queueBook(book types.L2BookData) {
+	r.enqueue(func(ctx context.Context, s Strategy) error {
+		if err := s.OnBook(ctx, book); err != nil {
+			return fmt.Errorf("strategy failed on %s book: %w", book.Coin, err)
+		}
+		return nil
+	})
+}
+
+// HandleTrades feeds trades to a Simulator backend and queues them for the
+// strategy one at a time
+func (r *Runtime) HandleTrades(trades []types.TradeData) {
+	if sim, ok := r.backend.(Simulator); ok {
+		sim.HandleTrades(trades)
+	}
+	r.queueTrades(trades)
+}
+
+func (r *Runtime) queueTrades(trades []types.TradeData) {
+	r.enqueue(func(ctx context.Context, s Strategy) error {
+		for _, trade := range trades {
+			if err := s.OnTrade(ctx, trade); err != nil {
+				return fmt.Errorf("strategy failed on %s trade: %w", trade.Coin, err)
+			}
+		}
+		return nil
+	})
+}
+
+// HandleCandle queues a candle for a strategy implementing CandleHandler
+func (r *Runtime) HandleCandle(candle types.CandleData) {
+	r.enqueue(func(ctx context.Context, s Strategy) error {
+		handler, ok := s.(CandleHandler)
+		if !ok {
+			return nil
+		}
+		if err := handler.OnCandle(ctx, candle); err != nil {
+			return fmt.Errorf("strategy failed on %s candle: %w", candle.Coin, err)
+		}
+		return nil
+	})
+}
+
+// HandleFills applies fills to the position tracker and order manager,
+// then queues them for the strategy. Snapshot fills only update state.
+func (r *Runtime) HandleFills(data types.UserFillsData) {
+	r.tracker.HandleFills(data)
+	r.orders.HandleUserFills(data)
+	if data.IsSnapshot {
+		return
+	}
+
+	r.enqueue(func(ctx context.Context, s Strategy) error {
+		for _, fill := range data.Fills {
+			if err := s.OnFill(ctx, fill); err != nil {
+				return fmt.Errorf("strategy failed on %s fill: %w", fill.Coin, err)
+			}
+		}
+		return nil
+	})
+}
+
+// handleMids marks positions and updates the guard's reference prices
+func (r *Runtime) handleMids(mids map[string]string) {
+	r.tracker.HandleMids(mids)
+	if r.guard != nil {
+		r.guard.HandleMids(mids)
+	}
+}
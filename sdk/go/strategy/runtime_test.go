@@ -0,0 +1,302 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/backtest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/hltest"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/paper"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/risk"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const testKey = "0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// recorder logs callbacks and fails on any overlap between them
+type recorder struct {
+	Base
+	rt          *Runtime
+	active      atomic.Int32
+	overlapped  bool
+	fills       []types.UserFillData
+	events      []orders.Event
+	ticks       []time.Time
+	stopped     bool
+	onStart     func(ctx context.Context) error
+	onBook      func(ctx context.Context, book types.L2BookData) error
+	onCandle    func(ctx context.Context, candle types.CandleData) error
+	afterUpdate func()
+}
+
+func (r *recorder) enter() func() {
+	if r.active.Add(1) > 1 {
+		r.overlapped = true
+	}
+	return func() { r.active.Add(-1) }
+}
+
+func (r *recorder) OnStart(ctx context.Context, rt *Runtime) error {
+	defer r.enter()()
+	r.rt = rt
+	if r.onStart != nil {
+		return r.onStart(ctx)
+	}
+	return nil
+}
+
+func (r *recorder) OnBook(ctx context.Context, book types.L2BookData) error {
+	defer r.enter()()
+	if r.onBook != nil {
+		return r.onBook(ctx, book)
+	}
+	return nil
+}
+
+func (r *recorder) OnCandle(ctx context.Context, candle types.CandleData) error {
+	defer r.enter()()
+	if r.onCandle != nil {
+		return r.onCandle(ctx, candle)
+	}
+	return nil
+}
+
+func (r *recorder) OnFill(ctx context.Context, fill types.UserFillData) error {
+	defer r.enter()()
+	r.fills = append(r.fills, fill)
+	r.check()
+	return nil
+}
+
+func (r *recorder) OnOrderUpdate(ctx context.Context, event orders.Event) error {
+	defer r.enter()()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) OnTimer(ctx context.Context, name string, now time.Time) error {
+	defer r.enter()()
+	r.ticks = append(r.ticks, now)
+	r.check()
+	return nil
+}
+
+func (r *recorder) OnStop(ctx context.Context) error {
+	defer r.enter()()
+	r.stopped = true
+	return nil
+}
+
+func (r *recorder) check() {
+	if r.afterUpdate != nil {
+		r.afterUpdate()
+	}
+}
+
+func run(t *testing.T, rt *Runtime, s Strategy) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rt.Run(ctx, s); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+}
+
+func connect(t *testing.T, srv *hltest.Server) *websocket.Manager {
+	t.Helper()
+
+	ws := websocket.NewManager(srv.WSURL)
+	if err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { ws.Disconnect() })
+	return ws
+}
+
+func TestLiveRuntime(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+
+	address, _ := utils.GetAddressFromPrivateKey(testKey)
+	c := client.NewClient(srv.URL, srv.WSURL, testKey)
+	c.SetAddress(address)
+
+	rt := NewRuntime(Live(c), connect(t, srv), Config{User: address, Coins: []string{"BTC"}})
+	s := &recorder{}
+	s.onStart = func(ctx context.Context) error {
+		rt.SetTimer("tick", 10*time.Millisecond)
+		_, err := rt.Orders().PlaceOrders(ctx, []types.OrderRequest{
+			hltest.Limit("BTC", false, "51000", "1", types.TifGtc),
+			hltest.Limit("BTC", false, "52000", "1", types.TifGtc),
+		}, types.GroupingNA)
+		return err
+	}
+	// Once both asks show in the book, lift part of the first
+	lifted := false
+	s.onBook = func(ctx context.Context, book types.L2BookData) error {
+		_, asks, err := types.ParseL2Levels(book.Levels)
+		if err == nil && len(asks) == 2 && !lifted {
+			lifted = true
+			srv.Engine().Place("0xtaker", hltest.Limit("BTC", true, "51000", "0.25", types.TifIoc))
+		}
+		return nil
+	}
+	s.afterUpdate = func() {
+		if len(s.fills) > 0 && len(s.ticks) >= 2 {
+			rt.Stop()
+		}
+	}
+
+	run(t, rt, s)
+
+	if s.overlapped {
+		t.Errorf("Expected callbacks never to overlap")
+	}
+	if len(s.fills) != 1 || !s.fills[0].Sz.Equal(hltest.Dec("0.25")) || !s.stopped {
+		t.Fatalf("Expected one 0.25 fill and a stop, got %+v", s.fills)
+	}
+	if p, _ := rt.Positions().Position("BTC"); !p.Szi.Equal(hltest.Dec("-0.25")) {
+		t.Errorf("Expected a 0.25 short, got %s", p.Szi)
+	}
+	if open := srv.Engine().OpenOrders(address); len(open) != 0 {
+		t.Errorf("Expected every order canceled on shutdown, got %+v", open)
+	}
+}
+
+func TestRunStopsCleanlyOnCancel(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+
+	address, _ := utils.GetAddressFromPrivateKey(testKey)
+	c := client.NewClient(srv.URL, srv.WSURL, testKey)
+	c.SetAddress(address)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := NewRuntime(Live(c), connect(t, srv), Config{User: address, Coins: []string{"BTC"}})
+	s := &recorder{}
+	// The order placed after cancelling fails with the context
+	s.onStart = func(ctx context.Context) error {
+		cancel()
+		_, err := rt.Orders().Place(ctx, hltest.Limit("BTC", true, "49000", "1", types.TifGtc))
+		return err
+	}
+
+	if err := rt.Run(ctx, s); err != nil {
+		t.Errorf("Expected a clean stop on cancel, got %v", err)
+	}
+	if !s.stopped {
+		t.Error("Expected OnStop to run")
+	}
+}
+
+func TestPaperRuntime(t *testing.T) {
+	srv := hltest.NewServer(hltest.Config{})
+	defer srv.Close()
+	srv.Engine().Place("0xmaker", hltest.Limit("BTC", false, "50000", "2", types.TifGtc))
+
+	c := client.NewClient(srv.URL, srv.WSURL, testKey)
+	exchange := paper.NewPaperExchange(nil, "0xpaper", paper.Config{Balance: hltest.Dec("100000")})
+	rt := NewRuntime(exchange, connect(t, srv), Config{
+		User:   "0xpaper",
+		Coins:  []string{"BTC"},
+		Limits: &risk.Limits{MaxOrderNotional: hltest.Dec("30000")},
+		Assets: c.Info(),
+	})
+
+	s := &recorder{}
+	var violation *risk.Violation
+	s.onBook = func(ctx context.Context, book types.L2BookData) error {
+		if violation != nil {
+			return nil
+		}
+		if _, err := rt.Orders().Place(ctx, hltest.Limit("BTC", true, "50000", "1", types.TifIoc)); !errors.As(err, &violation) {
+			t.Errorf("Expected a notional violation, got %v", err)
+		}
+		_, err := rt.Orders().Place(ctx, hltest.Limit("BTC", true, "50000", "0.5", types.TifIoc))
+		return err
+	}
+	s.afterUpdate = func() { rt.Stop() }
+
+	run(t, rt, s)
+
+	if violation == nil || violation.Check != risk.CheckOrderNotional {
+		t.Errorf("Expected the guard to reject the large order, got %v", violation)
+	}
+	if len(s.fills) != 1 || !s.fills[0].Px.Equal(hltest.Dec("50000")) {
+		t.Fatalf("Expected a paper fill at 50000, got %+v", s.fills)
+	}
+	if p, _ := rt.Positions().Position("BTC"); !p.Szi.Equal(hltest.Dec("0.5")) {
+		t.Errorf("Expected a 0.5 long, got %s", p.Szi)
+	}
+	if n := len(srv.Engine().Fills("0xmaker")); n != 0 {
+		t.Errorf("Expected paper orders to stay off the exchange, got %d maker fills", n)
+	}
+}
+
+func TestBacktestRuntime(t *testing.T) {
+	var bars []types.Candle
+	for i, c := range []string{"100", "105", "110", "108"} {
+		px := hltest.Dec(c)
+		bars = append(bars, types.Candle{
+			T: int64(i) * time.Hour.Milliseconds(),
+			O: px, H: px.Add(decimal.NewFromInt(1)), L: px.Sub(decimal.NewFromInt(1)), C: px,
+			V: hltest.Dec("10"),
+		})
+	}
+	events, err := backtest.FromCandles("BTC", "1h", bars)
+	if err != nil {
+		t.Fatalf("Failed to convert candles: %v", err)
+	}
+
+	s := &recorder{}
+	s.onStart = func(ctx context.Context) error {
+		s.rt.SetTimer("half-hour", 30*time.Minute)
+		return nil
+	}
+	candles := 0
+	s.onCandle = func(ctx context.Context, candle types.CandleData) error {
+		candles++
+		var err error
+		switch candles {
+		case 1:
+			_, err = s.rt.Orders().Place(ctx, hltest.Limit("BTC", true, "101", "1", types.TifIoc))
+		case 2:
+			_, err = s.rt.Orders().Place(ctx, hltest.Limit("BTC", true, "50", "1", types.TifGtc))
+		}
+		return err
+	}
+	var position decimal.Decimal
+	s.afterUpdate = func() {
+		if p, ok := s.rt.Positions().Position("BTC"); ok {
+			position = p.Szi
+		}
+	}
+
+	engine := backtest.NewEngine(backtest.Config{Balance: hltest.Dec("10000")})
+	report, err := Backtest(context.Background(), engine, backtest.Replay(events), s, Config{})
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if len(s.fills) != 1 || !position.Equal(hltest.Dec("1")) || len(report.Trades) != 0 {
+		t.Errorf("Expected one fill opening a long, got %+v", s.fills)
+	}
+	// Scheduled at the first candle, then once per candle with missed ticks dropped
+	if len(s.ticks) != 3 || !s.ticks[0].Equal(time.UnixMilli(2*time.Hour.Milliseconds())) {
+		t.Errorf("Expected 3 ticks from 2h on simulated time, got %v", s.ticks)
+	}
+	if open := s.rt.Orders().Open(); len(open) != 0 || !s.stopped {
+		t.Errorf("Expected the resting bid canceled on stop, got %+v", open)
+	}
+}
@@ -0,0 +1,111 @@
+// Package strategy runs trading strategies on live, paper or backtest
+// execution with order tracking, positions and risk checks wired in.
+package strategy
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/client"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/orders"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/paper"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/positions"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// Strategy is driven by a Runtime. Callbacks are never called concurrently
+// or re-entrantly: events caused by a callback, such as updates to an order
+// it placed, are delivered after it returns. A callback error stops the
+// runtime.
+type Strategy interface {
+	OnStart(ctx context.Context, rt *Runtime) error
+	OnBook(ctx context.Context, book types.L2BookData) error
+	OnTrade(ctx context.Context, trade types.TradeData) error
+	OnFill(ctx context.Context, fill types.UserFillData) error
+	OnOrderUpdate(ctx context.Context, event orders.Event) error
+	OnTimer(ctx context.Context, name string, now time.Time) error
+	OnStop(ctx context.Context) error
+}
+
+// CandleHandler is implemented by strategies that consume candles
+type CandleHandler interface {
+	OnCandle(ctx context.Context, candle types.CandleData) error
+}
+
+// Base implements every Strategy callback as a no-op. Embed it to handle
+// only the events a strategy needs.
+type Base struct{}
+
+func (Base) OnStart(ctx context.Context, rt *Runtime) error                { return nil }
+func (Base) OnBook(ctx context.Context, book types.L2BookData) error       { return nil }
+func (Base) OnTrade(ctx context.Context, trade types.TradeData) error      { return nil }
+func (Base) OnFill(ctx context.Context, fill types.UserFillData) error     { return nil }
+func (Base) OnOrderUpdate(ctx context.Context, event orders.Event) error   { return nil }
+func (Base) OnTimer(ctx context.Context, name string, now time.Time) error { return nil }
+func (Base) OnStop(ctx context.Context) error                              { return nil }
+
+// Backend executes orders and answers account queries. Live adapts a
+// client; *paper.PaperExchange satisfies it directly.
+type Backend interface {
+	client.Trader
+	orders.StatusSource
+	positions.StateSource
+}
+
+// Simulator is a Backend that matches orders against the market data the
+// runtime feeds it, and reports account events through callbacks instead of
+// the user streams. *paper.PaperExchange satisfies it.
+type Simulator interface {
+	Backend
+	Track(coin string) error
+	HandleBook(data types.L2BookData) error
+	HandleTrades(trades []types.TradeData)
+	OnFills(handler func(types.UserFillsData))
+	OnOrderUpdates(handler func([]types.OrderUpdate))
+}
+
+var _ Simulator = (*paper.PaperExchange)(nil)
+
+// live routes orders through the exchange API and queries the info API
+type live struct {
+	exchange *client.ExchangeClient
+	info     *client.InfoClient
+}
+
+// Live returns a Backend trading c's account on the exchange. It also
+// supplies asset metadata for risk limits.
+func Live(c *client.Client) Backend {
+	return &live{exchange: c.Exchange(), info: c.Info()}
+}
+
+func (l *live) PlaceOrder(ctx context.Context, order types.OrderRequest) (*types.OrderResponse, error) {
+	return l.exchange.PlaceOrder(ctx, order)
+}
+
+func (l *live) PlaceOrders(ctx context.Context, orders []types.OrderRequest, grouping types.Grouping) (*types.OrderResponse, error) {
+	return l.exchange.PlaceOrders(ctx, orders, grouping)
+}
+
+func (l *live) BatchModify(ctx context.Context, modifies []types.ModifyRequest) (*types.OrderResponse, error) {
+	return l.exchange.BatchModify(ctx, modifies)
+}
+
+func (l *live) CancelByCloid(ctx context.Context, coin string, cloids ...string) ([]types.ActionStatus, error) {
+	return l.exchange.CancelByCloid(ctx, coin, cloids...)
+}
+
+func (l *live) CancelAll(ctx context.Context, filter types.CancelFilter) ([]types.CancelResult, error) {
+	return l.exchange.CancelAll(ctx, filter)
+}
+
+func (l *live) GetOrderStatus(ctx context.Context, user string, oid *int64, cloid *string) (map[string]interface{}, error) {
+	return l.info.GetOrderStatus(ctx, user, oid, cloid)
+}
+
+func (l *live) GetUserState(ctx context.Context, user string) (*types.UserState, error) {
+	return l.info.GetUserState(ctx, user)
+}
+
+func (l *live) GetAssetInfo(ctx context.Context, coin string) (*types.AssetInfo, error) {
+	return l.info.GetAssetInfo(ctx, coin)
+}
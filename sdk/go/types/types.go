@@ -2,6 +2,8 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -29,6 +31,13 @@ type LimitOrderType struct {
 	Tif string `json:"tif"` // "Gtc", "Ioc", "Alo"
 }
 
+// Time in force values for LimitOrderType.Tif
+const (
+	TifGtc = "Gtc" // Good till cancelled
+	TifIoc = "Ioc" // Immediate or cancel
+	TifAlo = "Alo" // Add liquidity only (post only)
+)
+
 // TriggerOrderType represents a trigger order configuration
 type TriggerOrderType struct {
 	TriggerPx decimal.Decimal `json:"trigger_px"`
@@ -36,20 +45,94 @@ type TriggerOrderType struct {
 	TpSl      string          `json:"tp_sl"` // "tp" or "sl"
 }
 
-// CancelRequest represents a request to cancel orders
+// MarginMode selects cross or isolated margin for a perp position
+type MarginMode string
+
+const (
+	MarginCross    MarginMode = "cross"
+	MarginIsolated MarginMode = "isolated"
+)
+
+// UsdcDecimals is the precision of integer micro-USDC amounts
+const UsdcDecimals = 6
+
+// BuilderInfo attaches a builder code to an order action. Fee is in tenths
+// of a basis point (10 = 1bp).
+type BuilderInfo struct {
+	Builder string `json:"b"`
+	Fee     int    `json:"f"`
+}
+
+// Maximum builder fees in tenths of a basis point
+const (
+	MaxPerpBuilderFee = 100  // 0.1%
+	MaxSpotBuilderFee = 1000 // 1%
+)
+
+// Trigger kinds for TriggerOrderType.TpSl
+const (
+	TpSlTakeProfit = "tp"
+	TpSlStopLoss   = "sl"
+)
+
+// Validate checks the trigger kind and price
+func (t TriggerOrderType) Validate() error {
+	if t.TpSl != TpSlTakeProfit && t.TpSl != TpSlStopLoss {
+		return fmt.Errorf("trigger kind must be %q or %q, got %q", TpSlTakeProfit, TpSlStopLoss, t.TpSl)
+	}
+	if !t.TriggerPx.IsPositive() {
+		return fmt.Errorf("trigger price must be positive")
+	}
+	return nil
+}
+
+// Grouping controls how the orders of one order action are linked
+type Grouping string
+
+const (
+	// GroupingNA places independent orders
+	GroupingNA Grouping = "na"
+	// GroupingNormalTpsl links an entry with TP/SL orders sized to the entry
+	GroupingNormalTpsl Grouping = "normalTpsl"
+	// GroupingPositionTpsl attaches TP/SL orders to the whole position
+	GroupingPositionTpsl Grouping = "positionTpsl"
+)
+
+// CancelRequest names an order to cancel by coin and exactly one of Oid or
+// Cloid. ExchangeClient.CancelOrders converts it to the exchange's wire form.
 type CancelRequest struct {
 	Asset string  `json:"coin"`
 	Oid   *int64  `json:"oid,omitempty"`
 	Cloid *string `json:"cloid,omitempty"`
 }
 
-// ModifyRequest represents a request to modify an order
+// ModifyRequest replaces a resting order, identified by exactly one of Oid
+// or Cloid, with a complete new order
 type ModifyRequest struct {
-	Asset   string          `json:"coin"`
-	Oid     int64           `json:"oid"`
-	IsBuy   bool            `json:"is_buy"`
-	LimitPx decimal.Decimal `json:"limit_px"`
-	Sz      decimal.Decimal `json:"sz"`
+	Oid   *int64       `json:"-"`
+	Cloid *string      `json:"-"`
+	Order OrderRequest `json:"order"`
+}
+
+// MarshalJSON encodes the wire format, where "oid" holds either the order ID
+// or the client order ID
+func (m ModifyRequest) MarshalJSON() ([]byte, error) {
+	var id interface{}
+	switch {
+	case m.Oid != nil && m.Cloid != nil:
+		return nil, fmt.Errorf("modify must set only one of oid or cloid")
+	case m.Oid != nil:
+		id = *m.Oid
+	case m.Cloid != nil:
+		id = *m.Cloid
+	default:
+		return nil, fmt.Errorf("modify requires an oid or cloid")
+	}
+
+	return json.Marshal(struct {
+		Oid   interface{}  `json:"oid"`
+		Order OrderRequest `json:"order"`
+	}{id, m.Order})
 }
 
 // UserState represents a user's account state
@@ -176,6 +259,7 @@ type Fill struct {
 	Fee          decimal.Decimal `json:"fee"`
 	Tid          int64           `json:"tid"`
 	FeeToken     string          `json:"feeToken"`
+	Cloid        *string         `json:"cloid,omitempty"`
 }
 
 // FundingHistory represents funding payment history
@@ -190,7 +274,8 @@ type FundingHistory struct {
 
 // SpotMeta represents spot token metadata
 type SpotMeta struct {
-	Universe []SpotToken `json:"universe"`
+	Universe []SpotToken     `json:"universe"`
+	Tokens   []SpotTokenInfo `json:"tokens"`
 }
 
 // SpotToken represents a spot token
@@ -198,6 +283,28 @@ type SpotToken struct {
 	Name     string `json:"name"`
 	SzDecimals int    `json:"szDecimals"`
 	TokenId  int    `json:"tokenId"`
+	Tokens   []int  `json:"tokens"` // [base, quote] indices into SpotMeta.Tokens
+	Index    int    `json:"index"`
+}
+
+// SpotTokenInfo represents a token listed on the spot exchange
+type SpotTokenInfo struct {
+	Name        string `json:"name"`
+	SzDecimals  int    `json:"szDecimals"`
+	WeiDecimals int    `json:"weiDecimals"`
+	Index       int    `json:"index"`
+	TokenId     string `json:"tokenId"`
+	IsCanonical bool   `json:"isCanonical"`
+}
+
+// AssetInfo describes a tradable asset resolved from perp or spot metadata
+type AssetInfo struct {
+	ID           int    // Asset index used on the wire (spot assets are offset by 10000)
+	Coin         string
+	SzDecimals   int
+	MaxLeverage  int
+	OnlyIsolated bool
+	IsSpot       bool
 }
 
 // Meta represents market metadata
@@ -265,6 +372,131 @@ type FilledOrder struct {
 	Oid     int64           `json:"oid"`
 }
 
+// TwapResponse represents a response from TWAP order placement
+type TwapResponse struct {
+	Status string `json:"status"`
+	Response struct {
+		Type string `json:"type"`
+		Data struct {
+			Status TwapOrderStatus `json:"status"`
+		} `json:"data"`
+	} `json:"response"`
+}
+
+// TwapOrderStatus represents the status of a placed TWAP order
+type TwapOrderStatus struct {
+	Running *TwapRunning `json:"running,omitempty"`
+	Error   *string      `json:"error,omitempty"`
+}
+
+// TwapRunning identifies a running TWAP order
+type TwapRunning struct {
+	TwapId int64 `json:"twapId"`
+}
+
+// TwapCancelResponse represents a response from TWAP cancellation
+type TwapCancelResponse struct {
+	Status string `json:"status"`
+	Response struct {
+		Type string `json:"type"`
+		Data struct {
+			Status ActionStatus `json:"status"`
+		} `json:"data"`
+	} `json:"response"`
+}
+
+// ActionStatus is the per-action result of simple exchange actions, either
+// the string "success" or an object carrying an error
+type ActionStatus struct {
+	Success bool
+	Error   *string
+}
+
+// UnmarshalJSON decodes either "success" or {"error": "..."}
+func (a *ActionStatus) UnmarshalJSON(data []byte) error {
+	var status string
+	if err := json.Unmarshal(data, &status); err == nil {
+		a.Success = status == "success"
+		if !a.Success {
+			a.Error = &status
+		}
+		return nil
+	}
+
+	var failure struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &failure); err != nil {
+		return err
+	}
+	a.Error = &failure.Error
+	return nil
+}
+
+// MarshalJSON encodes the status in its wire form
+func (a ActionStatus) MarshalJSON() ([]byte, error) {
+	if a.Error != nil {
+		return json.Marshal(map[string]string{"error": *a.Error})
+	}
+	return json.Marshal("success")
+}
+
+// CancelResponse represents a response from a cancel or cancelByCloid action
+type CancelResponse struct {
+	Status string `json:"status"`
+	Response struct {
+		Type string `json:"type"`
+		Data struct {
+			Statuses []ActionStatus `json:"statuses"`
+		} `json:"data"`
+	} `json:"response"`
+}
+
+// CancelFilter selects open orders for CancelAll. Zero values match every
+// order.
+type CancelFilter struct {
+	Coins       []string      // Only these coins
+	IsBuy       *bool         // Only bids (true) or asks (false)
+	CloidPrefix string        // Only orders whose cloid starts with this prefix
+	MinAge      time.Duration // Only orders resting at least this long
+}
+
+// Matches reports whether order is selected by the filter at time now
+func (f CancelFilter) Matches(order OpenOrder, now time.Time) bool {
+	if len(f.Coins) > 0 {
+		found := false
+		for _, coin := range f.Coins {
+			if coin == order.Coin {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.IsBuy != nil && *f.IsBuy != (order.Side == "B") {
+		return false
+	}
+
+	if f.CloidPrefix != "" && (order.Cloid == nil || !strings.HasPrefix(*order.Cloid, f.CloidPrefix)) {
+		return false
+	}
+
+	if f.MinAge > 0 && now.Sub(time.UnixMilli(order.Timestamp)) < f.MinAge {
+		return false
+	}
+
+	return true
+}
+
+// CancelResult is the outcome of cancelling one open order
+type CancelResult struct {
+	Order  OpenOrder
+	Status ActionStatus
+}
+
 // WebSocket Message Types
 
 // WSSubscription represents a WebSocket subscription request
@@ -273,6 +505,8 @@ type WSSubscription struct {
 	Coin     string      `json:"coin,omitempty"`
 	User     string      `json:"user,omitempty"`
 	Interval string      `json:"interval,omitempty"`
+	NSigFigs *int        `json:"nSigFigs,omitempty"` // l2Book price aggregation (2-5 significant figures)
+	Mantissa *int        `json:"mantissa,omitempty"` // l2Book mantissa (1, 2 or 5), only with nSigFigs 5
 }
 
 // WSMessage represents a generic WebSocket message
@@ -328,10 +562,96 @@ type CandleData struct {
 	N        int             `json:"n"`
 }
 
-// UserEvent represents a user event (fill, funding, liquidation)
+// UserEventType discriminates the variants of the userEvents channel
+type UserEventType string
+
+const (
+	UserEventFills         UserEventType = "fills"
+	UserEventFunding       UserEventType = "funding"
+	UserEventLiquidation   UserEventType = "liquidation"
+	UserEventNonUserCancel UserEventType = "nonUserCancel"
+)
+
+// UserEvent represents a user event (fills, funding, liquidation or
+// non-user cancels). Exactly one payload field is set, as given by Type.
 type UserEvent struct {
-	Type string          `json:"type"`
-	Data json.RawMessage `json:"data"`
+	Type          UserEventType
+	Fills         []UserFillData
+	Funding       *FundingData
+	Liquidation   *LiquidationData
+	NonUserCancel []NonUserCancel
+}
+
+// UnmarshalJSON decodes the userEvents union, which is keyed by variant name
+func (e *UserEvent) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for key, value := range raw {
+		var err error
+		switch UserEventType(key) {
+		case UserEventFills:
+			err = json.Unmarshal(value, &e.Fills)
+		case UserEventFunding:
+			e.Funding = &FundingData{}
+			err = json.Unmarshal(value, e.Funding)
+		case UserEventLiquidation:
+			e.Liquidation = &LiquidationData{}
+			err = json.Unmarshal(value, e.Liquidation)
+		case UserEventNonUserCancel:
+			err = json.Unmarshal(value, &e.NonUserCancel)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode %s event: %w", key, err)
+		}
+		e.Type = UserEventType(key)
+		return nil
+	}
+
+	return fmt.Errorf("unknown user event")
+}
+
+// MarshalJSON encodes the event in the keyed union form used on the wire
+func (e UserEvent) MarshalJSON() ([]byte, error) {
+	var value interface{}
+	switch e.Type {
+	case UserEventFills:
+		value = e.Fills
+	case UserEventFunding:
+		value = e.Funding
+	case UserEventLiquidation:
+		value = e.Liquidation
+	case UserEventNonUserCancel:
+		value = e.NonUserCancel
+	default:
+		return nil, fmt.Errorf("unknown user event type: %s", e.Type)
+	}
+	return json.Marshal(map[string]interface{}{string(e.Type): value})
+}
+
+// NonUserCancel represents an order cancelled by the exchange rather than the user
+type NonUserCancel struct {
+	Coin string `json:"coin"`
+	Oid  int64  `json:"oid"`
+}
+
+// UserFillsData represents a userFills message. The first message after
+// subscribing is a snapshot of recent fills.
+type UserFillsData struct {
+	IsSnapshot bool           `json:"isSnapshot"`
+	User       string         `json:"user"`
+	Fills      []UserFillData `json:"fills"`
+}
+
+// UserFundingsData represents a userFundings message
+type UserFundingsData struct {
+	IsSnapshot bool          `json:"isSnapshot"`
+	User       string        `json:"user"`
+	Fundings   []FundingData `json:"fundings"`
 }
 
 // UserFillData represents user fill data from WebSocket
@@ -351,19 +671,25 @@ type UserFillData struct {
 	Fee          decimal.Decimal `json:"fee"`
 	Tid          int64           `json:"tid"`
 	FeeToken     string          `json:"feeToken"`
+	Cloid        *string         `json:"cloid,omitempty"`
 }
 
-// OrderUpdate represents an order update from WebSocket
+// OrderUpdate is one element of an orderUpdates message
 type OrderUpdate struct {
-	User      string          `json:"user"`
+	Order           BasicOrder `json:"order"`
+	Status          string     `json:"status"` // e.g. open, filled, canceled, triggered, rejected, marginCanceled
+	StatusTimestamp int64      `json:"statusTimestamp"`
+}
+
+// BasicOrder is the order carried by orderUpdates and orderStatus
+type BasicOrder struct {
 	Coin      string          `json:"coin"`
+	Side      string          `json:"side"`
+	LimitPx   decimal.Decimal `json:"limitPx"`
+	Sz        decimal.Decimal `json:"sz"` // Remaining size
 	Oid       int64           `json:"oid"`
-	Update    string          `json:"update"`
-	Status    string          `json:"status"`
-	LimitPx   decimal.Decimal `json:"limitPx,omitempty"`
-	Sz        decimal.Decimal `json:"sz,omitempty"`
-	Side      string          `json:"side,omitempty"`
 	Timestamp int64           `json:"timestamp"`
+	OrigSz    decimal.Decimal `json:"origSz"`
 	Cloid     *string         `json:"cloid,omitempty"`
 }
 
@@ -380,13 +706,11 @@ type FundingData struct {
 
 // LiquidationData represents liquidation event data
 type LiquidationData struct {
-	User        string          `json:"user"`
-	Coin        string          `json:"coin"`
-	Szi         decimal.Decimal `json:"szi"`
-	Px          decimal.Decimal `json:"px"`
-	Time        int64           `json:"time"`
-	Liq         string          `json:"liq"`
-	Reason      string          `json:"reason"`
+	Lid                    int64           `json:"lid"`
+	Liquidator             string          `json:"liquidator"`
+	LiquidatedUser         string          `json:"liquidated_user"`
+	LiquidatedNtlPos       decimal.Decimal `json:"liquidated_ntl_pos"`
+	LiquidatedAccountValue decimal.Decimal `json:"liquidated_account_value"`
 }
 
 // NotificationData represents notification data
@@ -396,20 +720,225 @@ type NotificationData struct {
 	Time         int64  `json:"time"`
 }
 
-// WebData2Data represents web data v2
+// WebData2Data represents web data v2, the aggregate account view used by the web frontend
 type WebData2Data struct {
-	User        string          `json:"user"`
-	UserSummary json.RawMessage `json:"userSummary"`
-	Time        int64           `json:"time"`
+	User               string                  `json:"user"`
+	ClearinghouseState UserState               `json:"clearinghouseState"`
+	LeadingVaults      []LeadingVault          `json:"leadingVaults"`
+	TotalVaultEquity   decimal.Decimal         `json:"totalVaultEquity"`
+	OpenOrders         []OpenOrder             `json:"openOrders"`
+	AgentAddress       *string                 `json:"agentAddress"`
+	AgentValidUntil    *int64                  `json:"agentValidUntil"`
+	CumLedger          decimal.Decimal         `json:"cumLedger"`
+	Meta               Meta                    `json:"meta"`
+	AssetCtxs          []PerpAssetCtx          `json:"assetCtxs"`
+	ServerTime         int64                   `json:"serverTime"`
+	IsVault            bool                    `json:"isVault"`
+	TwapStates         []TwapStateEntry        `json:"twapStates"`
+	SpotState          *SpotClearinghouseState `json:"spotState,omitempty"`
+}
+
+// LeadingVault represents a vault led by the user
+type LeadingVault struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+}
+
+// SpotClearinghouseState represents a user's spot balances
+type SpotClearinghouseState struct {
+	Balances []SpotBalance `json:"balances"`
+}
+
+// SpotBalance represents a spot token balance
+type SpotBalance struct {
+	Coin     string          `json:"coin"`
+	Token    int             `json:"token"`
+	Total    decimal.Decimal `json:"total"`
+	Hold     decimal.Decimal `json:"hold"`
+	EntryNtl decimal.Decimal `json:"entryNtl"`
+}
+
+// PerpAssetCtx represents the market context of a perpetual
+type PerpAssetCtx struct {
+	Funding      decimal.Decimal   `json:"funding"`
+	OpenInterest decimal.Decimal   `json:"openInterest"`
+	PrevDayPx    decimal.Decimal   `json:"prevDayPx"`
+	DayNtlVlm    decimal.Decimal   `json:"dayNtlVlm"`
+	DayBaseVlm   decimal.Decimal   `json:"dayBaseVlm"`
+	Premium      *decimal.Decimal  `json:"premium"`
+	OraclePx     decimal.Decimal   `json:"oraclePx"`
+	MarkPx       decimal.Decimal   `json:"markPx"`
+	MidPx        *decimal.Decimal  `json:"midPx"`
+	ImpactPxs    []decimal.Decimal `json:"impactPxs"`
+}
+
+// SpotAssetCtx represents the market context of a spot pair
+type SpotAssetCtx struct {
+	Coin              string           `json:"coin"`
+	DayNtlVlm         decimal.Decimal  `json:"dayNtlVlm"`
+	DayBaseVlm        decimal.Decimal  `json:"dayBaseVlm"`
+	MarkPx            decimal.Decimal  `json:"markPx"`
+	MidPx             *decimal.Decimal `json:"midPx"`
+	PrevDayPx         decimal.Decimal  `json:"prevDayPx"`
+	CirculatingSupply decimal.Decimal  `json:"circulatingSupply"`
+	TotalSupply       decimal.Decimal  `json:"totalSupply"`
 }
 
 // ActiveAssetCtxData represents active asset context
 type ActiveAssetCtxData struct {
 	Coin         string          `json:"coin"`
-	Ctx          json.RawMessage `json:"ctx"`
+	Ctx          PerpAssetCtx    `json:"ctx"`
 	Time         int64           `json:"time"`
 }
 
+// ActiveSpotAssetCtxData represents active spot asset context
+type ActiveSpotAssetCtxData struct {
+	Coin string       `json:"coin"`
+	Ctx  SpotAssetCtx `json:"ctx"`
+}
+
+// ClearinghouseStateData represents a clearinghouseState message
+type ClearinghouseStateData struct {
+	Dex                string    `json:"dex"`
+	User               string    `json:"user"`
+	ClearinghouseState UserState `json:"clearinghouseState"`
+}
+
+// OpenOrdersData represents an openOrders message
+type OpenOrdersData struct {
+	Dex    string      `json:"dex"`
+	User   string      `json:"user"`
+	Orders []OpenOrder `json:"orders"`
+}
+
+// TwapState represents the state of a TWAP order
+type TwapState struct {
+	Coin        string          `json:"coin"`
+	User        string          `json:"user"`
+	Side        string          `json:"side"`
+	Sz          decimal.Decimal `json:"sz"`
+	ExecutedSz  decimal.Decimal `json:"executedSz"`
+	ExecutedNtl decimal.Decimal `json:"executedNtl"`
+	Minutes     int             `json:"minutes"`
+	ReduceOnly  bool            `json:"reduceOnly"`
+	Randomize   bool            `json:"randomize"`
+	Timestamp   int64           `json:"timestamp"`
+}
+
+// TwapStateEntry pairs a running TWAP with its ID, encoded as [id, state]
+type TwapStateEntry struct {
+	TwapId int64
+	State  TwapState
+}
+
+// UnmarshalJSON decodes the [id, state] tuple
+func (t *TwapStateEntry) UnmarshalJSON(data []byte) error {
+	var tuple []json.RawMessage
+	if err := json.Unmarshal(data, &tuple); err != nil {
+		return err
+	}
+	if len(tuple) != 2 {
+		return fmt.Errorf("expected [id, state] tuple, got %d elements", len(tuple))
+	}
+	if err := json.Unmarshal(tuple[0], &t.TwapId); err != nil {
+		return fmt.Errorf("failed to decode TWAP id: %w", err)
+	}
+	return json.Unmarshal(tuple[1], &t.State)
+}
+
+// MarshalJSON encodes the entry as an [id, state] tuple
+func (t TwapStateEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{t.TwapId, t.State})
+}
+
+// TwapStatus represents the lifecycle status of a TWAP order
+type TwapStatus struct {
+	Status      string `json:"status"` // "activated", "finished", "terminated" or "error"
+	Description string `json:"description,omitempty"`
+}
+
+// TwapHistoryEntry represents a TWAP state change
+type TwapHistoryEntry struct {
+	State  TwapState  `json:"state"`
+	Status TwapStatus `json:"status"`
+	Time   int64      `json:"time"`
+}
+
+// TwapSliceFill represents a fill produced by a TWAP slice
+type TwapSliceFill struct {
+	Fill   UserFillData `json:"fill"`
+	TwapId int64        `json:"twapId"`
+}
+
+// UserTwapSliceFillsData represents a userTwapSliceFills message
+type UserTwapSliceFillsData struct {
+	IsSnapshot     bool            `json:"isSnapshot"`
+	User           string          `json:"user"`
+	TwapSliceFills []TwapSliceFill `json:"twapSliceFills"`
+}
+
+// UserTwapHistoryData represents a userTwapHistory message
+type UserTwapHistoryData struct {
+	IsSnapshot bool               `json:"isSnapshot"`
+	User       string             `json:"user"`
+	History    []TwapHistoryEntry `json:"history"`
+}
+
+// Ledger delta types reported by userNonFundingLedgerUpdates
+const (
+	LedgerDeposit               = "deposit"
+	LedgerWithdraw              = "withdraw"
+	LedgerInternalTransfer      = "internalTransfer"
+	LedgerSubAccountTransfer    = "subAccountTransfer"
+	LedgerLiquidation           = "liquidation"
+	LedgerVaultCreate           = "vaultCreate"
+	LedgerVaultDeposit          = "vaultDeposit"
+	LedgerVaultDistribution     = "vaultDistribution"
+	LedgerVaultWithdraw         = "vaultWithdraw"
+	LedgerVaultLeaderCommission = "vaultLeaderCommission"
+	LedgerSpotTransfer          = "spotTransfer"
+	LedgerAccountClassTransfer  = "accountClassTransfer"
+	LedgerSpotGenesis           = "spotGenesis"
+	LedgerRewardsClaim          = "rewardsClaim"
+)
+
+// LedgerDelta represents a non-funding change to a user's balances. Only
+// the fields relevant to Type are populated.
+type LedgerDelta struct {
+	Type            string          `json:"type"`
+	Usdc            decimal.Decimal `json:"usdc"`
+	Fee             decimal.Decimal `json:"fee"`
+	Nonce           int64           `json:"nonce,omitempty"`
+	User            string          `json:"user,omitempty"`
+	Destination     string          `json:"destination,omitempty"`
+	Vault           string          `json:"vault,omitempty"`
+	Token           string          `json:"token,omitempty"`
+	Amount          decimal.Decimal `json:"amount"`
+	UsdcValue       decimal.Decimal `json:"usdcValue"`
+	ToPerp          bool            `json:"toPerp,omitempty"`
+	AccountValue    decimal.Decimal `json:"accountValue"`
+	LeverageType    string          `json:"leverageType,omitempty"`
+	RequestedUsd    decimal.Decimal `json:"requestedUsd"`
+	Commission      decimal.Decimal `json:"commission"`
+	ClosingCost     decimal.Decimal `json:"closingCost"`
+	Basis           decimal.Decimal `json:"basis"`
+	NetWithdrawnUsd decimal.Decimal `json:"netWithdrawnUsd"`
+}
+
+// LedgerUpdate represents a single ledger entry
+type LedgerUpdate struct {
+	Time  int64       `json:"time"`
+	Hash  string      `json:"hash"`
+	Delta LedgerDelta `json:"delta"`
+}
+
+// UserNonFundingLedgerUpdatesData represents a userNonFundingLedgerUpdates message
+type UserNonFundingLedgerUpdatesData struct {
+	IsSnapshot              bool           `json:"isSnapshot"`
+	User                    string         `json:"user"`
+	NonFundingLedgerUpdates []LedgerUpdate `json:"nonFundingLedgerUpdates"`
+}
+
 // ActiveAssetDataData represents active asset data
 type ActiveAssetDataData struct {
 	User string          `json:"user"`
@@ -441,6 +970,41 @@ type OrderBookLevel struct {
 	NumOrders int            `json:"n"`
 }
 
+// ParseL2Levels converts the raw [bids, asks] levels of an l2Book payload
+// into typed order book levels
+func ParseL2Levels(levels [][]interface{}) (bids, asks []OrderBookLevel, err error) {
+	if len(levels) != 2 {
+		return nil, nil, fmt.Errorf("expected 2 book sides, got %d", len(levels))
+	}
+
+	raw, err := json.Marshal(levels)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal levels: %w", err)
+	}
+
+	var sides [2][]OrderBookLevel
+	if err := json.Unmarshal(raw, &sides); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal levels: %w", err)
+	}
+
+	return sides[0], sides[1], nil
+}
+
+// OrderBook converts the snapshot into a typed OrderBook
+func (d L2BookData) OrderBook() (*OrderBook, error) {
+	bids, asks, err := ParseL2Levels(d.Levels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderBook{Coin: d.Coin, Time: d.Time, Bids: bids, Asks: asks}, nil
+}
+
+// OrderBook converts the snapshot into a typed OrderBook
+func (b L2Book) OrderBook() (*OrderBook, error) {
+	return L2BookData(b).OrderBook()
+}
+
 // WSStats represents WebSocket connection statistics
 type WSStats struct {
 	Connected       bool          `json:"connected"`
@@ -451,4 +1015,63 @@ type WSStats struct {
 	Uptime          time.Duration `json:"uptime"`
 	LastPing        time.Time     `json:"lastPing"`
 	LastPong        time.Time     `json:"lastPong"`
-}
\ No newline at end of file
+}
+// Staking Types
+
+// HypeWeiDecimals is the number of decimals in a HYPE staking wei amount
+const HypeWeiDecimals = 8
+
+// DelegatorSummary represents a user's staking totals
+type DelegatorSummary struct {
+	Delegated              decimal.Decimal `json:"delegated"`
+	Undelegated            decimal.Decimal `json:"undelegated"`
+	TotalPendingWithdrawal decimal.Decimal `json:"totalPendingWithdrawal"`
+	NPendingWithdrawals    int             `json:"nPendingWithdrawals"`
+}
+
+// Delegation represents stake delegated to one validator
+type Delegation struct {
+	Validator            string          `json:"validator"`
+	Amount               decimal.Decimal `json:"amount"`
+	LockedUntilTimestamp int64           `json:"lockedUntilTimestamp"`
+}
+
+// DelegatorReward represents a staking reward accrual
+type DelegatorReward struct {
+	Time        int64           `json:"time"`
+	Source      string          `json:"source"` // "delegation" or "commission"
+	TotalAmount decimal.Decimal `json:"totalAmount"`
+}
+
+// DelegatorHistoryEntry represents a staking event
+type DelegatorHistoryEntry struct {
+	Time  int64          `json:"time"`
+	Hash  string         `json:"hash"`
+	Delta DelegatorDelta `json:"delta"`
+}
+
+// DelegatorDelta is the change recorded by a staking event. Exactly one
+// field is set.
+type DelegatorDelta struct {
+	Delegate   *DelegateDelta     `json:"delegate,omitempty"`
+	CDeposit   *StakingAmount     `json:"cDeposit,omitempty"`
+	Withdrawal *StakingWithdrawal `json:"withdrawal,omitempty"`
+}
+
+// DelegateDelta represents a delegation or undelegation
+type DelegateDelta struct {
+	Validator    string          `json:"validator"`
+	Amount       decimal.Decimal `json:"amount"`
+	IsUndelegate bool            `json:"isUndelegate"`
+}
+
+// StakingAmount represents a transfer into the staking balance
+type StakingAmount struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// StakingWithdrawal represents a withdrawal from the staking balance
+type StakingWithdrawal struct {
+	Amount decimal.Decimal `json:"amount"`
+	Phase  string          `json:"phase"` // "initiated" or "finalized"
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestUserEventUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		wantType UserEventType
+	}{
+		{
+			name:     "fills",
+			payload:  `{"fills":[{"coin":"ETH","px":"2000.5","sz":"0.1","side":"B","time":1,"oid":7,"tid":9,"hash":"0xabc"}]}`,
+			wantType: UserEventFills,
+		},
+		{
+			name:     "funding",
+			payload:  `{"funding":{"time":1,"coin":"BTC","usdc":"-0.5","szi":"0.01","fundingRate":"0.0001"}}`,
+			wantType: UserEventFunding,
+		},
+		{
+			name:     "liquidation",
+			payload:  `{"liquidation":{"lid":3,"liquidator":"0x1","liquidated_user":"0x2","liquidated_ntl_pos":"100","liquidated_account_value":"5"}}`,
+			wantType: UserEventLiquidation,
+		},
+		{
+			name:     "non-user cancel",
+			payload:  `{"nonUserCancel":[{"coin":"SOL","oid":42}]}`,
+			wantType: UserEventNonUserCancel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event UserEvent
+			if err := json.Unmarshal([]byte(tt.payload), &event); err != nil {
+				t.Fatalf("Failed to unmarshal: %v", err)
+			}
+
+			if event.Type != tt.wantType {
+				t.Errorf("Expected type %s, got %s", tt.wantType, event.Type)
+			}
+
+			// Round trip through the wire format
+			encoded, err := json.Marshal(event)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			var decoded UserEvent
+			if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.Type != tt.wantType {
+				t.Errorf("Round trip failed: type=%s err=%v", decoded.Type, err)
+			}
+		})
+	}
+
+	var event UserEvent
+	if err := json.Unmarshal([]byte(`{"unknown":{}}`), &event); err == nil {
+		t.Error("Expected error for unknown event")
+	}
+}
+
+func TestTwapStateEntryUnmarshal(t *testing.T) {
+	payload := `[[17,{"coin":"ETH","user":"0x1","side":"B","sz":"10","executedSz":"2","executedNtl":"4000","minutes":30,"reduceOnly":false,"randomize":true,"timestamp":5}]]`
+
+	var entries []TwapStateEntry
+	if err := json.Unmarshal([]byte(payload), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].TwapId != 17 || entries[0].State.Minutes != 30 {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestL2BookDataOrderBook(t *testing.T) {
+	payload := `{"coin":"BTC","time":10,"levels":[[{"px":"100","sz":"1","n":2}],[{"px":"101","sz":"3","n":1},{"px":"102","sz":"4","n":5}]]}`
+
+	var data L2BookData
+	if err := json.Unmarshal([]byte(payload), &data); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	book, err := data.OrderBook()
+	if err != nil {
+		t.Fatalf("Failed to convert book: %v", err)
+	}
+
+	if len(book.Bids) != 1 || len(book.Asks) != 2 || book.Asks[1].NumOrders != 5 {
+		t.Errorf("Unexpected book: %+v", book)
+	}
+
+	if _, err := (L2BookData{Levels: [][]interface{}{{}}}).OrderBook(); err == nil {
+		t.Error("Expected error for single-sided levels")
+	}
+}
+
+func TestTriggerOrderTypeValidate(t *testing.T) {
+	tests := []struct {
+		trigger TriggerOrderType
+		valid   bool
+	}{
+		{TriggerOrderType{TriggerPx: decimal.NewFromInt(100), TpSl: TpSlTakeProfit}, true},
+		{TriggerOrderType{TriggerPx: decimal.NewFromInt(100), TpSl: TpSlStopLoss, IsMarket: true}, true},
+		{TriggerOrderType{TriggerPx: decimal.NewFromInt(100), TpSl: "stop"}, false},
+		{TriggerOrderType{TriggerPx: decimal.Zero, TpSl: TpSlStopLoss}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.trigger.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) error = %v, want valid %t", tt.trigger, err, tt.valid)
+		}
+	}
+}
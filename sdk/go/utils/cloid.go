@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// NewCloid generates a random 128-bit client order ID as 0x-prefixed hex
+func NewCloid() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate cloid: %w", err)
+	}
+	return "0x" + hex.EncodeToString(b[:]), nil
+}
+
+// ValidateCloid checks that cloid is a 0x-prefixed 128-bit hex string
+func ValidateCloid(cloid string) error {
+	if !strings.HasPrefix(cloid, "0x") || len(cloid) != 34 {
+		return fmt.Errorf("cloid must be 0x followed by 32 hex characters, got %q", cloid)
+	}
+	if _, err := hex.DecodeString(cloid[2:]); err != nil {
+		return fmt.Errorf("cloid is not valid hex: %q", cloid)
+	}
+	return nil
+}
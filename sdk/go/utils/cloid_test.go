@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestNewCloid(t *testing.T) {
+	a, err := NewCloid()
+	if err != nil {
+		t.Fatalf("NewCloid failed: %v", err)
+	}
+	b, _ := NewCloid()
+
+	if err := ValidateCloid(a); err != nil {
+		t.Errorf("Generated cloid is invalid: %v", err)
+	}
+	if a == b {
+		t.Error("Expected distinct cloids")
+	}
+}
+
+func TestValidateCloid(t *testing.T) {
+	tests := []struct {
+		cloid string
+		valid bool
+	}{
+		{"0x0123456789abcdef0123456789abcdef", true},
+		{"0123456789abcdef0123456789abcdef", false},
+		{"0x0123456789abcdef", false},
+		{"0x0123456789abcdef0123456789abcdeg", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateCloid(tt.cloid)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateCloid(%s) error = %v, want valid %t", tt.cloid, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "fmt"
+
+// ValidateL2Aggregation checks l2Book aggregation parameters. nSigFigs must
+// be between 2 and 5, and mantissa (1, 2 or 5) is only allowed with 5.
+func ValidateL2Aggregation(nSigFigs, mantissa *int) error {
+	if nSigFigs == nil {
+		if mantissa != nil {
+			return fmt.Errorf("mantissa requires nSigFigs")
+		}
+		return nil
+	}
+
+	if *nSigFigs < 2 || *nSigFigs > 5 {
+		return fmt.Errorf("nSigFigs must be between 2 and 5, got %d", *nSigFigs)
+	}
+
+	if mantissa != nil {
+		if *nSigFigs != 5 {
+			return fmt.Errorf("mantissa is only allowed with nSigFigs 5")
+		}
+		if *mantissa != 1 && *mantissa != 2 && *mantissa != 5 {
+			return fmt.Errorf("mantissa must be 1, 2 or 5, got %d", *mantissa)
+		}
+	}
+
+	return nil
+}
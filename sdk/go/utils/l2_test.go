@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestValidateL2Aggregation(t *testing.T) {
+	n := func(v int) *int { return &v }
+
+	tests := []struct {
+		name     string
+		nSigFigs *int
+		mantissa *int
+		wantErr  bool
+	}{
+		{"full precision", nil, nil, false},
+		{"two sig figs", n(2), nil, false},
+		{"five with mantissa", n(5), n(2), false},
+		{"too few sig figs", n(1), nil, true},
+		{"too many sig figs", n(6), nil, true},
+		{"mantissa without sig figs", nil, n(2), true},
+		{"mantissa below five", n(4), n(2), true},
+		{"bad mantissa", n(5), n(3), true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateL2Aggregation(tt.nSigFigs, tt.mantissa)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// MaxPriceSigFigs is the number of significant figures allowed in a
+	// non-integer price
+	MaxPriceSigFigs = 5
+
+	// MaxPerpPriceDecimals and MaxSpotPriceDecimals bound price decimals
+	// before subtracting the asset's szDecimals
+	MaxPerpPriceDecimals = 6
+	MaxSpotPriceDecimals = 8
+)
+
+// RoundPrice rounds px to the exchange tick rules: at most 5 significant
+// figures and at most (6 or 8 for spot) - szDecimals decimals. Prices with
+// five or more integer digits round to an integer, which is always valid.
+func RoundPrice(px decimal.Decimal, szDecimals int, isSpot bool) decimal.Decimal {
+	if px.IsZero() {
+		return px
+	}
+
+	maxDecimals := MaxPerpPriceDecimals - szDecimals
+	if isSpot {
+		maxDecimals = MaxSpotPriceDecimals - szDecimals
+	}
+
+	// Digits before the decimal point, negative for leading fractional zeros
+	intDigits := int(px.NumDigits()) + int(px.Exponent())
+
+	places := MaxPriceSigFigs - intDigits
+	if places > maxDecimals {
+		places = maxDecimals
+	}
+	if places < 0 {
+		places = 0
+	}
+
+	return px.Round(int32(places))
+}
+
+// RoundSize rounds sz to the asset's szDecimals
+func RoundSize(sz decimal.Decimal, szDecimals int) decimal.Decimal {
+	return sz.Round(int32(szDecimals))
+}
+
+// ToWei converts a token amount to its integer wei representation with the
+// given number of decimals, rejecting amounts that are not exact
+func ToWei(amount decimal.Decimal, decimals int) (uint64, error) {
+	if !amount.IsPositive() {
+		return 0, fmt.Errorf("amount must be positive")
+	}
+
+	wei := amount.Shift(int32(decimals))
+	if !wei.IsInteger() {
+		return 0, fmt.Errorf("amount %s has more than %d decimals", amount, decimals)
+	}
+	if wei.Cmp(decimal.NewFromUint64(^uint64(0))) > 0 {
+		return 0, fmt.Errorf("amount %s is too large", amount)
+	}
+
+	return wei.BigInt().Uint64(), nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		px         string
+		szDecimals int
+		isSpot     bool
+		expected   string
+	}{
+		{"12345.67", 5, false, "12346"},
+		{"123456.7", 5, false, "123457"},
+		{"1234.567", 4, false, "1234.6"},
+		{"3.456789", 2, false, "3.4568"},
+		{"3.456789", 4, false, "3.46"},
+		{"0.0012345678", 0, false, "0.001235"},
+		{"0.0012345678", 0, true, "0.0012346"},
+		{"0.0012345678", 2, true, "0.001235"},
+		{"0", 3, false, "0"},
+	}
+
+	for _, tt := range tests {
+		result := RoundPrice(decimal.RequireFromString(tt.px), tt.szDecimals, tt.isSpot)
+		if !result.Equal(decimal.RequireFromString(tt.expected)) {
+			t.Errorf("RoundPrice(%s, %d, %t) = %s, want %s", tt.px, tt.szDecimals, tt.isSpot, result, tt.expected)
+		}
+	}
+}
+
+func TestRoundSize(t *testing.T) {
+	result := RoundSize(decimal.RequireFromString("0.123456"), 4)
+	if !result.Equal(decimal.RequireFromString("0.1235")) {
+		t.Errorf("Expected 0.1235, got %s", result)
+	}
+}
+
+func TestToWei(t *testing.T) {
+	tests := []struct {
+		amount   string
+		expected uint64
+		valid    bool
+	}{
+		{"1", 100000000, true},
+		{"12060.16529862", 1206016529862, true},
+		{"0.000000001", 0, false},
+		{"0", 0, false},
+		{"-1", 0, false},
+	}
+
+	for _, tt := range tests {
+		wei, err := ToWei(decimal.RequireFromString(tt.amount), 8)
+		if (err == nil) != tt.valid || wei != tt.expected {
+			t.Errorf("ToWei(%s) = %d, %v, want %d (valid %t)", tt.amount, wei, err, tt.expected, tt.valid)
+		}
+	}
+}
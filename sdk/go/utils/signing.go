@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
@@ -107,33 +108,6 @@ func createTypedData(action interface{}, nonce int64) (*apitypes.TypedData, erro
 	primaryType := ""
 
 	switch actionType {
-	case "order":
-		types["Order"] = []apitypes.Type{
-			{Name: "orders", Type: "OrderRequest[]"},
-			{Name: "grouping", Type: "string"},
-		}
-		types["OrderRequest"] = []apitypes.Type{
-			{Name: "coin", Type: "string"},
-			{Name: "is_buy", Type: "bool"},
-			{Name: "limit_px", Type: "string"},
-			{Name: "sz", Type: "string"},
-			{Name: "reduce_only", Type: "bool"},
-			{Name: "order_type", Type: "string"},
-		}
-		primaryType = "Order"
-		message = actionMap
-
-	case "cancel":
-		types["Cancel"] = []apitypes.Type{
-			{Name: "cancels", Type: "CancelRequest[]"},
-		}
-		types["CancelRequest"] = []apitypes.Type{
-			{Name: "coin", Type: "string"},
-			{Name: "oid", Type: "uint64"},
-		}
-		primaryType = "Cancel"
-		message = actionMap
-
 	case "usdSend", "withdraw3":
 		types["Transfer"] = []apitypes.Type{
 			{Name: "destination", Type: "address"},
@@ -147,6 +121,71 @@ func createTypedData(action interface{}, nonce int64) (*apitypes.TypedData, erro
 			"time":        actionMap["time"],
 		}
 
+	case "approveBuilderFee":
+		if domain, err = userSignedDomain(actionMap); err != nil {
+			return nil, err
+		}
+		primaryType = "HyperliquidTransaction:ApproveBuilderFee"
+		types[primaryType] = []apitypes.Type{
+			{Name: "hyperliquidChain", Type: "string"},
+			{Name: "maxFeeRate", Type: "string"},
+			{Name: "builder", Type: "address"},
+			{Name: "nonce", Type: "uint64"},
+		}
+		message = map[string]interface{}{
+			"hyperliquidChain": actionMap["hyperliquidChain"],
+			"maxFeeRate":       actionMap["maxFeeRate"],
+			"builder":          actionMap["builder"],
+			"nonce":            big.NewInt(nonce),
+		}
+
+	case "tokenDelegate":
+		wei, err := bigField(actionJSON, "wei")
+		if err != nil {
+			return nil, err
+		}
+		if domain, err = userSignedDomain(actionMap); err != nil {
+			return nil, err
+		}
+		primaryType = "HyperliquidTransaction:TokenDelegate"
+		types[primaryType] = []apitypes.Type{
+			{Name: "hyperliquidChain", Type: "string"},
+			{Name: "validator", Type: "address"},
+			{Name: "wei", Type: "uint64"},
+			{Name: "isUndelegate", Type: "bool"},
+			{Name: "nonce", Type: "uint64"},
+		}
+		message = map[string]interface{}{
+			"hyperliquidChain": actionMap["hyperliquidChain"],
+			"validator":        actionMap["validator"],
+			"wei":              wei,
+			"isUndelegate":     actionMap["isUndelegate"],
+			"nonce":            big.NewInt(nonce),
+		}
+
+	case "cDeposit", "cWithdraw":
+		wei, err := bigField(actionJSON, "wei")
+		if err != nil {
+			return nil, err
+		}
+		if domain, err = userSignedDomain(actionMap); err != nil {
+			return nil, err
+		}
+		primaryType = "HyperliquidTransaction:CDeposit"
+		if actionType == "cWithdraw" {
+			primaryType = "HyperliquidTransaction:CWithdraw"
+		}
+		types[primaryType] = []apitypes.Type{
+			{Name: "hyperliquidChain", Type: "string"},
+			{Name: "wei", Type: "uint64"},
+			{Name: "nonce", Type: "uint64"},
+		}
+		message = map[string]interface{}{
+			"hyperliquidChain": actionMap["hyperliquidChain"],
+			"wei":              wei,
+			"nonce":            big.NewInt(nonce),
+		}
+
 	default:
 		// For other action types, create a generic structure
 		types["Action"] = []apitypes.Type{
@@ -156,7 +195,7 @@ func createTypedData(action interface{}, nonce int64) (*apitypes.TypedData, erro
 		primaryType = "Action"
 		message = map[string]interface{}{
 			"action": normalizeAction(actionMap),
-			"nonce":  nonce,
+			"nonce":  big.NewInt(nonce),
 		}
 	}
 
@@ -170,22 +209,53 @@ func createTypedData(action interface{}, nonce int64) (*apitypes.TypedData, erro
 	return typedData, nil
 }
 
-// signTypedData signs EIP-712 typed data
-func signTypedData(privateKey *ecdsa.PrivateKey, typedData *apitypes.TypedData) (string, error) {
-	// Get the hash to sign
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
-	if err != nil {
-		return "", fmt.Errorf("failed to hash domain: %w", err)
+// userSignedDomain returns the EIP-712 domain of a user-signed action. The
+// exchange builds it from the action's signatureChainId, the chain of the
+// signing wallet, so that field must be set before signing.
+func userSignedDomain(actionMap map[string]interface{}) (apitypes.TypedDataDomain, error) {
+	raw, _ := actionMap["signatureChainId"].(string)
+	chainId, ok := new(big.Int).SetString(removeHexPrefix(raw), 16)
+	if !ok {
+		return apitypes.TypedDataDomain{}, fmt.Errorf("invalid signatureChainId %q", raw)
 	}
 
-	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash message: %w", err)
+	return apitypes.TypedDataDomain{
+		Name:              "HyperliquidSignTransaction",
+		Version:           "1",
+		ChainId:           (*math.HexOrDecimal256)(chainId),
+		VerifyingContract: "0x0000000000000000000000000000000000000000",
+	}, nil
+}
+
+// bigField reads an integer field from an encoded action without the
+// precision loss of decoding it as float64
+func bigField(actionJSON []byte, key string) (*big.Int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(actionJSON))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to decode action: %w", err)
 	}
 
-	// Create the final hash
-	rawData := []byte(fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(typedDataHash)))
-	hash := crypto.Keccak256(rawData)
+	number, ok := fields[key].(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("action field %s is not a number", key)
+	}
+
+	value, ok := new(big.Int).SetString(number.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("action field %s is not an integer: %s", key, number)
+	}
+	return value, nil
+}
+
+// signTypedData signs EIP-712 typed data
+func signTypedData(privateKey *ecdsa.PrivateKey, typedData *apitypes.TypedData) (string, error) {
+	hash, err := typedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
 
 	// Sign the hash
 	sig, err := crypto.Sign(hash, privateKey)
@@ -199,6 +269,57 @@ func signTypedData(privateKey *ecdsa.PrivateKey, typedData *apitypes.TypedData)
 	return hexutil.Encode(sig), nil
 }
 
+// typedDataHash returns the EIP-712 digest of typed data
+func typedDataHash(typedData *apitypes.TypedData) ([]byte, error) {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash domain: %w", err)
+	}
+
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash message: %w", err)
+	}
+
+	rawData := []byte(fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(messageHash)))
+	return crypto.Keccak256(rawData), nil
+}
+
+// RecoverSigner returns the address that produced signature over action and
+// nonce, as created by SignAction
+func RecoverSigner(action interface{}, signature string, nonce int64) (string, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if len(sig) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes, got %d", len(sig))
+	}
+
+	typedData, err := createTypedData(action, nonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to create typed data: %w", err)
+	}
+
+	hash, err := typedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+
+	// Undo the Ethereum V adjustment applied when signing
+	sig = append([]byte(nil), sig...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover public key: %w", err)
+	}
+
+	return crypto.PubkeyToAddress(*publicKey).Hex(), nil
+}
+
 // normalizeAction converts action map to deterministic string representation
 func normalizeAction(action map[string]interface{}) string {
 	// Sort keys for deterministic ordering
@@ -273,4 +394,4 @@ func HexToBytes(hexStr string) ([]byte, error) {
 // BytesToHex converts bytes to hex string with 0x prefix
 func BytesToHex(b []byte) string {
 	return hexutil.Encode(b)
-}
\ No newline at end of file
+}
@@ -0,0 +1,364 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// FillSource backfills fills missed while disconnected. *client.InfoClient
+// satisfies it.
+type FillSource interface {
+	GetUserFills(ctx context.Context, user string, startTime, endTime *int64) ([]types.Fill, error)
+}
+
+// FillOrigin describes how a fill reached a FillStream
+type FillOrigin int
+
+const (
+	// FillLive is an incremental fill received while connected
+	FillLive FillOrigin = iota
+	// FillSnapshot is a fill first seen in a subscription snapshot
+	FillSnapshot
+	// FillBackfill is a fill recovered over REST after a reconnect
+	FillBackfill
+)
+
+func (o FillOrigin) String() string {
+	switch o {
+	case FillSnapshot:
+		return "snapshot"
+	case FillBackfill:
+		return "backfill"
+	default:
+		return "live"
+	}
+}
+
+// FillEvent is a deduplicated fill delivered by a FillStream
+type FillEvent struct {
+	Fill   types.UserFillData
+	Origin FillOrigin
+}
+
+// FillStreamConfig configures a FillStream
+type FillStreamConfig struct {
+	CacheSize       int           // Fills remembered for deduplication (default 10000)
+	Backfill        FillSource    // Optional REST source used to fill reconnect gaps
+	BackfillTimeout time.Duration // Timeout for a backfill request (default 10s)
+	DeliverSnapshot bool          // Deliver the initial snapshot rather than only recording it
+}
+
+// FillStream delivers a user's fills exactly once across reconnects. The
+// initial snapshot seeds the dedupe cache, and after each reconnect the
+// snapshot is merged with a REST backfill from the last seen fill so that
+// fills missed while disconnected are delivered in time order before any
+// newer increments. The backfill runs off the connection's delivery path;
+// increments arriving meanwhile are held until it is merged.
+type FillStream struct {
+	manager Subscriber
+	user    string
+	config  FillStreamConfig
+	handler func(event FillEvent) error
+
+	delivering  sync.Mutex // Serializes delivery so fills reach the handler in order
+	mu          sync.Mutex
+	seen        *DedupeCache
+	lastTime    int64
+	epoch       uint64
+	subID       string
+	backfilling bool
+	held        []FillEvent // Fills received while a backfill runs
+	onError     func(error)
+}
+
+// NewFillStream creates a fill stream for user on a Manager or Pool. The
+// handler is called one fill at a time, outside the stream's lock, so it may
+// call LastFillTime or Stop.
+func NewFillStream(manager Subscriber, user string, config FillStreamConfig, handler func(event FillEvent) error) *FillStream {
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+	if config.BackfillTimeout <= 0 {
+		config.BackfillTimeout = 10 * time.Second
+	}
+
+	return &FillStream{
+		manager: manager,
+		user:    user,
+		config:  config,
+		handler: handler,
+		seen:    NewDedupeCache(config.CacheSize),
+	}
+}
+
+// Start subscribes to the user's fills
+func (s *FillStream) Start() (string, error) {
+	sub := types.WSSubscription{
+		Type: "userFills",
+		User: s.user,
+	}
+
+	subID, err := s.manager.SubscribeWithInfo(sub, s.handle)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.subID = subID
+	s.mu.Unlock()
+
+	return subID, nil
+}
+
+// Stop unsubscribes from the user's fills
+func (s *FillStream) Stop() error {
+	s.mu.Lock()
+	subID := s.subID
+	s.subID = ""
+	s.mu.Unlock()
+
+	if subID == "" {
+		return nil
+	}
+	return s.manager.Unsubscribe(subID)
+}
+
+// OnError sets a callback for failures outside the message handler, such as
+// a failed backfill or a handler error while merging one. Without it they are
+// logged.
+func (s *FillStream) OnError(handler func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onError = handler
+}
+
+// LastFillTime returns the time (ms) of the newest fill seen
+func (s *FillStream) LastFillTime() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastTime
+}
+
+func (s *FillStream) handle(raw json.RawMessage, info MessageInfo) error {
+	var data types.UserFillsData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	s.delivering.Lock()
+	defer s.delivering.Unlock()
+
+	s.mu.Lock()
+	events := s.accept(data, info)
+	s.mu.Unlock()
+
+	return s.deliver(events)
+}
+
+// accept returns the fills of a message that are ready for delivery.
+// Callers hold s.mu.
+func (s *FillStream) accept(data types.UserFillsData, info MessageInfo) []FillEvent {
+	first := s.epoch == 0
+	reconnected := !first && info.Epoch != s.epoch
+	s.epoch = info.Epoch
+
+	origin := FillLive
+	if data.IsSnapshot {
+		origin = FillSnapshot
+	}
+	events := make([]FillEvent, 0, len(data.Fills))
+	for _, fill := range data.Fills {
+		events = append(events, FillEvent{Fill: fill, Origin: origin})
+	}
+
+	if s.backfilling {
+		s.held = append(s.held, events...)
+		return nil
+	}
+
+	if !data.IsSnapshot && !reconnected {
+		return events
+	}
+
+	if first && !s.config.DeliverSnapshot {
+		for _, fill := range data.Fills {
+			s.record(fill)
+		}
+		return nil
+	}
+
+	for i := range events {
+		events[i].Origin = FillSnapshot
+	}
+	if !reconnected || s.config.Backfill == nil || s.lastTime == 0 {
+		return events
+	}
+
+	// Hold the snapshot and what follows until everything since the last
+	// seen fill is fetched, so the gap is delivered in order
+	s.backfilling = true
+	s.held = events
+	go s.merge(s.lastTime)
+
+	return nil
+}
+
+// merge fetches the fills since the last seen one and delivers them with the
+// held fills; duplicates are dropped by the cache
+func (s *FillStream) merge(since int64) {
+	backfill, err := s.backfill(since)
+	if err != nil {
+		err = fmt.Errorf("failed to backfill fills for %s: %w", s.user, err)
+	}
+
+	s.delivering.Lock()
+	defer s.delivering.Unlock()
+
+	s.mu.Lock()
+	events := s.held
+	for _, fill := range backfill {
+		events = append(events, FillEvent{Fill: fill, Origin: FillBackfill})
+	}
+	s.held = nil
+	s.backfilling = false
+	onError := s.onError
+	s.mu.Unlock()
+
+	if deliverErr := s.deliver(events); deliverErr != nil {
+		err = errors.Join(err, deliverErr)
+	}
+
+	if err == nil {
+		return
+	}
+	if onError != nil {
+		onError(err)
+		return
+	}
+	log.Printf("Fill stream error for %s: %v", s.user, err)
+}
+
+func (s *FillStream) backfill(since int64) ([]types.UserFillData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.BackfillTimeout)
+	defer cancel()
+
+	fills, err := s.config.Backfill.GetUserFills(ctx, s.user, &since, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]types.UserFillData, 0, len(fills))
+	for _, fill := range fills {
+		converted = append(converted, userFillFromFill(s.user, fill))
+	}
+	return converted, nil
+}
+
+// deliver passes unseen fills to the handler in time order. Callers hold
+// s.delivering but not s.mu, so the handler may call back into the stream.
+func (s *FillStream) deliver(events []FillEvent) error {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Fill.Time != events[j].Fill.Time {
+			return events[i].Fill.Time < events[j].Fill.Time
+		}
+		return events[i].Fill.Tid < events[j].Fill.Tid
+	})
+
+	for _, event := range events {
+		s.mu.Lock()
+		fresh := s.record(event.Fill)
+		s.mu.Unlock()
+		if !fresh {
+			continue
+		}
+		if err := s.handler(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record marks a fill as seen, returning false if it already was. Callers
+// hold s.mu.
+func (s *FillStream) record(fill types.UserFillData) bool {
+	if fill.Time > s.lastTime {
+		s.lastTime = fill.Time
+	}
+	return s.seen.Add(FillKey(fill))
+}
+
+// FillKey identifies a fill for deduplication: its trade ID, or its hash,
+// order and time when the trade ID is missing
+func FillKey(fill types.UserFillData) string {
+	if fill.Tid != 0 {
+		return fmt.Sprintf("tid:%d", fill.Tid)
+	}
+	return fmt.Sprintf("hash:%s:%d:%d", fill.Hash, fill.Oid, fill.Time)
+}
+
+func userFillFromFill(user string, fill types.Fill) types.UserFillData {
+	return types.UserFillData{
+		User:          user,
+		Coin:          fill.Coin,
+		Px:            fill.Px,
+		Sz:            fill.Sz,
+		Side:          fill.Side,
+		Time:          fill.Time,
+		StartPosition: fill.StartPosition,
+		Dir:           fill.Dir,
+		ClosedPnl:     fill.ClosedPnl,
+		Hash:          fill.Hash,
+		Oid:           fill.Oid,
+		Crossed:       fill.Crossed,
+		Fee:           fill.Fee,
+		Tid:           fill.Tid,
+		FeeToken:      fill.FeeToken,
+		Cloid:         fill.Cloid,
+	}
+}
+
+// DedupeCache is a bounded set that forgets its oldest keys first. It is
+// not safe for concurrent use.
+type DedupeCache struct {
+	keys  map[string]struct{}
+	order []string
+	next  int
+}
+
+// NewDedupeCache creates a cache remembering up to capacity keys
+func NewDedupeCache(capacity int) *DedupeCache {
+	return &DedupeCache{
+		keys:  make(map[string]struct{}, capacity),
+		order: make([]string, capacity),
+	}
+}
+
+// Contains reports whether key is present
+func (c *DedupeCache) Contains(key string) bool {
+	_, ok := c.keys[key]
+	return ok
+}
+
+// Add inserts key, returning false if it was already present
+func (c *DedupeCache) Add(key string) bool {
+	if _, ok := c.keys[key]; ok {
+		return false
+	}
+
+	if evicted := c.order[c.next]; evicted != "" {
+		delete(c.keys, evicted)
+	}
+	c.order[c.next] = key
+	c.next = (c.next + 1) % len(c.order)
+	c.keys[key] = struct{}{}
+
+	return true
+}
@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+type fakeFillSource struct {
+	fills     []types.Fill
+	startTime int64
+	release   chan struct{} // Holds the request until closed, when set
+}
+
+func (f *fakeFillSource) GetUserFills(ctx context.Context, user string, startTime, endTime *int64) ([]types.Fill, error) {
+	if startTime != nil {
+		f.startTime = *startTime
+	}
+	if f.release != nil {
+		<-f.release
+	}
+	return f.fills, nil
+}
+
+func fillsMessage(t *testing.T, snapshot bool, tids ...int64) json.RawMessage {
+	data := types.UserFillsData{IsSnapshot: snapshot, User: "0xabc"}
+	for _, tid := range tids {
+		data.Fills = append(data.Fills, types.UserFillData{Coin: "ETH", Tid: tid, Time: tid * 1000})
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Failed to marshal fills: %v", err)
+	}
+	return raw
+}
+
+func TestFillStreamExactlyOnce(t *testing.T) {
+	source := &fakeFillSource{
+		fills:   []types.Fill{{Coin: "ETH", Tid: 3, Time: 3000}, {Coin: "ETH", Tid: 4, Time: 4000}},
+		release: make(chan struct{}),
+	}
+
+	var mu sync.Mutex
+	var got []FillEvent
+	stream := NewFillStream(NewManager("ws://unused"), "0xabc", FillStreamConfig{Backfill: source}, func(event FillEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, event)
+		return nil
+	})
+	stream.OnError(func(err error) { t.Errorf("Unexpected stream error: %v", err) })
+
+	steps := []struct {
+		raw  json.RawMessage
+		info MessageInfo
+	}{
+		// Initial snapshot only seeds the cache
+		{fillsMessage(t, true, 1, 2), MessageInfo{Epoch: 1, IsSnapshot: true, Initial: true}},
+		// Live increment, then a duplicate of it
+		{fillsMessage(t, false, 3), MessageInfo{Epoch: 1}},
+		{fillsMessage(t, false, 3), MessageInfo{Epoch: 1}},
+		// Reconnect snapshot overlaps what was seen; backfill recovers tid 4
+		{fillsMessage(t, true, 2, 3, 5), MessageInfo{Epoch: 2, IsSnapshot: true, Initial: true}},
+		// Held without blocking while the backfill is outstanding
+		{fillsMessage(t, false, 5, 6), MessageInfo{Epoch: 2}},
+	}
+
+	for i, step := range steps {
+		if err := stream.handle(step.raw, step.info); err != nil {
+			t.Fatalf("Step %d failed: %v", i, err)
+		}
+	}
+
+	mu.Lock()
+	if len(got) != 1 {
+		t.Errorf("Expected only the first live fill before the backfill returns, got %+v", got)
+	}
+	mu.Unlock()
+	close(source.release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		if len(got) >= 4 {
+			break
+		}
+		mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the backfill to merge")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer mu.Unlock()
+
+	wantTids := []int64{3, 4, 5, 6}
+	wantOrigins := []FillOrigin{FillLive, FillBackfill, FillSnapshot, FillLive}
+	if len(got) != len(wantTids) {
+		t.Fatalf("Expected %d fills, got %d: %+v", len(wantTids), len(got), got)
+	}
+	for i := range got {
+		if got[i].Fill.Tid != wantTids[i] || got[i].Origin != wantOrigins[i] {
+			t.Errorf("Fill %d: expected tid %d (%s), got tid %d (%s)", i, wantTids[i], wantOrigins[i], got[i].Fill.Tid, got[i].Origin)
+		}
+	}
+
+	if source.startTime != 3000 {
+		t.Errorf("Expected backfill from 3000, got %d", source.startTime)
+	}
+	if got[1].Fill.User != "0xabc" {
+		t.Errorf("Expected backfilled fill to carry user, got %q", got[1].Fill.User)
+	}
+}
+
+func TestFillStreamHandlerCallsBack(t *testing.T) {
+	var stream *FillStream
+	var seen []int64
+	stream = NewFillStream(NewManager("ws://unused"), "0xabc", FillStreamConfig{}, func(event FillEvent) error {
+		seen = append(seen, stream.LastFillTime())
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- stream.handle(fillsMessage(t, false, 1, 2), MessageInfo{Epoch: 1})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Failed to handle fills: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out: handler deadlocked calling LastFillTime")
+	}
+	if len(seen) != 2 || seen[0] != 1000 || seen[1] != 2000 {
+		t.Errorf("Expected last fill times [1000 2000], got %v", seen)
+	}
+}
+
+func TestDedupeCacheEviction(t *testing.T) {
+	cache := NewDedupeCache(2)
+
+	if !cache.Add("a") || !cache.Add("b") {
+		t.Fatal("Expected new keys to be added")
+	}
+	if cache.Add("a") {
+		t.Error("Expected duplicate key to be rejected")
+	}
+	if !cache.Contains("b") || cache.Contains("c") {
+		t.Error("Expected Contains to report only added keys")
+	}
+
+	// Adding a third key evicts the oldest
+	cache.Add("c")
+	if !cache.Add("a") {
+		t.Error("Expected evicted key to be accepted again")
+	}
+}
@@ -5,19 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
 )
 
+// Subscriber is implemented by Manager and Pool
+type Subscriber interface {
+	Subscribe(sub types.WSSubscription, handler MessageHandler) (string, error)
+	SubscribeWithInfo(sub types.WSSubscription, handler InfoHandler) (string, error)
+	Unsubscribe(subID string) error
+}
+
 type Manager struct {
 	url            string
 	conn           *websocket.Conn
 	mu             sync.RWMutex
 	subscriptions  map[string]*Subscription
-	handlers       map[string]MessageHandler
+	refs           map[string]int // Handlers per server subscription
+	handles        uint64
 	reconnectDelay time.Duration
 	maxReconnect   int
 	pingInterval   time.Duration
@@ -26,22 +36,49 @@ type Manager struct {
 	isConnected    bool
 	reconnectCount int
 	messageQueue   chan []byte
+	epoch          uint64
+	connDone       chan struct{}
+	processing     bool
+	onReconnect    []func(epoch uint64)
+	connectedAt    time.Time
+	lastPing       time.Time
+	reconnects     int64
+	received       atomic.Int64
+	sent           atomic.Int64
+	taps           []FrameTap
 }
 
 type MessageHandler func(data json.RawMessage) error
 
+// InfoHandler receives a message together with its delivery metadata
+type InfoHandler func(data json.RawMessage, info MessageInfo) error
+
+// MessageInfo describes a message delivered to a subscription
+type MessageInfo struct {
+	Epoch      uint64 // Connection generation the message arrived on, starting at 1
+	IsSnapshot bool   // Payload is flagged as a snapshot rather than an increment
+	Initial    bool   // First message for the subscription on this connection
+}
+
+// Subscription is one handler registered for a server subscription. Several
+// handlers can share a server subscription; each gets its own ID.
 type Subscription struct {
 	ID       string
 	Type     string
+	Channel  string
 	Callback MessageHandler
 	Request  types.WSSubscription
+
+	key          string // Server subscription the handler shares
+	infoCallback InfoHandler
+	seenEpoch    atomic.Uint64
 }
 
 func NewManager(url string) *Manager {
 	return &Manager{
 		url:            url,
 		subscriptions:  make(map[string]*Subscription),
-		handlers:       make(map[string]MessageHandler),
+		refs:           make(map[string]int),
 		reconnectDelay: 5 * time.Second,
 		maxReconnect:   10,
 		pingInterval:   30 * time.Second,
@@ -68,10 +105,18 @@ func (m *Manager) Connect(ctx context.Context) error {
 	m.conn = conn
 	m.isConnected = true
 	m.reconnectCount = 0
+	m.epoch++
+	m.connDone = make(chan struct{})
+	m.connectedAt = time.Now()
 
-	go m.readLoop()
-	go m.pingLoop()
-	go m.processMessages()
+	go m.readLoop(conn, m.connDone)
+	go m.pingLoop(m.connDone)
+
+	// A single processor keeps delivery ordered across reconnects
+	if !m.processing {
+		m.processing = true
+		go m.processMessages()
+	}
 
 	if err := m.resubscribeAll(); err != nil {
 		return fmt.Errorf("failed to resubscribe: %w", err)
@@ -98,7 +143,22 @@ func (m *Manager) Disconnect() error {
 	return nil
 }
 
+// Subscribe registers handler for sub. Subscribing again to a subscription
+// that is already active adds a handler rather than replacing the first, and
+// the returned ID identifies that handler for Unsubscribe. An l2Book
+// subscription is rejected while the same coin is subscribed with a
+// different aggregation, as the payloads could not be told apart.
 func (m *Manager) Subscribe(sub types.WSSubscription, handler MessageHandler) (string, error) {
+	return m.subscribe(sub, channelForType(sub.Type), handler, nil)
+}
+
+// SubscribeWithInfo subscribes with a handler that also receives delivery
+// metadata, used to tell snapshots from increments across reconnects
+func (m *Manager) SubscribeWithInfo(sub types.WSSubscription, handler InfoHandler) (string, error) {
+	return m.subscribe(sub, channelForType(sub.Type), nil, handler)
+}
+
+func (m *Manager) subscribe(sub types.WSSubscription, channel string, handler MessageHandler, infoHandler InfoHandler) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -106,35 +166,58 @@ func (m *Manager) Subscribe(sub types.WSSubscription, handler MessageHandler) (s
 		return "", fmt.Errorf("not connected")
 	}
 
-	subID := m.generateSubscriptionID(sub)
-	
+	key := m.generateSubscriptionID(sub)
+	if sub.Type == "l2Book" {
+		for _, existing := range m.subscriptions {
+			if existing.Type == "l2Book" && existing.Request.Coin == sub.Coin && existing.key != key {
+				return "", fmt.Errorf("l2Book for %s already subscribed with a different aggregation (%s)", sub.Coin, existing.key)
+			}
+		}
+	}
+
+	subID := key
+	if _, taken := m.subscriptions[subID]; taken {
+		m.handles++
+		subID = fmt.Sprintf("%s#%d", key, m.handles)
+	}
+
 	subscription := &Subscription{
 		ID:       subID,
 		Type:     sub.Type,
+		Channel:  channel,
 		Callback: handler,
 		Request:  sub,
+
+		key:          key,
+		infoCallback: infoHandler,
 	}
 
-	m.subscriptions[subID] = subscription
-	m.handlers[sub.Type] = handler
+	// Later handlers share the server subscription. The server does not
+	// repeat its snapshot for them, so they start from the next update.
+	if m.refs[key] == 0 {
+		req := types.WSRequest{
+			Method:       "subscribe",
+			Subscription: sub,
+		}
 
-	req := types.WSRequest{
-		Method:       "subscribe",
-		Subscription: sub,
-	}
+		data, err := json.Marshal(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request: %w", err)
+		}
 
-	data, err := json.Marshal(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		if err := m.write(data); err != nil {
+			return "", fmt.Errorf("failed to send subscription: %w", err)
+		}
 	}
 
-	if err := m.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return "", fmt.Errorf("failed to send subscription: %w", err)
-	}
+	m.subscriptions[subID] = subscription
+	m.refs[key]++
 
 	return subID, nil
 }
 
+// Unsubscribe removes a handler. The server subscription is dropped once its
+// last handler is gone.
 func (m *Manager) Unsubscribe(subID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -144,28 +227,61 @@ func (m *Manager) Unsubscribe(subID string) error {
 		return fmt.Errorf("subscription not found: %s", subID)
 	}
 
-	req := types.WSRequest{
-		Method:       "unsubscribe",
-		Subscription: sub.Request,
+	if m.refs[sub.key] == 1 {
+		req := types.WSRequest{
+			Method:       "unsubscribe",
+			Subscription: sub.Request,
+		}
+
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		if err := m.write(data); err != nil {
+			return fmt.Errorf("failed to send unsubscribe: %w", err)
+		}
 	}
 
-	data, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+	m.drop(sub)
+
+	return nil
+}
+
+// forget drops a subscription locally without notifying the server, used
+// when moving subscriptions off a dead connection
+func (m *Manager) forget(subID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sub, ok := m.subscriptions[subID]; ok {
+		m.drop(sub)
 	}
+}
 
-	if err := m.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return fmt.Errorf("failed to send unsubscribe: %w", err)
+// drop removes a handler and its share of the server subscription. Callers
+// hold m.mu.
+func (m *Manager) drop(sub *Subscription) {
+	delete(m.subscriptions, sub.ID)
+	if m.refs[sub.key]--; m.refs[sub.key] <= 0 {
+		delete(m.refs, sub.key)
 	}
+}
 
-	delete(m.subscriptions, subID)
-	delete(m.handlers, sub.Type)
+// subscriptionIDs returns the IDs of the active subscriptions
+func (m *Manager) subscriptionIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	return nil
+	ids := make([]string, 0, len(m.subscriptions))
+	for id := range m.subscriptions {
+		ids = append(ids, id)
+	}
+	return ids
 }
 
-func (m *Manager) readLoop() {
+func (m *Manager) readLoop(conn *websocket.Conn, done chan struct{}) {
 	defer func() {
+		close(done)
 		m.mu.Lock()
 		m.isConnected = false
 		m.mu.Unlock()
@@ -177,7 +293,7 @@ func (m *Manager) readLoop() {
 		case <-m.stopCh:
 			return
 		default:
-			messageType, data, err := m.conn.ReadMessage()
+			messageType, data, err := conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					log.Printf("WebSocket error: %v", err)
@@ -186,6 +302,8 @@ func (m *Manager) readLoop() {
 			}
 
 			if messageType == websocket.TextMessage {
+				m.received.Add(1)
+				m.tap(Inbound, data)
 				m.messageQueue <- data
 			}
 		}
@@ -211,22 +329,96 @@ func (m *Manager) processMessages() {
 
 func (m *Manager) handleMessage(msg types.WSMessage) {
 	m.mu.RLock()
-	handler, exists := m.handlers[msg.Channel]
+	var matched []*Subscription
+	var key routingKey
+	keyParsed := false
+	epoch := m.epoch
+	for _, sub := range m.subscriptions {
+		if sub.Channel != msg.Channel {
+			continue
+		}
+		// Several subscriptions can share a channel (e.g. l2Book for
+		// different coins), so route on the coin or user in the payload
+		if sub.Request.Coin != "" || sub.Request.User != "" {
+			if !keyParsed {
+				key = parseRoutingKey(msg.Data)
+				keyParsed = true
+			}
+			if key.Coin != "" && sub.Request.Coin != "" && key.Coin != sub.Request.Coin {
+				continue
+			}
+			if key.User != "" && sub.Request.User != "" && !strings.EqualFold(key.User, sub.Request.User) {
+				continue
+			}
+		}
+		matched = append(matched, sub)
+	}
 	m.mu.RUnlock()
 
-	if !exists {
-		if msg.Channel != "pong" {
+	if len(matched) == 0 {
+		if msg.Channel != "pong" && msg.Channel != "subscriptionResponse" {
 			log.Printf("No handler for channel: %s", msg.Channel)
 		}
 		return
 	}
 
-	if err := handler(msg.Data); err != nil {
-		log.Printf("Handler error for channel %s: %v", msg.Channel, err)
+	for _, sub := range matched {
+		var err error
+		if sub.infoCallback != nil {
+			if !keyParsed {
+				key = parseRoutingKey(msg.Data)
+				keyParsed = true
+			}
+			info := MessageInfo{
+				Epoch:      epoch,
+				IsSnapshot: key.IsSnapshot,
+				Initial:    sub.seenEpoch.Swap(epoch) != epoch,
+			}
+			err = sub.infoCallback(msg.Data, info)
+		} else {
+			err = sub.Callback(msg.Data)
+		}
+		if err != nil {
+			log.Printf("Handler error for channel %s: %v", msg.Channel, err)
+		}
 	}
 }
 
-func (m *Manager) pingLoop() {
+// routingKey holds the fields used to match a message to its subscription
+type routingKey struct {
+	Coin       string `json:"coin"`
+	User       string `json:"user"`
+	IsSnapshot bool   `json:"isSnapshot"`
+}
+
+// channelForType returns the channel on which a subscription type's
+// messages arrive, which differs from the type for a few subscriptions
+func channelForType(subType string) string {
+	switch subType {
+	case "userEvents":
+		return "user"
+	default:
+		return subType
+	}
+}
+
+// parseRoutingKey extracts the routing fields from a message payload, which
+// may be a single object or an array of objects (e.g. trades)
+func parseRoutingKey(data json.RawMessage) routingKey {
+	var key routingKey
+	if err := json.Unmarshal(data, &key); err == nil {
+		return key
+	}
+
+	var keys []routingKey
+	if err := json.Unmarshal(data, &keys); err == nil && len(keys) > 0 {
+		return keys[0]
+	}
+
+	return routingKey{}
+}
+
+func (m *Manager) pingLoop(done chan struct{}) {
 	ticker := time.NewTicker(m.pingInterval)
 	defer ticker.Stop()
 
@@ -234,16 +426,18 @@ func (m *Manager) pingLoop() {
 		select {
 		case <-m.stopCh:
 			return
+		case <-done:
+			return
 		case <-ticker.C:
 			m.mu.Lock()
 			if m.isConnected && m.conn != nil {
-				ping := map[string]string{"method": "ping"}
-				if err := m.conn.WriteJSON(ping); err != nil {
+				if err := m.write([]byte(`{"method":"ping"}`)); err != nil {
 					log.Printf("Failed to send ping: %v", err)
 					m.isConnected = false
 					m.mu.Unlock()
 					return
 				}
+				m.lastPing = time.Now()
 			}
 			m.mu.Unlock()
 		}
@@ -265,6 +459,7 @@ func (m *Manager) handleReconnect() {
 			
 			if err == nil {
 				log.Println("Reconnected successfully")
+				m.notifyReconnect()
 				return
 			}
 			
@@ -275,8 +470,41 @@ func (m *Manager) handleReconnect() {
 	log.Printf("Max reconnect attempts reached")
 }
 
+// OnReconnect registers a callback invoked after the manager has
+// automatically reconnected and resubscribed
+func (m *Manager) OnReconnect(callback func(epoch uint64)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onReconnect = append(m.onReconnect, callback)
+}
+
+func (m *Manager) notifyReconnect() {
+	m.mu.Lock()
+	m.reconnects++
+	callbacks := m.onReconnect
+	epoch := m.epoch
+	m.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(epoch)
+	}
+}
+
+// Epoch returns the connection generation, incremented on every connect
+func (m *Manager) Epoch() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.epoch
+}
+
 func (m *Manager) resubscribeAll() error {
+	sent := make(map[string]bool, len(m.refs))
 	for _, sub := range m.subscriptions {
+		if sent[sub.key] {
+			continue
+		}
+		sent[sub.key] = true
+
 		req := types.WSRequest{
 			Method:       "subscribe",
 			Subscription: sub.Request,
@@ -287,7 +515,7 @@ func (m *Manager) resubscribeAll() error {
 			return fmt.Errorf("failed to marshal resubscription: %w", err)
 		}
 
-		if err := m.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		if err := m.write(data); err != nil {
 			return fmt.Errorf("failed to resubscribe: %w", err)
 		}
 	}
@@ -295,12 +523,37 @@ func (m *Manager) resubscribeAll() error {
 	return nil
 }
 
+// write sends a text frame to the server. Callers hold m.mu.
+func (m *Manager) write(data []byte) error {
+	if err := m.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+	m.sent.Add(1)
+
+	for _, tap := range m.taps {
+		tap(Outbound, data)
+	}
+	return nil
+}
+
 func (m *Manager) generateSubscriptionID(sub types.WSSubscription) string {
+	return subscriptionID(sub)
+}
+
+// subscriptionID derives a stable ID for a subscription request
+func subscriptionID(sub types.WSSubscription) string {
 	switch sub.Type {
 	case "allMids":
 		return "allMids"
 	case "l2Book":
-		return fmt.Sprintf("l2Book:%s", sub.Coin)
+		id := fmt.Sprintf("l2Book:%s", sub.Coin)
+		if sub.NSigFigs != nil {
+			id += fmt.Sprintf(":%d", *sub.NSigFigs)
+		}
+		if sub.Mantissa != nil {
+			id += fmt.Sprintf(":%d", *sub.Mantissa)
+		}
+		return id
 	case "trades":
 		return fmt.Sprintf("trades:%s", sub.Coin)
 	case "candle":
@@ -308,7 +561,14 @@ func (m *Manager) generateSubscriptionID(sub types.WSSubscription) string {
 	case "userEvents", "orderUpdates":
 		return fmt.Sprintf("%s:%s", sub.Type, sub.User)
 	default:
-		return fmt.Sprintf("%s:%s", sub.Type, sub.Coin)
+		id := sub.Type
+		if sub.Coin != "" {
+			id += ":" + sub.Coin
+		}
+		if sub.User != "" {
+			id += ":" + sub.User
+		}
+		return id
 	}
 }
 
@@ -322,9 +582,17 @@ func (m *Manager) GetStats() types.WSStats {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return types.WSStats{
-		Connected:    m.isConnected,
-		Reconnects:   int64(m.reconnectCount),
-		Subscriptions: len(m.subscriptions),
+	stats := types.WSStats{
+		Connected:        m.isConnected,
+		Reconnects:       m.reconnects,
+		MessagesReceived: m.received.Load(),
+		MessagesSent:     m.sent.Load(),
+		Subscriptions:    len(m.refs),
+		LastPing:         m.lastPing,
 	}
+	if m.isConnected {
+		stats.Uptime = time.Since(m.connectedAt)
+	}
+
+	return stats
 }
\ No newline at end of file
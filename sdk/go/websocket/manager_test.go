@@ -0,0 +1,167 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// pushServer records requests and writes whatever is sent on push
+type pushServer struct {
+	*httptest.Server
+	push     chan types.WSMessage
+	mu       sync.Mutex
+	requests []types.WSRequest
+}
+
+func newPushServer(t *testing.T) *pushServer {
+	s := &pushServer{push: make(chan types.WSMessage, 10)}
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		go func() {
+			for msg := range s.push {
+				if err := conn.WriteJSON(msg); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			var req types.WSRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.requests = append(s.requests, req)
+			s.mu.Unlock()
+		}
+	}))
+
+	return s
+}
+
+func (s *pushServer) methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.requests))
+	for i, req := range s.requests {
+		out[i] = req.Method
+	}
+	return out
+}
+
+func TestManagerSharesSubscription(t *testing.T) {
+	server := newPushServer(t)
+	defer server.Close()
+
+	m := NewManager("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer m.Disconnect()
+
+	first, second := make(chan struct{}, 10), make(chan struct{}, 10)
+	sub := types.WSSubscription{Type: "allMids"}
+	firstID, err := m.Subscribe(sub, func(json.RawMessage) error { first <- struct{}{}; return nil })
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	secondID, err := m.Subscribe(sub, func(json.RawMessage) error { second <- struct{}{}; return nil })
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if firstID == secondID {
+		t.Fatalf("Expected distinct handler IDs, got %s twice", firstID)
+	}
+
+	receive := func(ch chan struct{}, name string) {
+		t.Helper()
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected the %s handler called", name)
+		}
+	}
+
+	server.push <- types.WSMessage{Channel: "allMids", Data: json.RawMessage(`{"mids":{"BTC":"50000"}}`)}
+	receive(first, "first")
+	receive(second, "second")
+
+	// Dropping one handler keeps the server subscription for the other
+	if err := m.Unsubscribe(firstID); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	server.push <- types.WSMessage{Channel: "allMids", Data: json.RawMessage(`{"mids":{"BTC":"50001"}}`)}
+	receive(second, "second")
+	select {
+	case <-first:
+		t.Errorf("Expected the unsubscribed handler not called")
+	default:
+	}
+
+	if err := m.Unsubscribe(secondID); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := server.methods()
+		if len(got) == 2 {
+			if got[0] != "subscribe" || got[1] != "unsubscribe" {
+				t.Errorf("Expected one subscribe and one unsubscribe, got %v", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected one subscribe and one unsubscribe, got %v", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := m.GetStats().Subscriptions; n != 0 {
+		t.Errorf("Expected no subscriptions left, got %d", n)
+	}
+}
+
+func TestManagerL2BookAggregation(t *testing.T) {
+	server := newPushServer(t)
+	defer server.Close()
+
+	m := NewManager("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer m.Disconnect()
+
+	noop := func(types.L2BookData) error { return nil }
+	for i := 0; i < 3; i++ {
+		if _, err := m.SubscribeToL2Book("BTC", noop); err != nil {
+			t.Fatalf("Expected subscriber %d at the same aggregation to share, got %v", i+1, err)
+		}
+	}
+
+	nSigFigs := 3
+	if _, err := m.SubscribeToL2BookAggregated("BTC", &nSigFigs, nil, noop); err == nil {
+		t.Error("Expected a different aggregation of BTC to be rejected")
+	}
+	if _, err := m.Subscribe(types.WSSubscription{Type: "l2Book", Coin: "BTC", NSigFigs: &nSigFigs}, func(json.RawMessage) error { return nil }); err == nil {
+		t.Error("Expected a raw subscription with a different aggregation to be rejected")
+	}
+	if _, err := m.SubscribeToL2BookAggregated("ETH", &nSigFigs, nil, noop); err != nil {
+		t.Errorf("Expected another coin to be unaffected, got %v", err)
+	}
+}
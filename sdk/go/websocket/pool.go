@@ -0,0 +1,551 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// PoolConfig configures a Pool
+type PoolConfig struct {
+	MaxConnections          int           // Upper bound on open connections (default 10)
+	MaxSubscriptionsPerConn int           // Subscriptions carried by one connection (default 100)
+	FailoverAfter           time.Duration // Downtime before a connection's subscriptions move (default 30s)
+	HealthInterval          time.Duration // How often connections are checked (default 5s)
+	DialTimeout             time.Duration // Timeout for opening a connection (default 10s)
+}
+
+// Pool shards subscriptions across several WebSocket connections so that
+// the per-connection subscription cap is never exceeded. Subscriptions on a
+// connection that stays down are moved to healthy connections, and load is
+// rebalanced whenever a connection comes back. While a subscription moves
+// it is briefly carried by both connections, so handlers may see a message
+// twice around a failover or rebalance. As with Manager, several handlers can
+// subscribe to the same subscription and share one server subscription.
+type Pool struct {
+	url    string
+	config PoolConfig
+
+	mu      sync.Mutex
+	conns   []*poolConn
+	dialing int                 // Connections being opened without p.mu held
+	subs    map[string]*poolSub // Server subscriptions by key
+	handles map[string]*poolSub // Server subscriptions by handler ID
+	next    uint64
+	stopCh  chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+type poolConn struct {
+	manager   *Manager
+	subs      map[string]*poolSub
+	downSince time.Time
+}
+
+// poolSub is one server subscription, carried by one connection and
+// delivered to every handler registered for it
+type poolSub struct {
+	id      string // Subscription key, also its ID on the connection
+	request types.WSSubscription
+	conn    *poolConn
+
+	mu       sync.Mutex
+	handlers []poolHandler
+}
+
+type poolHandler struct {
+	id          string
+	handler     MessageHandler
+	infoHandler InfoHandler
+}
+
+// deliver fans a message out to the subscription's handlers
+func (ps *poolSub) deliver(data json.RawMessage, info MessageInfo) error {
+	ps.mu.Lock()
+	handlers := append([]poolHandler(nil), ps.handlers...)
+	ps.mu.Unlock()
+
+	var errs []error
+	for _, h := range handlers {
+		var err error
+		if h.infoHandler != nil {
+			err = h.infoHandler(data, info)
+		} else {
+			err = h.handler(data)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// remove drops a handler, reporting how many are left
+func (ps *poolSub) remove(id string) int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for i, h := range ps.handlers {
+		if h.id == id {
+			ps.handlers = append(ps.handlers[:i], ps.handlers[i+1:]...)
+			break
+		}
+	}
+	return len(ps.handlers)
+}
+
+// NewPool creates a pool of connections to url
+func NewPool(url string, config PoolConfig) *Pool {
+	if config.MaxConnections <= 0 {
+		config.MaxConnections = 10
+	}
+	if config.MaxSubscriptionsPerConn <= 0 {
+		config.MaxSubscriptionsPerConn = 100
+	}
+	if config.FailoverAfter <= 0 {
+		config.FailoverAfter = 30 * time.Second
+	}
+	if config.HealthInterval <= 0 {
+		config.HealthInterval = 5 * time.Second
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 10 * time.Second
+	}
+
+	return &Pool{
+		url:     url,
+		config:  config,
+		subs:    make(map[string]*poolSub),
+		handles: make(map[string]*poolSub),
+	}
+}
+
+// Connect opens the first connection and starts health monitoring. Further
+// connections are opened on demand as subscriptions are added.
+func (p *Pool) Connect(ctx context.Context) error {
+	p.mu.Lock()
+	connected := p.stopCh != nil
+	p.mu.Unlock()
+	if connected {
+		return nil
+	}
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopCh != nil {
+		conn.manager.Disconnect()
+		return nil
+	}
+
+	p.conns = append(p.conns, conn)
+	p.stopCh = make(chan struct{})
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	go p.monitor(p.stopCh)
+
+	return nil
+}
+
+// Disconnect closes every connection
+func (p *Pool) Disconnect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopCh == nil {
+		return nil
+	}
+
+	close(p.stopCh)
+	p.stopCh = nil
+	p.cancel()
+
+	for _, conn := range p.conns {
+		conn.manager.Disconnect()
+	}
+	p.conns = nil
+
+	return nil
+}
+
+// Subscribe subscribes on the least loaded connection with spare capacity.
+// Subscribing again to an active subscription adds a handler on the same
+// server subscription, and the returned ID identifies that handler.
+func (p *Pool) Subscribe(sub types.WSSubscription, handler MessageHandler) (string, error) {
+	return p.subscribe(sub, handler, nil)
+}
+
+// SubscribeWithInfo subscribes with a handler that also receives delivery metadata
+func (p *Pool) SubscribeWithInfo(sub types.WSSubscription, handler InfoHandler) (string, error) {
+	return p.subscribe(sub, nil, handler)
+}
+
+func (p *Pool) subscribe(sub types.WSSubscription, handler MessageHandler, infoHandler InfoHandler) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopCh == nil {
+		return "", fmt.Errorf("not connected")
+	}
+
+	h := poolHandler{handler: handler, infoHandler: infoHandler}
+	key := subscriptionID(sub)
+	if ps, exists := p.subs[key]; exists {
+		return p.attach(ps, h), nil
+	}
+
+	ps := &poolSub{id: key, request: sub}
+	if err := p.place(p.ctx, ps, nil, true); err != nil {
+		return "", err
+	}
+
+	// place may have dialed without the lock, letting another caller in
+	if existing, exists := p.subs[key]; exists {
+		p.drop(ps)
+		return p.attach(existing, h), nil
+	}
+	p.subs[key] = ps
+
+	return p.attach(ps, h), nil
+}
+
+// attach adds a handler to ps. The first handler of a subscription gets its
+// key as ID and later ones "key#n", matching Manager. Callers hold p.mu.
+func (p *Pool) attach(ps *poolSub, h poolHandler) string {
+	h.id = ps.id
+	if _, taken := p.handles[h.id]; taken {
+		p.next++
+		h.id = fmt.Sprintf("%s#%d", ps.id, p.next)
+	}
+
+	ps.mu.Lock()
+	ps.handlers = append(ps.handlers, h)
+	ps.mu.Unlock()
+	p.handles[h.id] = ps
+
+	return h.id
+}
+
+// Unsubscribe removes a handler. The server subscription is dropped from
+// whichever connection carries it once its last handler is gone.
+func (p *Pool) Unsubscribe(subID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ps, ok := p.handles[subID]
+	if !ok {
+		return fmt.Errorf("subscription not found: %s", subID)
+	}
+
+	delete(p.handles, subID)
+	if ps.remove(subID) > 0 {
+		return nil
+	}
+
+	delete(p.subs, ps.id)
+	delete(ps.conn.subs, ps.id)
+
+	if !ps.conn.manager.IsConnected() {
+		ps.conn.manager.forget(ps.id)
+		return nil
+	}
+	return ps.conn.manager.Unsubscribe(ps.id)
+}
+
+// Rebalance moves subscriptions off connections that have been down for
+// longer than FailoverAfter and evens out load across healthy connections
+func (p *Pool) Rebalance() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rebalance(p.ctx)
+}
+
+// Connections returns the number of open connections
+func (p *Pool) Connections() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// GetStats aggregates statistics across all connections. The pool counts as
+// connected only while every connection is up.
+func (p *Pool) GetStats() types.WSStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var stats types.WSStats
+	stats.Connected = len(p.conns) > 0
+	for _, conn := range p.conns {
+		s := conn.manager.GetStats()
+		stats.Connected = stats.Connected && s.Connected
+		stats.Reconnects += s.Reconnects
+		stats.MessagesReceived += s.MessagesReceived
+		stats.MessagesSent += s.MessagesSent
+		stats.Subscriptions += s.Subscriptions
+		if s.Uptime > stats.Uptime {
+			stats.Uptime = s.Uptime
+		}
+		if s.LastPing.After(stats.LastPing) {
+			stats.LastPing = s.LastPing
+		}
+		if s.LastPong.After(stats.LastPong) {
+			stats.LastPong = s.LastPong
+		}
+	}
+	return stats
+}
+
+// ConnectionStats returns per-connection statistics
+func (p *Pool) ConnectionStats() []types.WSStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]types.WSStats, 0, len(p.conns))
+	for _, conn := range p.conns {
+		stats = append(stats, conn.manager.GetStats())
+	}
+	return stats
+}
+
+// dial opens a connection that is not yet part of the pool
+func (p *Pool) dial(ctx context.Context) (*poolConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.DialTimeout)
+	defer cancel()
+
+	manager := NewManager(p.url)
+	if err := manager.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	conn := &poolConn{manager: manager, subs: make(map[string]*poolSub)}
+	manager.OnReconnect(func(uint64) {
+		go func() {
+			if err := p.Rebalance(); err != nil {
+				log.Printf("Pool rebalance failed: %v", err)
+			}
+		}()
+	})
+
+	return conn, nil
+}
+
+// openConn adds a connection to the pool. Callers hold p.mu, which is
+// released while dialing so that a slow dial does not stall the pool.
+func (p *Pool) openConn(ctx context.Context) (*poolConn, error) {
+	if len(p.conns)+p.dialing >= p.config.MaxConnections {
+		return nil, fmt.Errorf("connection limit reached (%d)", p.config.MaxConnections)
+	}
+
+	p.dialing++
+	p.mu.Unlock()
+	conn, err := p.dial(ctx)
+	p.mu.Lock()
+	p.dialing--
+
+	if err != nil {
+		return nil, err
+	}
+	if p.stopCh == nil {
+		conn.manager.Disconnect()
+		return nil, fmt.Errorf("not connected")
+	}
+
+	p.conns = append(p.conns, conn)
+	return conn, nil
+}
+
+// place subscribes ps on the least loaded healthy connection other than
+// exclude, opening a new connection when allowed and every existing one is
+// full. Opening a connection releases p.mu while dialing.
+func (p *Pool) place(ctx context.Context, ps *poolSub, exclude *poolConn, allowOpen bool) error {
+	var target *poolConn
+	for _, conn := range p.conns {
+		if conn == exclude || !conn.manager.IsConnected() || !p.fits(conn, ps) {
+			continue
+		}
+		if target == nil || len(conn.subs) < len(target.subs) {
+			target = conn
+		}
+	}
+
+	if target == nil {
+		if !allowOpen {
+			return fmt.Errorf("no capacity for %s", ps.id)
+		}
+		conn, err := p.openConn(ctx)
+		if err != nil {
+			return fmt.Errorf("no capacity for %s: %w", ps.id, err)
+		}
+		target = conn
+	}
+
+	if _, err := target.manager.SubscribeWithInfo(ps.request, ps.deliver); err != nil {
+		return err
+	}
+
+	ps.conn = target
+	target.subs[ps.id] = ps
+	return nil
+}
+
+// drop removes ps from its connection, telling the server when it is up
+func (p *Pool) drop(ps *poolSub) {
+	delete(ps.conn.subs, ps.id)
+	if ps.conn.manager.IsConnected() {
+		if err := ps.conn.manager.Unsubscribe(ps.id); err == nil {
+			return
+		}
+	}
+	ps.conn.manager.forget(ps.id)
+}
+
+// fits reports whether conn has room for ps. Subscriptions that differ only
+// in parameters the server does not echo (e.g. l2Book aggregation) cannot
+// share a connection because their messages would be indistinguishable.
+func (p *Pool) fits(conn *poolConn, ps *poolSub) bool {
+	if len(conn.subs) >= p.config.MaxSubscriptionsPerConn {
+		return false
+	}
+	for _, existing := range conn.subs {
+		if existing.request.Type == ps.request.Type &&
+			existing.request.Coin == ps.request.Coin &&
+			existing.request.User == ps.request.User {
+			return false
+		}
+	}
+	return true
+}
+
+// move re-places ps away from its current connection. The new connection
+// subscribes before the old one unsubscribes, so no message is missed but
+// one may arrive on both.
+func (p *Pool) move(ctx context.Context, ps *poolSub, allowOpen bool) error {
+	from := ps.conn
+	if err := p.place(ctx, ps, from, allowOpen); err != nil {
+		return err
+	}
+
+	// Unsubscribed while place was dialing
+	if p.subs[ps.id] != ps {
+		p.drop(ps)
+		return nil
+	}
+
+	delete(from.subs, ps.id)
+	if from.manager.IsConnected() {
+		if err := from.manager.Unsubscribe(ps.id); err != nil {
+			from.manager.forget(ps.id)
+		}
+	} else {
+		from.manager.forget(ps.id)
+	}
+	return nil
+}
+
+// rebalance moves what it can and reports every subscription it could not
+func (p *Pool) rebalance(ctx context.Context) error {
+	now := time.Now()
+	var healthy []*poolConn
+	var failed []*poolConn
+	for _, conn := range p.conns {
+		if conn.manager.IsConnected() {
+			conn.downSince = time.Time{}
+			healthy = append(healthy, conn)
+			continue
+		}
+		if conn.downSince.IsZero() {
+			conn.downSince = now
+		}
+		if now.Sub(conn.downSince) >= p.config.FailoverAfter {
+			failed = append(failed, conn)
+		}
+	}
+
+	var errs []error
+
+	// Fail over subscriptions from connections that stayed down
+	for _, conn := range failed {
+		for _, ps := range subsOf(conn) {
+			// Moved or removed while a dial released the lock
+			if ps.conn != conn || p.subs[ps.id] != ps {
+				continue
+			}
+			if err := p.move(ctx, ps, true); err != nil {
+				errs = append(errs, fmt.Errorf("failed to move %s: %w", ps.id, err))
+			}
+		}
+		if len(conn.subs) == 0 {
+			conn.manager.Disconnect()
+			p.removeConn(conn)
+		}
+	}
+
+	if len(healthy) < 2 {
+		return errors.Join(errs...)
+	}
+
+	// Even out load so that no healthy connection carries more than its share
+	total := 0
+	for _, conn := range healthy {
+		total += len(conn.subs)
+	}
+	target := (total + len(healthy) - 1) / len(healthy)
+
+	for _, conn := range healthy {
+		for _, ps := range subsOf(conn) {
+			if len(conn.subs) <= target {
+				break
+			}
+			if err := p.move(ctx, ps, false); err != nil {
+				errs = append(errs, fmt.Errorf("failed to move %s: %w", ps.id, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// subsOf returns the subscriptions on conn, so they can be moved while
+// iterating
+func subsOf(conn *poolConn) []*poolSub {
+	subs := make([]*poolSub, 0, len(conn.subs))
+	for _, ps := range conn.subs {
+		subs = append(subs, ps)
+	}
+	return subs
+}
+
+func (p *Pool) removeConn(conn *poolConn) {
+	for i, c := range p.conns {
+		if c == conn {
+			p.conns = append(p.conns[:i], p.conns[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *Pool) monitor(stopCh chan struct{}) {
+	ticker := time.NewTicker(p.config.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := p.Rebalance(); err != nil {
+				log.Printf("Pool rebalance failed: %v", err)
+			}
+		}
+	}
+}
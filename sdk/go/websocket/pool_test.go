@@ -0,0 +1,323 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+)
+
+// echoServer accepts connections and answers every subscribe request with
+// one message on the subscribed channel
+type echoServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	connections int
+}
+
+func newEchoServer(t *testing.T) *echoServer {
+	s := &echoServer{}
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		s.mu.Lock()
+		s.connections++
+		s.mu.Unlock()
+
+		for {
+			var req types.WSRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if req.Method != "subscribe" {
+				continue
+			}
+
+			data, _ := json.Marshal(map[string]interface{}{"coin": req.Subscription.Coin, "time": 1})
+			msg := types.WSMessage{Channel: req.Subscription.Type, Data: data}
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		}
+	}))
+
+	return s
+}
+
+func (s *echoServer) wsURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func TestPoolSharding(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	pool := NewPool(server.wsURL(), PoolConfig{MaxConnections: 3, MaxSubscriptionsPerConn: 2})
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Disconnect()
+
+	received := make(chan string, 10)
+	for _, coin := range []string{"BTC", "ETH", "SOL", "ARB", "OP"} {
+		sub := types.WSSubscription{Type: "l2Book", Coin: coin}
+		_, err := pool.Subscribe(sub, func(raw json.RawMessage) error {
+			var data types.L2BookData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return err
+			}
+			received <- data.Coin
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to subscribe to %s: %v", coin, err)
+		}
+	}
+
+	if pool.Connections() != 3 {
+		t.Errorf("Expected 3 connections, got %d", pool.Connections())
+	}
+
+	stats := pool.GetStats()
+	if !stats.Connected || stats.Subscriptions != 5 {
+		t.Errorf("Unexpected aggregate stats: %+v", stats)
+	}
+
+	seen := make(map[string]bool)
+	timeout := time.After(2 * time.Second)
+	for len(seen) < 5 {
+		select {
+		case coin := <-received:
+			seen[coin] = true
+		case <-timeout:
+			t.Fatalf("Timed out waiting for messages, got %v", seen)
+		}
+	}
+
+	// One slot is left; after that the pool has nowhere to go
+	noop := func(json.RawMessage) error { return nil }
+	if _, err := pool.Subscribe(types.WSSubscription{Type: "trades", Coin: "DOGE"}, noop); err != nil {
+		t.Errorf("Expected last slot to be usable: %v", err)
+	}
+	if _, err := pool.Subscribe(types.WSSubscription{Type: "trades", Coin: "PEPE"}, noop); err == nil {
+		t.Error("Expected error when pool capacity is exhausted")
+	}
+}
+
+func TestPoolSeparatesAggregations(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	pool := NewPool(server.wsURL(), PoolConfig{MaxConnections: 2, MaxSubscriptionsPerConn: 10})
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Disconnect()
+
+	noop := func(json.RawMessage) error { return nil }
+	five := 5
+	if _, err := pool.Subscribe(types.WSSubscription{Type: "l2Book", Coin: "BTC"}, noop); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if _, err := pool.Subscribe(types.WSSubscription{Type: "l2Book", Coin: "BTC", NSigFigs: &five}, noop); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	if pool.Connections() != 2 {
+		t.Errorf("Expected aggregations on separate connections, got %d connections", pool.Connections())
+	}
+}
+
+func TestPoolRebalanceContinuesPastFailures(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	pool := NewPool(server.wsURL(), PoolConfig{MaxConnections: 2, FailoverAfter: time.Nanosecond, HealthInterval: time.Hour})
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Disconnect()
+
+	noop := func(json.RawMessage) error { return nil }
+	five := 5
+	for _, sub := range []types.WSSubscription{
+		{Type: "l2Book", Coin: "BTC"},
+		{Type: "l2Book", Coin: "BTC", NSigFigs: &five}, // Forced onto a second connection
+		{Type: "trades", Coin: "ETH"},
+	} {
+		if _, err := pool.Subscribe(sub, noop); err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+	}
+
+	pool.mu.Lock()
+	first := pool.subs["l2Book:BTC"].conn
+	if pool.subs["trades:ETH"].conn != first {
+		pool.mu.Unlock()
+		t.Fatalf("Expected trades to share the first connection")
+	}
+	pool.mu.Unlock()
+	first.manager.Disconnect()
+
+	// The first check only notes the connection as down
+	if err := pool.Rebalance(); err != nil {
+		t.Fatalf("Rebalance failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	// The book has nowhere to go, but trades still move
+	if err := pool.Rebalance(); err == nil || !strings.Contains(err.Error(), "l2Book:BTC") {
+		t.Errorf("Expected the book reported as stuck, got %v", err)
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if conn := pool.subs["trades:ETH"].conn; conn == first {
+		t.Errorf("Expected trades moved off the failed connection")
+	}
+}
+
+func TestPoolDialsWithoutTheLock(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var mu sync.Mutex
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connections++
+		hang := connections > 1
+		mu.Unlock()
+
+		// Later connections never finish the handshake
+		if hang {
+			<-r.Context().Done()
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	pool := NewPool("ws"+strings.TrimPrefix(server.URL, "http"), PoolConfig{MaxSubscriptionsPerConn: 1, DialTimeout: 200 * time.Millisecond})
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Disconnect()
+
+	noop := func(json.RawMessage) error { return nil }
+	if _, err := pool.Subscribe(types.WSSubscription{Type: "trades", Coin: "BTC"}, noop); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pool.Subscribe(types.WSSubscription{Type: "trades", Coin: "ETH"}, noop)
+		done <- err
+	}()
+
+	// The pool stays usable while the second connection hangs
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	if n := pool.Connections(); n != 1 {
+		t.Errorf("Expected 1 connection, got %d", n)
+	}
+	if waited := time.Since(start); waited > 100*time.Millisecond {
+		t.Errorf("Expected Connections not to wait for the dial, took %s", waited)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("Expected the hung dial to time out")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected the dial bounded by DialTimeout")
+	}
+}
+
+func TestPoolSharesSubscription(t *testing.T) {
+	server := newPushServer(t)
+	defer server.Close()
+
+	pool := NewPool("ws"+strings.TrimPrefix(server.URL, "http"), PoolConfig{})
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Disconnect()
+
+	// Two components following the same user's fills
+	first, second := make(chan int64, 10), make(chan int64, 10)
+	streams := []*FillStream{
+		NewFillStream(pool, "0xabc", FillStreamConfig{}, func(event FillEvent) error { first <- event.Fill.Tid; return nil }),
+		NewFillStream(pool, "0xabc", FillStreamConfig{}, func(event FillEvent) error { second <- event.Fill.Tid; return nil }),
+	}
+	var ids []string
+	for _, stream := range streams {
+		id, err := stream.Start()
+		if err != nil {
+			t.Fatalf("Failed to start stream: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if ids[0] == ids[1] {
+		t.Fatalf("Expected distinct handler IDs, got %s twice", ids[0])
+	}
+	if n := pool.GetStats().Subscriptions; n != 1 {
+		t.Errorf("Expected one server subscription, got %d", n)
+	}
+
+	receive := func(ch chan int64, want int64) {
+		t.Helper()
+		select {
+		case tid := <-ch:
+			if tid != want {
+				t.Errorf("Expected fill %d, got %d", want, tid)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected fill %d delivered", want)
+		}
+	}
+
+	server.push <- types.WSMessage{Channel: "userFills", Data: fillsMessage(t, false, 1)}
+	receive(first, 1)
+	receive(second, 1)
+
+	// Stopping one component leaves the other subscribed
+	if err := streams[0].Stop(); err != nil {
+		t.Fatalf("Failed to stop stream: %v", err)
+	}
+	server.push <- types.WSMessage{Channel: "userFills", Data: fillsMessage(t, false, 2)}
+	receive(second, 2)
+	select {
+	case tid := <-first:
+		t.Errorf("Expected the stopped stream not called, got fill %d", tid)
+	default:
+	}
+
+	if err := streams[1].Stop(); err != nil {
+		t.Fatalf("Failed to stop stream: %v", err)
+	}
+	if n := pool.GetStats().Subscriptions; n != 0 {
+		t.Errorf("Expected no subscriptions left, got %d", n)
+	}
+}
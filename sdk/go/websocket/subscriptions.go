@@ -2,7 +2,10 @@ package websocket
 
 import (
 	"encoding/json"
+	"fmt"
+
 	"github.com/hyperliquid-labs/hyperliquid-go-sdk/types"
+	"github.com/hyperliquid-labs/hyperliquid-go-sdk/utils"
 )
 
 // Subscription helper functions
@@ -22,9 +25,23 @@ func (m *Manager) SubscribeToAllMids(handler func(data types.AllMidsData) error)
 }
 
 func (m *Manager) SubscribeToL2Book(coin string, handler func(data types.L2BookData) error) (string, error) {
+	return m.SubscribeToL2BookAggregated(coin, nil, nil, handler)
+}
+
+// SubscribeToL2BookAggregated subscribes to l2Book with server-side price
+// aggregation. l2Book payloads do not echo the aggregation, so a connection
+// can only carry one aggregation per coin; use separate managers (or a Pool)
+// to stream several aggregations of the same coin.
+func (m *Manager) SubscribeToL2BookAggregated(coin string, nSigFigs, mantissa *int, handler func(data types.L2BookData) error) (string, error) {
+	if err := utils.ValidateL2Aggregation(nSigFigs, mantissa); err != nil {
+		return "", fmt.Errorf("invalid L2 aggregation: %w", err)
+	}
+
 	sub := types.WSSubscription{
-		Type: "l2Book",
-		Coin: coin,
+		Type:     "l2Book",
+		Coin:     coin,
+		NSigFigs: nSigFigs,
+		Mantissa: mantissa,
 	}
 
 	return m.Subscribe(sub, func(raw json.RawMessage) error {
@@ -82,14 +99,14 @@ func (m *Manager) SubscribeToUserEvents(user string, handler func(data types.Use
 	})
 }
 
-func (m *Manager) SubscribeToUserFills(user string, handler func(data types.UserFillData) error) (string, error) {
+func (m *Manager) SubscribeToUserFills(user string, handler func(data types.UserFillsData) error) (string, error) {
 	sub := types.WSSubscription{
 		Type: "userFills",
 		User: user,
 	}
 
 	return m.Subscribe(sub, func(raw json.RawMessage) error {
-		var data types.UserFillData
+		var data types.UserFillsData
 		if err := json.Unmarshal(raw, &data); err != nil {
 			return err
 		}
@@ -97,14 +114,14 @@ func (m *Manager) SubscribeToUserFills(user string, handler func(data types.User
 	})
 }
 
-func (m *Manager) SubscribeToOrderUpdates(user string, handler func(data types.OrderUpdate) error) (string, error) {
+func (m *Manager) SubscribeToOrderUpdates(user string, handler func(data []types.OrderUpdate) error) (string, error) {
 	sub := types.WSSubscription{
 		Type: "orderUpdates",
 		User: user,
 	}
 
 	return m.Subscribe(sub, func(raw json.RawMessage) error {
-		var data types.OrderUpdate
+		var data []types.OrderUpdate
 		if err := json.Unmarshal(raw, &data); err != nil {
 			return err
 		}
@@ -112,14 +129,14 @@ func (m *Manager) SubscribeToOrderUpdates(user string, handler func(data types.O
 	})
 }
 
-func (m *Manager) SubscribeToUserFunding(user string, handler func(data types.FundingData) error) (string, error) {
+func (m *Manager) SubscribeToUserFunding(user string, handler func(data types.UserFundingsData) error) (string, error) {
 	sub := types.WSSubscription{
 		Type: "userFundings",
 		User: user,
 	}
 
 	return m.Subscribe(sub, func(raw json.RawMessage) error {
-		var data types.FundingData
+		var data types.UserFundingsData
 		if err := json.Unmarshal(raw, &data); err != nil {
 			return err
 		}
@@ -157,6 +174,23 @@ func (m *Manager) SubscribeToActiveAssetCtx(coin string, handler func(data types
 	})
 }
 
+// SubscribeToActiveSpotAssetCtx subscribes to activeAssetCtx for a spot
+// coin, whose updates arrive on the activeSpotAssetCtx channel
+func (m *Manager) SubscribeToActiveSpotAssetCtx(coin string, handler func(data types.ActiveSpotAssetCtxData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "activeAssetCtx",
+		Coin: coin,
+	}
+
+	return m.subscribe(sub, "activeSpotAssetCtx", func(raw json.RawMessage) error {
+		var data types.ActiveSpotAssetCtxData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	}, nil)
+}
+
 func (m *Manager) SubscribeToActiveAssetData(coin, user string, handler func(data types.ActiveAssetDataData) error) (string, error) {
 	sub := types.WSSubscription{
 		Type: "activeAssetData",
@@ -186,4 +220,94 @@ func (m *Manager) SubscribeToWebData2(user string, handler func(data types.WebDa
 		}
 		return handler(data)
 	})
-}
\ No newline at end of file
+}
+
+func (m *Manager) SubscribeToNotifications(user string, handler func(data types.NotificationData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "notification",
+		User: user,
+	}
+
+	return m.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.NotificationData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	})
+}
+
+func (m *Manager) SubscribeToUserNonFundingLedgerUpdates(user string, handler func(data types.UserNonFundingLedgerUpdatesData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "userNonFundingLedgerUpdates",
+		User: user,
+	}
+
+	return m.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.UserNonFundingLedgerUpdatesData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	})
+}
+
+func (m *Manager) SubscribeToUserTwapSliceFills(user string, handler func(data types.UserTwapSliceFillsData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "userTwapSliceFills",
+		User: user,
+	}
+
+	return m.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.UserTwapSliceFillsData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	})
+}
+
+func (m *Manager) SubscribeToUserTwapHistory(user string, handler func(data types.UserTwapHistoryData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "userTwapHistory",
+		User: user,
+	}
+
+	return m.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.UserTwapHistoryData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	})
+}
+
+func (m *Manager) SubscribeToClearinghouseState(user string, handler func(data types.ClearinghouseStateData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "clearinghouseState",
+		User: user,
+	}
+
+	return m.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.ClearinghouseStateData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	})
+}
+
+func (m *Manager) SubscribeToOpenOrders(user string, handler func(data types.OpenOrdersData) error) (string, error) {
+	sub := types.WSSubscription{
+		Type: "openOrders",
+		User: user,
+	}
+
+	return m.Subscribe(sub, func(raw json.RawMessage) error {
+		var data types.OpenOrdersData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return handler(data)
+	})
+}
@@ -0,0 +1,41 @@
+package websocket
+
+// Direction is the way a frame travelled on the connection
+type Direction int
+
+const (
+	// Inbound frames were received from the server
+	Inbound Direction = iota
+	// Outbound frames were sent by the Manager
+	Outbound
+)
+
+func (d Direction) String() string {
+	if d == Inbound {
+		return "in"
+	}
+	return "out"
+}
+
+// FrameTap observes a raw text frame. It runs on the connection's read or
+// write path, so it must return quickly and must not call into the Manager.
+type FrameTap func(dir Direction, data []byte)
+
+// Tap registers a tap called with every text frame sent or received,
+// including pings, across reconnects
+func (m *Manager) Tap(tap FrameTap) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.taps = append(m.taps, tap)
+}
+
+// tap reports a received frame to the registered taps
+func (m *Manager) tap(dir Direction, data []byte) {
+	m.mu.RLock()
+	taps := m.taps
+	m.mu.RUnlock()
+
+	for _, tap := range taps {
+		tap(dir, data)
+	}
+}